@@ -1,10 +1,16 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"html/template"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fukata/golang-stats-api-handler"
@@ -15,13 +21,22 @@ import (
 	"github.com/utahta/momoclo-channel/crawler"
 	"github.com/utahta/momoclo-channel/customsearch"
 	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/discord"
 	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/event"
 	"github.com/utahta/momoclo-channel/linebot"
 	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mailer"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/slack"
+	"github.com/utahta/momoclo-channel/syndication"
+	"github.com/utahta/momoclo-channel/telegram"
 	"github.com/utahta/momoclo-channel/usecase"
 	"github.com/utahta/momoclo-channel/ustream"
+	"github.com/utahta/momoclo-channel/webhook"
+	"github.com/utahta/momoclo-channel/webpush"
+	"github.com/utahta/momoclo-channel/websub"
 )
 
 type (
@@ -31,10 +46,18 @@ type (
 		taskQueue        event.TaskQueue
 		ustChecker       ustream.StatusChecker
 		feedFetcher      crawler.FeedFetcher
+		archiveFetcher   crawler.ArchiveFetcher
 		linebotClient    linebot.Client
 		imageSearcher    customsearch.ImageSearcher
 		linenotifyToken  linenotify.Token
 		linenotifyClient linenotify.Client
+		discordClient    discord.Client
+		slackClient      slack.Client
+		mailer           mailer.Mailer
+		webhookClient    webhook.Client
+		websubClient     websub.Client
+		webpushClient    webpush.Client
+		telegramClient   telegram.Client
 
 		reminderRepo         entity.ReminderRepository
 		ustreamStatusRepo    entity.UstreamStatusRepository
@@ -42,6 +65,18 @@ type (
 		tweetItemRepo        entity.TweetItemRepository
 		lineItemRepo         entity.LineItemRepository
 		lineNotificationRepo entity.LineNotificationRepository
+		crawlStatusRepo      entity.CrawlStatusRepository
+		previewRepo          entity.PreviewRepository
+		channelItemRepo      entity.ChannelItemRepository
+		discordWebhookRepo   entity.DiscordWebhookRepository
+		slackWebhookRepo     entity.SlackWebhookRepository
+		emailSubscriberRepo  entity.EmailSubscriberRepository
+
+		webhookSubscriberRepo   entity.WebhookSubscriberRepository
+		webhookDeliveryRepo     entity.WebhookDeliveryRepository
+		webSubSubscriptionRepo  entity.WebSubSubscriptionRepository
+		webPushSubscriptionRepo entity.WebPushSubscriptionRepository
+		telegramSubscriberRepo  entity.TelegramSubscriberRepository
 	}
 )
 
@@ -53,11 +88,19 @@ func NewBackendServer() Server {
 		transactor:       dao.NewDatastoreTransactor(),
 		taskQueue:        event.NewTaskQueue(),
 		ustChecker:       ustream.NewStatusChecker(),
-		feedFetcher:      crawler.New(),
+		feedFetcher:      crawler.New(log.NewAELogger(), entity.NewFetchCacheRepository(dh)),
+		archiveFetcher:   crawler.NewArchiveFetcher(),
 		linebotClient:    linebot.New(),
 		imageSearcher:    customsearch.NewImageSearcher(),
 		linenotifyToken:  linenotify.NewToken(),
 		linenotifyClient: linenotify.New(),
+		discordClient:    discord.New(),
+		slackClient:      slack.New(),
+		mailer:           mailer.New(),
+		webhookClient:    webhook.New(),
+		websubClient:     websub.New(),
+		webpushClient:    webpush.New(),
+		telegramClient:   telegram.New(),
 
 		reminderRepo:         entity.NewReminderRepository(dh),
 		ustreamStatusRepo:    entity.NewUstreamStatusRepository(dh),
@@ -65,22 +108,42 @@ func NewBackendServer() Server {
 		tweetItemRepo:        entity.NewTweetItemRepository(dh),
 		lineItemRepo:         entity.NewLineItemRepository(dh),
 		lineNotificationRepo: entity.NewLineNotificationRepository(dh),
+		crawlStatusRepo:      entity.NewCrawlStatusRepository(dh),
+		previewRepo:          entity.NewPreviewRepository(dh),
+		channelItemRepo:      entity.NewChannelItemRepository(dh),
+		discordWebhookRepo:   entity.NewDiscordWebhookRepository(dh),
+		slackWebhookRepo:     entity.NewSlackWebhookRepository(dh),
+		emailSubscriberRepo:  entity.NewEmailSubscriberRepository(dh),
+
+		webhookSubscriberRepo:   entity.NewWebhookSubscriberRepository(dh),
+		webhookDeliveryRepo:     entity.NewWebhookDeliveryRepository(dh),
+		webSubSubscriptionRepo:  entity.NewWebSubSubscriptionRepository(dh),
+		webPushSubscriptionRepo: entity.NewWebPushSubscriptionRepository(dh),
+		telegramSubscriberRepo:  entity.NewTelegramSubscriberRepository(dh),
 	}
 }
 
 func (s *backendServer) Handle() {
 	r := chi.NewRouter()
 	r.Use(middleware.AEContext)
+	r.Use(middleware.DryRun)
 
 	r.Route("/cron", func(r chi.Router) {
 		r.Get("/crawl", s.cronCrawl)
 		r.Get("/ustream", s.cronUstream)
 		r.Get("/reminder", s.cronReminder)
+		r.Get("/email/{frequency}", s.cronEmailDigests)
 	})
 
 	r.Route("/enqueue", func(r chi.Router) {
 		r.Post("/tweets", s.enqueueTweets)
 		r.Post("/lines", s.enqueueLines)
+		r.Post("/{channel}", s.enqueueNotification)
+	})
+
+	r.Route("/backfill", func(r chi.Router) {
+		r.Get("/feed", s.backfillFeedStart)
+		r.Post("/feed", s.backfillFeed)
 	})
 
 	r.Route("/line", func(r chi.Router) {
@@ -94,12 +157,64 @@ func (s *backendServer) Handle() {
 			r.Get("/on", s.lineNotifyOn)
 			r.Get("/off", s.lineNotifyOff)
 			r.HandleFunc("/callback", s.lineNotifyCallback)
+			r.Get("/preferences", s.lineNotifyPreferences)
+			r.Post("/preferences", s.lineNotifyPreferencesUpdate)
 
 			r.Post("/broadcast", s.lineNotifyBroadcast)
 			r.Post("/", s.lineNotify)
 		})
 	})
 
+	r.Route("/discord", func(r chi.Router) {
+		r.Post("/webhook", s.discordWebhookAdd)
+		r.Post("/notify", s.discordNotify)
+	})
+
+	r.Route("/slack", func(r chi.Router) {
+		r.Post("/webhook", s.slackWebhookAdd)
+		r.Post("/notify", s.slackNotify)
+	})
+
+	r.Route("/email", func(r chi.Router) {
+		r.Post("/subscribe", s.emailSubscribe)
+		r.Get("/confirm", s.emailConfirm)
+		r.HandleFunc("/unsubscribe", s.emailUnsubscribe)
+		r.Get("/manage", s.emailManage)
+		r.Post("/send", s.emailSend)
+	})
+
+	r.Route("/webhook", func(r chi.Router) {
+		r.Post("/subscribers", s.webhookSubscriberAdd)
+		r.Get("/deliveries", s.webhookDeliveries)
+		r.Post("/deliver", s.webhookDeliver)
+	})
+
+	r.Route("/webpush", func(r chi.Router) {
+		r.Get("/", s.webPushPage)
+		r.Post("/subscribe", s.webPushSubscribe)
+		r.Post("/unsubscribe", s.webPushUnsubscribe)
+		r.Post("/broadcast", s.webPushBroadcast)
+		r.Post("/send", s.webPushSend)
+	})
+
+	r.Route("/telegram", func(r chi.Router) {
+		r.Post("/webhook", s.telegramWebhook)
+		r.Post("/send", s.telegramSend)
+	})
+
+	r.Route("/websub", func(r chi.Router) {
+		r.Post("/hub", s.websubHub)
+		r.Post("/verify", s.websubVerify)
+		r.Post("/publish", s.websubPublish)
+		r.Post("/distribute", s.websubDistribute)
+	})
+
+	r.Get("/feed.atom", s.feedAtom)
+	r.Get("/feed.rss", s.feedRSS)
+	r.Get("/feed.json", s.feedJSON)
+
+	r.Get("/preview", s.preview)
+
 	r.HandleFunc("/api/stats", stats_api.Handler)
 
 	http.Handle("/", r)
@@ -109,20 +224,39 @@ func (s *backendServer) Handle() {
 func (s *backendServer) cronReminder(w http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 
-	remind := usecase.NewRemind(s.logger, s.taskQueue, s.reminderRepo)
+	remind := usecase.NewRemind(s.logger, s.newNotify(), s.reminderRepo)
 	if err := remind.Do(ctx); err != nil {
 		failResponse(ctx, w, err, http.StatusInternalServerError)
 		return
 	}
 }
 
+// cronEmailDigests sends email digests of given frequency (e.g. /cron/email/daily)
+func (s *backendServer) cronEmailDigests(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 540*time.Second)
+	defer cancel()
+
+	sendEmailDigests := usecase.NewSendEmailDigests(
+		s.logger,
+		s.taskQueue,
+		s.emailSubscriberRepo,
+		s.lineItemRepo,
+		s.previewRepo,
+	)
+	params := usecase.SendEmailDigestsParams{Frequency: chi.URLParam(req, "frequency")}
+	if err := sendEmailDigests.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
 // cronUstream checks ustream status
 func (s *backendServer) cronUstream(w http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 
 	checkUstream := usecase.NewCheckUstream(
 		s.logger,
-		s.taskQueue,
+		s.newNotify(),
 		s.ustChecker,
 		s.ustreamStatusRepo,
 	)
@@ -140,8 +274,10 @@ func (s *backendServer) cronCrawl(w http.ResponseWriter, req *http.Request) {
 	crawlFeed := usecase.NewCrawlFeed(
 		s.logger,
 		s.feedFetcher,
-		s.taskQueue,
+		s.newNotify(),
 		s.latestEntryRepo,
+		s.crawlStatusRepo,
+		usecase.NewLineNotifyAdmins(s.logger, s.taskQueue, s.lineNotificationRepo),
 	)
 	crawlFeeds := usecase.NewCrawlFeeds(s.logger, crawlFeed)
 	if err := crawlFeeds.Do(ctx); err != nil {
@@ -150,7 +286,46 @@ func (s *backendServer) cronCrawl(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// newNotify returns Notify use case with all notification channels registered
+func (s *backendServer) newNotify() *usecase.Notify {
+	registry := notifier.NewRegistry()
+	registry.Register(notifier.ChannelTwitter, usecase.NewEnqueueTweets(s.logger, s.taskQueue, s.transactor, s.tweetItemRepo))
+	registry.Register(notifier.ChannelLine, usecase.NewEnqueueLines(s.logger, s.taskQueue, s.transactor, s.lineItemRepo))
+	registry.Register(notifier.ChannelDiscord, usecase.NewEnqueueDiscord(s.logger, s.taskQueue, s.transactor, s.channelItemRepo, s.discordWebhookRepo, s.previewRepo))
+	registry.Register(notifier.ChannelSlack, usecase.NewEnqueueSlack(s.logger, s.taskQueue, s.transactor, s.channelItemRepo, s.slackWebhookRepo, s.previewRepo))
+	registry.Register(notifier.ChannelMastodon, usecase.NewEnqueueMastodon(s.logger, s.taskQueue, s.transactor, s.channelItemRepo))
+	registry.Register(notifier.ChannelBluesky, usecase.NewEnqueueBluesky(s.logger, s.taskQueue, s.transactor, s.channelItemRepo))
+	registry.Register(notifier.ChannelEmail, usecase.NewEnqueueEmails(s.logger, s.taskQueue, s.transactor, s.channelItemRepo, s.emailSubscriberRepo, s.previewRepo))
+	registry.Register(notifier.ChannelWebhook, usecase.NewEnqueueWebhooks(s.logger, s.taskQueue, s.transactor, s.channelItemRepo, s.webhookSubscriberRepo, s.webhookDeliveryRepo, s.previewRepo))
+	registry.Register(notifier.ChannelWebPush, usecase.NewEnqueueWebPush(s.logger, s.taskQueue, s.transactor, s.channelItemRepo))
+	registry.Register(notifier.ChannelTelegram, usecase.NewEnqueueTelegram(s.logger, s.taskQueue, s.transactor, s.channelItemRepo, s.telegramSubscriberRepo, s.previewRepo))
+	return usecase.NewNotify(s.logger, s.taskQueue, registry)
+}
+
+// enqueueNotification delivers notification to the channel (e.g. /enqueue/twitter)
+func (s *backendServer) enqueueNotification(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 540*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	n := notifier.Notification{}
+	if err := event.ParseTask(req.Form, &n); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.newNotify().Dispatch(ctx, chi.URLParam(req, "channel"), n); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
 // enqueueTweets enqueue tweets event
+// it remains for the tasks that are pushed before notification channels
 func (s *backendServer) enqueueTweets(w http.ResponseWriter, req *http.Request) {
 	ctx, cancel := context.WithTimeout(req.Context(), 540*time.Second)
 	defer cancel()
@@ -180,6 +355,7 @@ func (s *backendServer) enqueueTweets(w http.ResponseWriter, req *http.Request)
 }
 
 // enqueueLines enqueue lines event
+// it remains for the tasks that are pushed before notification channels
 func (s *backendServer) enqueueLines(w http.ResponseWriter, req *http.Request) {
 	ctx, cancel := context.WithTimeout(req.Context(), 540*time.Second)
 	defer cancel()
@@ -208,6 +384,65 @@ func (s *backendServer) enqueueLines(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// backfillFeedStart starts backfill of the feed given code (e.g. /backfill/feed?code=aenews)
+func (s *backendServer) backfillFeedStart(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+
+	params := usecase.BackfillFeedParams{
+		Request: crawler.ArchiveRequest{
+			Code:   crawler.FeedCode(req.URL.Query().Get("code")),
+			Cursor: req.URL.Query().Get("cursor"),
+		},
+	}
+	backfillFeed := usecase.NewBackfillFeed(
+		s.logger,
+		s.archiveFetcher,
+		s.taskQueue,
+		s.transactor,
+		s.latestEntryRepo,
+		s.tweetItemRepo,
+		s.lineItemRepo,
+		s.channelItemRepo,
+	)
+	if err := backfillFeed.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// backfillFeed backfills a page of the feed archive
+func (s *backendServer) backfillFeed(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 540*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	params := usecase.BackfillFeedParams{}
+	if err := event.ParseTask(req.Form, &params.Request); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	backfillFeed := usecase.NewBackfillFeed(
+		s.logger,
+		s.archiveFetcher,
+		s.taskQueue,
+		s.transactor,
+		s.latestEntryRepo,
+		s.tweetItemRepo,
+		s.lineItemRepo,
+		s.channelItemRepo,
+	)
+	if err := backfillFeed.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
 // lineBotCallback handler
 func (s *backendServer) lineBotCallback(w http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
@@ -226,6 +461,7 @@ func (s *backendServer) lineBotCallback(w http.ResponseWriter, req *http.Request
 		s.logger,
 		s.linebotClient,
 		s.imageSearcher,
+		s.lineNotificationRepo,
 	)
 	params := usecase.HandleLineBotEventsParams{Events: events}
 	if err := handleLineBotEvents.Do(ctx, params); err != nil {
@@ -265,7 +501,13 @@ func (s *backendServer) lineNotifyOn(w http.ResponseWriter, req *http.Request) {
 		failResponse(ctx, w, err, http.StatusInternalServerError)
 		return
 	}
-	http.SetCookie(w, &http.Cookie{Name: "state", Value: c.State, Expires: time.Now().Add(300 * time.Second), Secure: true})
+	expires := time.Now().Add(300 * time.Second)
+	http.SetCookie(w, &http.Cookie{Name: "state", Value: c.State, Expires: expires, Secure: true})
+	if user := req.FormValue("user"); user != "" {
+		// the LINE bot user who requested the connection is linked to the token on callback
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: user, Expires: expires, Secure: true, HttpOnly: true})
+		http.SetCookie(w, &http.Cookie{Name: "user_token", Value: req.FormValue("token"), Expires: expires, Secure: true, HttpOnly: true})
+	}
 
 	s.logger.Info(ctx, "Redirect to LINE Notify connection page")
 
@@ -309,10 +551,18 @@ func (s *backendServer) lineNotifyCallback(w http.ResponseWriter, req *http.Requ
 
 	addLineNotification := usecase.NewAddLineNotification(
 		s.logger,
+		s.taskQueue,
 		s.linenotifyToken,
 		s.lineNotificationRepo,
 	)
-	if err := addLineNotification.Do(ctx, usecase.AddLineNotificationParams{Code: params.Code}); err != nil {
+	addParams := usecase.AddLineNotificationParams{Code: params.Code}
+	if user, err := req.Cookie("user"); err == nil {
+		addParams.UserHash = user.Value
+		if token, err := req.Cookie("user_token"); err == nil {
+			addParams.UserToken = token.Value
+		}
+	}
+	if err := addLineNotification.Do(ctx, addParams); err != nil {
 		failResponse(ctx, w, err, http.StatusInternalServerError)
 		return
 	}
@@ -329,27 +579,105 @@ func (s *backendServer) lineNotifyCallback(w http.ResponseWriter, req *http.Requ
 	}
 }
 
-// lineNotifyBroadcast invokes broadcast line notification event
-func (s *backendServer) lineNotifyBroadcast(w http.ResponseWriter, req *http.Request) {
+// lineNotifyPreferences shows the preferences page of LINE Notify subscriber given signed link
+func (s *backendServer) lineNotifyPreferences(w http.ResponseWriter, req *http.Request) {
+	s.renderLineNotifyPreferences(w, req, false)
+}
+
+// lineNotifyPreferencesUpdate saves the preferences of LINE Notify subscriber given form values
+// (e.g. id=...&token=...&codes=momota-sd&codes=aenews&members=高城れに&ustream=on)
+func (s *backendServer) lineNotifyPreferencesUpdate(w http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 
 	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	updatePreferences := usecase.NewUpdateLineNotifyPreferences(s.logger, s.lineNotificationRepo)
+	params := usecase.UpdateLineNotifyPreferencesParams{
+		ID:    req.PostForm.Get("id"),
+		Token: req.PostForm.Get("token"),
+		Preferences: usecase.LineNotifyPreferences{
+			Codes:    req.PostForm["codes"],
+			Members:  req.PostForm["members"],
+			Ustream:  req.PostForm.Get("ustream") != "",
+			Reminder: req.PostForm.Get("reminder") != "",
+		},
+	}
+	if err := updatePreferences.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+	s.renderLineNotifyPreferences(w, req, true)
+}
+
+// renderLineNotifyPreferences renders the preferences page
+func (s *backendServer) renderLineNotifyPreferences(w http.ResponseWriter, req *http.Request, saved bool) {
+	ctx := req.Context()
+
+	showPreferences := usecase.NewShowLineNotifyPreferences(s.logger, s.lineNotificationRepo)
+	params := usecase.ShowLineNotifyPreferencesParams{ID: req.FormValue("id"), Token: req.FormValue("token")}
+	result, err := showPreferences.Do(ctx, params)
+	if err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	data := struct {
+		ID    string
+		Token string
+		Saved bool
+		usecase.ShowLineNotifyPreferencesResult
+	}{params.ID, params.Token, saved, result}
+
+	funcs := template.FuncMap{"contains": func(a []string, s string) bool {
+		for _, v := range a {
+			if v == s {
+				return true
+			}
+		}
+		return false
+	}}
+	tpl := template.Must(template.New("preferences.html").Funcs(funcs).ParseFiles("public/templates/linenotify/preferences.html"))
+	if err := tpl.Execute(w, data); err != nil {
 		failResponse(ctx, w, err, http.StatusInternalServerError)
 		return
 	}
+}
 
-	var messages []linenotify.Message
-	if err := event.ParseTask(req.Form, &messages); err != nil {
+// lineNotifyBroadcast invokes broadcast line notification event
+func (s *backendServer) lineNotifyBroadcast(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if err := req.ParseForm(); err != nil {
 		failResponse(ctx, w, err, http.StatusInternalServerError)
 		return
 	}
 
+	var broadcast linenotify.Broadcast
+	if err := event.ParseTask(req.Form, &broadcast); err != nil {
+		// the payload of the tasks enqueued before Broadcast is the array of messages, that all subscribers accept
+		var messages []linenotify.Message
+		if err := event.ParseTask(req.Form, &messages); err != nil {
+			failResponse(ctx, w, err, http.StatusInternalServerError)
+			return
+		}
+		broadcast = linenotify.Broadcast{Messages: messages}
+	}
+
 	lineNotifyBroadcast := usecase.NewLineNotifyBroadcast(
 		s.logger,
 		s.taskQueue,
 		s.lineNotificationRepo,
+		s.previewRepo,
 	)
-	params := usecase.LineNotifyBroadcastParams{Messages: messages}
+	params := usecase.LineNotifyBroadcastParams{
+		Messages: broadcast.Messages,
+		Kind:     notifier.Kind(broadcast.Kind),
+		Code:     broadcast.Code,
+		Members:  broadcast.Members,
+	}
 	if err := lineNotifyBroadcast.Do(ctx, params); err != nil {
 		failResponse(ctx, w, err, http.StatusInternalServerError)
 		return
@@ -384,3 +712,542 @@ func (s *backendServer) lineNotify(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 }
+
+// discordWebhookAdd adds Discord webhook url given form value
+func (s *backendServer) discordWebhookAdd(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	addDiscordWebhook := usecase.NewAddDiscordWebhook(s.logger, s.discordWebhookRepo)
+	params := usecase.AddDiscordWebhookParams{URL: req.FormValue("url")}
+	if err := addDiscordWebhook.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+}
+
+// discordNotify posts message to Discord webhook
+func (s *backendServer) discordNotify(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var request discord.Request
+	if err := event.ParseTask(req.Form, &request); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	discordNotify := usecase.NewDiscordNotify(
+		s.logger,
+		s.taskQueue,
+		s.discordClient,
+		s.discordWebhookRepo,
+	)
+	params := usecase.DiscordNotifyParams{Request: request}
+	if err := discordNotify.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// slackWebhookAdd adds Slack webhook url given form values (e.g. url=...&codes=momota-sd,reminder)
+func (s *backendServer) slackWebhookAdd(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var codes []string
+	if v := req.FormValue("codes"); v != "" {
+		codes = strings.Split(v, ",")
+	}
+
+	addSlackWebhook := usecase.NewAddSlackWebhook(s.logger, s.slackWebhookRepo)
+	params := usecase.AddSlackWebhookParams{URL: req.FormValue("url"), Codes: codes}
+	if err := addSlackWebhook.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+}
+
+// slackNotify posts message to Slack webhook
+func (s *backendServer) slackNotify(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var request slack.Request
+	if err := event.ParseTask(req.Form, &request); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	slackNotify := usecase.NewSlackNotify(
+		s.logger,
+		s.taskQueue,
+		s.slackClient,
+		s.slackWebhookRepo,
+	)
+	params := usecase.SlackNotifyParams{Request: request}
+	if err := slackNotify.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// emailSubscribe registers email subscriber given form values (e.g. email=...&frequency=daily)
+func (s *backendServer) emailSubscribe(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	subscribeEmail := usecase.NewSubscribeEmail(s.logger, s.mailer, s.emailSubscriberRepo)
+	params := usecase.SubscribeEmailParams{Email: req.FormValue("email"), Frequency: req.FormValue("frequency")}
+	if err := subscribeEmail.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+	messageResponse(ctx, w, "確認メールを送信しました（・Θ・）")
+}
+
+// emailConfirm confirms email subscriber given signed link
+func (s *backendServer) emailConfirm(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	confirmEmail := usecase.NewConfirmEmail(s.logger, s.emailSubscriberRepo)
+	params := usecase.ConfirmEmailParams{ID: req.FormValue("id"), Token: req.FormValue("token")}
+	if err := confirmEmail.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+	messageResponse(ctx, w, "メール通知を登録しました（・Θ・）")
+}
+
+// emailUnsubscribe deletes email subscriber given signed link
+// it accepts POST of one-click unsubscribe from mail clients as well as GET (see: RFC 8058)
+func (s *backendServer) emailUnsubscribe(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	unsubscribeEmail := usecase.NewUnsubscribeEmail(s.logger, s.emailSubscriberRepo)
+	params := usecase.UnsubscribeEmailParams{ID: req.FormValue("id"), Token: req.FormValue("token")}
+	if err := unsubscribeEmail.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+	messageResponse(ctx, w, "メール通知を解除しました（・Θ・）")
+}
+
+// emailManage changes the frequency of email subscriber given signed link (e.g. /email/manage?id=...&token=...&frequency=weekly)
+func (s *backendServer) emailManage(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	updateEmailFrequency := usecase.NewUpdateEmailFrequency(s.logger, s.emailSubscriberRepo)
+	params := usecase.UpdateEmailFrequencyParams{ID: req.FormValue("id"), Token: req.FormValue("token"), Frequency: req.FormValue("frequency")}
+	if err := updateEmailFrequency.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+	messageResponse(ctx, w, "メール通知を変更しました（・Θ・）")
+}
+
+// emailSend sends mail
+func (s *backendServer) emailSend(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var mail mailer.Mail
+	if err := event.ParseTask(req.Form, &mail); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	sendEmail := usecase.NewSendEmail(s.logger, s.mailer)
+	if err := sendEmail.Do(ctx, usecase.SendEmailParams{Mail: mail}); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// webhookSubscriberAdd adds webhook subscriber given form value and responses the secret to verify signatures
+func (s *backendServer) webhookSubscriberAdd(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	addWebhookSubscriber := usecase.NewAddWebhookSubscriber(s.logger, s.webhookSubscriberRepo)
+	subscriber, err := addWebhookSubscriber.Do(ctx, usecase.AddWebhookSubscriberParams{URL: req.FormValue("url")})
+	if err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+	secret, err := subscriber.Secret(config.C().Webhook.TokenKey)
+	if err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	res := struct {
+		ID     string `json:"id"`
+		URL    string `json:"url"`
+		Secret string `json:"secret"`
+	}{subscriber.ID, subscriber.URL, secret}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		s.logger.Errorf(ctx, "webhookSubscriberAdd: encode err:%v", err)
+	}
+}
+
+// webhookDeliveries responses recent deliveries (e.g. /webhook/deliveries?subscriber_id=...)
+func (s *backendServer) webhookDeliveries(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	deliveries, err := s.webhookDeliveryRepo.FindRecent(ctx, req.FormValue("subscriber_id"), 100)
+	if err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+		s.logger.Errorf(ctx, "webhookDeliveries: encode err:%v", err)
+	}
+}
+
+// webhookDeliver delivers event to webhook subscriber
+func (s *backendServer) webhookDeliver(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var id string
+	if err := event.ParseTask(req.Form, &id); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	deliverWebhook := usecase.NewDeliverWebhook(
+		s.logger,
+		s.taskQueue,
+		s.webhookClient,
+		s.webhookSubscriberRepo,
+		s.webhookDeliveryRepo,
+	)
+	if err := deliverWebhook.Do(ctx, usecase.DeliverWebhookParams{DeliveryID: id}); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// webPushPage responses the page to subscribe browser push notifications
+func (s *backendServer) webPushPage(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	publicKey, err := webpush.PublicKey(config.C().WebPush.PrivateKey)
+	if err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	tpl := template.Must(template.ParseFiles("public/templates/webpush/subscribe.html"))
+	if err := tpl.Execute(w, struct{ PublicKey string }{publicKey}); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// webPushSubscribe saves PushSubscription given JSON of the browser
+func (s *backendServer) webPushSubscribe(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var subscription webpush.Subscription
+	if err := json.NewDecoder(req.Body).Decode(&subscription); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	subscribeWebPush := usecase.NewSubscribeWebPush(s.logger, s.webPushSubscriptionRepo)
+	if err := subscribeWebPush.Do(ctx, usecase.SubscribeWebPushParams{Subscription: subscription}); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+}
+
+// webPushUnsubscribe deletes PushSubscription given JSON of the endpoint
+func (s *backendServer) webPushUnsubscribe(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var body struct {
+		Endpoint string `json:"endpoint"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	unsubscribeWebPush := usecase.NewUnsubscribeWebPush(s.logger, s.webPushSubscriptionRepo)
+	if err := unsubscribeWebPush.Do(ctx, usecase.UnsubscribeWebPushParams{Endpoint: body.Endpoint}); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+}
+
+// webPushBroadcast enqueues a push for each subscription
+func (s *backendServer) webPushBroadcast(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var message webpush.Message
+	if err := event.ParseTask(req.Form, &message); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	webPushBroadcast := usecase.NewWebPushBroadcast(
+		s.logger,
+		s.taskQueue,
+		s.webPushSubscriptionRepo,
+		s.previewRepo,
+	)
+	if err := webPushBroadcast.Do(ctx, usecase.WebPushBroadcastParams{Message: message}); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// webPushSend sends a push to a subscriber
+func (s *backendServer) webPushSend(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var request webpush.Request
+	if err := event.ParseTask(req.Form, &request); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	sendWebPush := usecase.NewSendWebPush(s.logger, s.webpushClient, s.webPushSubscriptionRepo)
+	if err := sendWebPush.Do(ctx, usecase.SendWebPushParams{Request: request}); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// telegramWebhook handler
+func (s *backendServer) telegramWebhook(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	update, err := telegram.ParseRequest(req)
+	if err != nil {
+		if err == telegram.ErrInvalidSecretToken {
+			failResponse(ctx, w, err, http.StatusBadRequest)
+			return
+		}
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	handleTelegramUpdate := usecase.NewHandleTelegramUpdate(
+		s.logger,
+		s.telegramClient,
+		s.imageSearcher,
+		s.telegramSubscriberRepo,
+	)
+	params := usecase.HandleTelegramUpdateParams{Update: update}
+	if err := handleTelegramUpdate.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// telegramSend sends message to Telegram chat
+func (s *backendServer) telegramSend(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var request telegram.Request
+	if err := event.ParseTask(req.Form, &request); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	telegramNotify := usecase.NewTelegramNotify(
+		s.logger,
+		s.taskQueue,
+		s.telegramClient,
+		s.telegramSubscriberRepo,
+	)
+	params := usecase.TelegramNotifyParams{Request: request}
+	if err := telegramNotify.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// websubHub accepts subscription requests of WebSub subscribers (e.g. hub.mode=subscribe&hub.topic=...&hub.callback=...)
+// it responds 202 Accepted, and the intent of the subscriber is verified asynchronously
+func (s *backendServer) websubHub(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	leaseSeconds, _ := strconv.Atoi(req.FormValue("hub.lease_seconds"))
+	requestSubscription := usecase.NewRequestWebSubSubscription(s.logger, s.taskQueue)
+	params := usecase.RequestWebSubSubscriptionParams{
+		Request: websub.Request{
+			Mode:         req.FormValue("hub.mode"),
+			Topic:        req.FormValue("hub.topic"),
+			Callback:     req.FormValue("hub.callback"),
+			Secret:       req.FormValue("hub.secret"),
+			LeaseSeconds: leaseSeconds,
+		},
+	}
+	if err := requestSubscription.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// websubVerify verifies the intent of WebSub subscriber
+func (s *backendServer) websubVerify(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var r websub.Request
+	if err := event.ParseTask(req.Form, &r); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	verifySubscription := usecase.NewVerifyWebSubSubscription(s.logger, s.websubClient, s.webSubSubscriptionRepo)
+	if err := verifySubscription.Do(ctx, usecase.VerifyWebSubSubscriptionParams{Request: r}); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// websubPublish enqueues content distribution to WebSub subscribers
+func (s *backendServer) websubPublish(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	publish := usecase.NewPublishWebSub(s.logger, s.taskQueue, s.webSubSubscriptionRepo)
+	if err := publish.Do(ctx); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// websubDistribute distributes the content of the topic to WebSub subscriber
+func (s *backendServer) websubDistribute(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var id string
+	if err := event.ParseTask(req.Form, &id); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	distribute := usecase.NewDistributeWebSub(s.logger, s.websubClient, s.webSubSubscriptionRepo, s.lineItemRepo)
+	if err := distribute.Do(ctx, usecase.DistributeWebSubParams{SubscriptionID: id}); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// feedAtom responses the aggregated timeline as Atom (e.g. /feed.atom?code=momota-sd)
+func (s *backendServer) feedAtom(w http.ResponseWriter, req *http.Request) {
+	s.serveFeed(w, req, syndication.ContentTypeAtom, syndication.Feed.Atom)
+}
+
+// feedRSS responses the aggregated timeline as RSS 2.0
+func (s *backendServer) feedRSS(w http.ResponseWriter, req *http.Request) {
+	s.serveFeed(w, req, syndication.ContentTypeRSS, syndication.Feed.RSS)
+}
+
+// feedJSON responses the aggregated timeline as JSON Feed
+func (s *backendServer) feedJSON(w http.ResponseWriter, req *http.Request) {
+	s.serveFeed(w, req, syndication.ContentTypeJSON, syndication.Feed.JSON)
+}
+
+// serveFeed responses the feed document that is encoded by given function
+// it responds 304 Not Modified to conditional requests of feed readers, and advertises WebSub hub
+func (s *backendServer) serveFeed(w http.ResponseWriter, req *http.Request, contentType string, encode func(syndication.Feed) ([]byte, error)) {
+	ctx := req.Context()
+
+	baseURL := config.C().App.BaseURL
+	buildFeed := usecase.NewBuildFeed(s.logger, s.lineItemRepo)
+	params := usecase.BuildFeedParams{
+		Code:    req.FormValue("code"),
+		SiteURL: baseURL + "/",
+		FeedURL: baseURL + req.URL.RequestURI(),
+		HubURL:  websub.HubURL(),
+	}
+	feed, err := buildFeed.Do(ctx, params)
+	if err != nil {
+		failResponse(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	b, err := encode(feed)
+	if err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(b)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("ETag", fmt.Sprintf(`"%x"`, sum[:16]))
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="hub"`, feed.HubURL))
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="self"`, feed.FeedURL))
+	http.ServeContent(w, req, "", feed.Updated, bytes.NewReader(b))
+}
+
+// preview responses recent messages that are recorded in dry-run mode
+func (s *backendServer) preview(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	previews, err := s.previewRepo.FindRecent(ctx, 100)
+	if err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(previews); err != nil {
+		s.logger.Errorf(ctx, "preview: encode err:%v", err)
+	}
+}
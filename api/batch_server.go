@@ -7,8 +7,12 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/utahta/momoclo-channel/api/middleware"
+	"github.com/utahta/momoclo-channel/bluesky"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/event"
 	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mastodon"
 	"github.com/utahta/momoclo-channel/twitter"
 	"github.com/utahta/momoclo-channel/usecase"
 )
@@ -18,6 +22,11 @@ type (
 		logger    log.Logger
 		taskQueue event.TaskQueue
 		tweeter   twitter.Tweeter
+		mastodon  mastodon.Client
+		bluesky   bluesky.Client
+		preview   entity.PreviewRepository
+
+		blueskySessionRepo entity.BlueskySessionRepository
 	}
 )
 
@@ -27,15 +36,23 @@ func NewBatchServer() Server {
 		logger:    log.NewAELogger(),
 		taskQueue: event.NewTaskQueue(),
 		tweeter:   twitter.NewTweeter(),
+		mastodon:  mastodon.New(),
+		bluesky:   bluesky.New(),
+		preview:   entity.NewPreviewRepository(dao.NewDatastoreHandler()),
+
+		blueskySessionRepo: entity.NewBlueskySessionRepository(dao.NewDatastoreHandler()),
 	}
 }
 
 func (s *batchServer) Handle() {
 	r := chi.NewRouter()
 	r.Use(middleware.AEContext)
+	r.Use(middleware.DryRun)
 
 	r.Get("/_ah/start", func(w http.ResponseWriter, req *http.Request) {}) // nop
 	r.Post("/tweet", s.tweet)
+	r.Post("/mastodon/status", s.mastodonStatus)
+	r.Post("/bluesky/post", s.blueskyPost)
 
 	http.Handle("/", r)
 }
@@ -60,6 +77,7 @@ func (s *batchServer) tweet(w http.ResponseWriter, req *http.Request) {
 		s.logger,
 		s.taskQueue,
 		s.tweeter,
+		s.preview,
 	)
 	params := usecase.TweetParams{Requests: requests}
 	if err := tweet.Do(ctx, params); err != nil {
@@ -67,3 +85,62 @@ func (s *batchServer) tweet(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 }
+
+// mastodonStatus posts statuses to mastodon
+func (s *batchServer) mastodonStatus(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 180*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var requests []mastodon.StatusRequest
+	if err := event.ParseTask(req.Form, &requests); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	postStatuses := usecase.NewPostMastodonStatuses(
+		s.logger,
+		s.taskQueue,
+		s.mastodon,
+		s.preview,
+	)
+	params := usecase.PostMastodonStatusesParams{Requests: requests}
+	if err := postStatuses.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// blueskyPost posts a thread to bluesky
+func (s *batchServer) blueskyPost(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), 180*time.Second)
+	defer cancel()
+
+	if err := req.ParseForm(); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var requests []bluesky.PostRequest
+	if err := event.ParseTask(req.Form, &requests); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	postThread := usecase.NewPostBlueskyThread(
+		s.logger,
+		s.taskQueue,
+		s.bluesky,
+		s.blueskySessionRepo,
+		s.preview,
+	)
+	params := usecase.PostBlueskyThreadParams{Requests: requests}
+	if err := postThread.Do(ctx, params); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+		return
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"html/template"
 	"net/http"
 
 	"github.com/utahta/momoclo-channel/log"
@@ -17,3 +18,13 @@ func failResponse(ctx context.Context, w http.ResponseWriter, err error, code in
 	log.NewAELogger().Errorf(ctx, "An error has occurred! code:%v err:%+v", code, err)
 	http.Error(w, message, code)
 }
+
+// messageTemplate is the page that shows a message to users
+var messageTemplate = template.Must(template.New("message").Parse("<html><body><h1>{{.}}</h1></body></html>"))
+
+// messageResponse responses the page of given message
+func messageResponse(ctx context.Context, w http.ResponseWriter, message string) {
+	if err := messageTemplate.Execute(w, message); err != nil {
+		failResponse(ctx, w, err, http.StatusInternalServerError)
+	}
+}
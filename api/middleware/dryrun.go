@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/utahta/momoclo-channel/dryrun"
+)
+
+// DryRun enables dry-run mode if the request has the header or the query parameter
+func DryRun(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(dryrun.Header) == "1" || r.URL.Query().Get(dryrun.Param) == "1" {
+			r = r.WithContext(dryrun.WithContext(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
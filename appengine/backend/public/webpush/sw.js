@@ -0,0 +1,16 @@
+self.addEventListener('push', function (event) {
+    var message = event.data ? event.data.json() : {title: 'ももクロちゃんねる'};
+    event.waitUntil(self.registration.showNotification(message.title, {
+        body: message.body,
+        image: message.image,
+        data: {url: message.url}
+    }));
+});
+
+self.addEventListener('notificationclick', function (event) {
+    event.notification.close();
+    var url = event.notification.data && event.notification.data.url;
+    if (url) {
+        event.waitUntil(clients.openWindow(url));
+    }
+});
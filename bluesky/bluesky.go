@@ -0,0 +1,267 @@
+package bluesky
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"google.golang.org/appengine/urlfetch"
+)
+
+const (
+	// DefaultService is the PDS url that is used if config is empty
+	DefaultService = "https://bsky.social"
+
+	// MaxImageNum is the max number of images in a post
+	MaxImageNum = 4
+
+	// MaxTextLength is the max length of text in a post
+	MaxTextLength = 300
+)
+
+// maxImageSize is the max size of an image blob
+const maxImageSize = 1000000
+
+// ErrExpiredToken is returned when the token of the session is expired
+var ErrExpiredToken = errors.New("bluesky: expired token")
+
+type (
+	// Session represents an authenticated session of the account
+	Session struct {
+		DID        string `json:"did"`
+		Handle     string `json:"handle"`
+		AccessJwt  string `json:"accessJwt"`
+		RefreshJwt string `json:"refreshJwt"`
+	}
+
+	// StrongRef represents a reference to a record
+	StrongRef struct {
+		URI string `json:"uri" validate:"required"`
+		CID string `json:"cid" validate:"required"`
+	}
+
+	// ReplyRef represents the thread that a post replies to
+	ReplyRef struct {
+		Root   StrongRef `json:"root"`
+		Parent StrongRef `json:"parent"`
+	}
+
+	// PostRequest represents request that creates a post with images
+	PostRequest struct {
+		Text      string
+		ImageURLs []string `validate:"max=4,dive,url"`
+		Reply     *ReplyRef
+	}
+
+	// Client interface
+	Client interface {
+		CreateSession(context.Context) (Session, error)
+		RefreshSession(context.Context, Session) (Session, error)
+		Post(context.Context, Session, PostRequest) (StrongRef, error)
+	}
+
+	client struct {
+		service    string
+		identifier string
+		password   string
+		httpClient func(context.Context) *http.Client
+	}
+
+	postRecord struct {
+		Type      string       `json:"$type"`
+		Text      string       `json:"text"`
+		CreatedAt string       `json:"createdAt"`
+		Langs     []string     `json:"langs,omitempty"`
+		Facets    []facet      `json:"facets,omitempty"`
+		Embed     *imagesEmbed `json:"embed,omitempty"`
+		Reply     *ReplyRef    `json:"reply,omitempty"`
+	}
+
+	imagesEmbed struct {
+		Type   string       `json:"$type"`
+		Images []embedImage `json:"images"`
+	}
+
+	embedImage struct {
+		Alt   string          `json:"alt"`
+		Image json.RawMessage `json:"image"`
+	}
+
+	xrpcError struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+)
+
+// New returns Client that posts to the account of config
+func New() Client {
+	c := config.C().Bluesky
+	if c.Disabled {
+		return NewNop()
+	}
+	return NewWithHTTPClient(c.Service, c.Identifier, c.AppPassword, urlfetch.Client)
+}
+
+// NewWithHTTPClient returns Client that posts through given http client
+func NewWithHTTPClient(service, identifier, password string, fn func(context.Context) *http.Client) Client {
+	if service == "" {
+		service = DefaultService
+	}
+	return &client{
+		service:    strings.TrimSuffix(service, "/"),
+		identifier: identifier,
+		password:   password,
+		httpClient: fn,
+	}
+}
+
+// CreateSession signs in with the app password
+func (c *client) CreateSession(ctx context.Context) (Session, error) {
+	const errTag = "bluesky.CreateSession failed"
+
+	body := map[string]string{"identifier": c.identifier, "password": c.password}
+	var s Session
+	if err := c.call(ctx, "com.atproto.server.createSession", "", body, &s); err != nil {
+		return Session{}, errors.Wrap(err, errTag)
+	}
+	return s, nil
+}
+
+// RefreshSession returns the session that is refreshed by the refresh token
+func (c *client) RefreshSession(ctx context.Context, s Session) (Session, error) {
+	const errTag = "bluesky.RefreshSession failed"
+
+	var refreshed Session
+	if err := c.call(ctx, "com.atproto.server.refreshSession", s.RefreshJwt, nil, &refreshed); err != nil {
+		return Session{}, errors.Wrap(err, errTag)
+	}
+	return refreshed, nil
+}
+
+// Post uploads images and creates a post record
+func (c *client) Post(ctx context.Context, s Session, req PostRequest) (StrongRef, error) {
+	const errTag = "bluesky.Post failed"
+
+	record := postRecord{
+		Type:      "app.bsky.feed.post",
+		Text:      req.Text,
+		CreatedAt: timeutil.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+		Langs:     []string{"ja"},
+		Facets:    detectFacets(req.Text),
+		Reply:     req.Reply,
+	}
+	if len(req.ImageURLs) > 0 {
+		record.Embed = &imagesEmbed{Type: "app.bsky.embed.images"}
+		for _, imageURL := range req.ImageURLs {
+			blob, err := c.uploadBlob(ctx, s, imageURL)
+			if err != nil {
+				return StrongRef{}, errors.Wrapf(err, "%v: image:%v", errTag, imageURL)
+			}
+			record.Embed.Images = append(record.Embed.Images, embedImage{Image: blob})
+		}
+	}
+
+	body := map[string]interface{}{
+		"repo":       s.DID,
+		"collection": record.Type,
+		"record":     record,
+	}
+	var ref StrongRef
+	if err := c.call(ctx, "com.atproto.repo.createRecord", s.AccessJwt, body, &ref); err != nil {
+		return StrongRef{}, errors.Wrap(err, errTag)
+	}
+	return ref, nil
+}
+
+// uploadBlob uploads the image of given url and returns the blob object
+func (c *client) uploadBlob(ctx context.Context, s Session, imageURL string) (json.RawMessage, error) {
+	resp, err := c.httpClient(ctx).Get(imageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetch image status:%v", resp.StatusCode)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxImageSize {
+		return nil, errors.Errorf("image is too large url:%v", imageURL)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(b)
+	}
+	r, err := http.NewRequest(http.MethodPost, c.service+"/xrpc/com.atproto.repo.uploadBlob", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", contentType)
+
+	var res struct {
+		Blob json.RawMessage `json:"blob"`
+	}
+	if err := c.do(ctx, r, s.AccessJwt, &res); err != nil {
+		return nil, err
+	}
+	return res.Blob, nil
+}
+
+// call calls the xrpc procedure with json body
+func (c *client) call(ctx context.Context, nsid, token string, body interface{}, v interface{}) error {
+	var buf io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		buf = bytes.NewReader(b)
+	}
+
+	r, err := http.NewRequest(http.MethodPost, c.service+"/xrpc/"+nsid, buf)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(ctx, r, token, v)
+}
+
+// do sends the request with the token and decodes json response
+func (c *client) do(ctx context.Context, r *http.Request, token string, v interface{}) error {
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient(ctx).Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var e xrpcError
+		json.Unmarshal(b, &e)
+		if e.Error == "ExpiredToken" {
+			return ErrExpiredToken
+		}
+		return errors.Errorf("status:%v body:%s", resp.StatusCode, b)
+	}
+	return json.Unmarshal(b, v)
+}
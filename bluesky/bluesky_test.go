@@ -0,0 +1,156 @@
+package bluesky
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestDetectFacets(t *testing.T) {
+	text := "百田夏菜子 ブログ\nhttps://ameblo.jp/momota-sd/entry-1.html\n#momoclo #ももクロ。"
+	facets := detectFacets(text)
+	if len(facets) != 3 {
+		t.Fatalf("Expected facets length 3, got %v", len(facets))
+	}
+
+	tests := []struct {
+		text    string
+		feature facetFeature
+	}{
+		{"https://ameblo.jp/momota-sd/entry-1.html", facetFeature{Type: "app.bsky.richtext.facet#link", URI: "https://ameblo.jp/momota-sd/entry-1.html"}},
+		{"#momoclo", facetFeature{Type: "app.bsky.richtext.facet#tag", Tag: "momoclo"}},
+		{"#ももクロ", facetFeature{Type: "app.bsky.richtext.facet#tag", Tag: "ももクロ"}},
+	}
+	for i, test := range tests {
+		f := facets[i]
+		if v := text[f.Index.ByteStart:f.Index.ByteEnd]; v != test.text {
+			t.Errorf("Expected text %v, got %v", test.text, v)
+		}
+		if !reflect.DeepEqual(f.Features, []facetFeature{test.feature}) {
+			t.Errorf("Expected feature %v, got %v", test.feature, f.Features)
+		}
+	}
+
+	if facets := detectFacets("no#tag # http:/"); len(facets) != 0 {
+		t.Errorf("Expected no facets, got %v", facets)
+	}
+}
+
+func TestClient_Post(t *testing.T) {
+	var (
+		blobs  int
+		auth   string
+		record map[string]interface{}
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/images/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("image"))
+	})
+	mux.HandleFunc("/xrpc/com.atproto.repo.uploadBlob", func(w http.ResponseWriter, r *http.Request) {
+		if v := r.Header.Get("Content-Type"); v != "image/jpeg" {
+			t.Errorf("Expected content type image/jpeg, got %v", v)
+		}
+		blobs++
+		w.Write([]byte(`{"blob":{"$type":"blob","ref":{"$link":"bafk"},"mimeType":"image/jpeg","size":5}}`))
+	})
+	mux.HandleFunc("/xrpc/com.atproto.repo.createRecord", func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		var body struct {
+			Repo   string                 `json:"repo"`
+			Record map[string]interface{} `json:"record"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Repo != "did:plc:test" {
+			t.Errorf("Expected repo did:plc:test, got %v", body.Repo)
+		}
+		record = body.Record
+		w.Write([]byte(`{"uri":"at://did:plc:test/app.bsky.feed.post/2","cid":"cid2"}`))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	c := NewWithHTTPClient(s.URL, "test", "pass", func(context.Context) *http.Client { return http.DefaultClient })
+	reply := &ReplyRef{
+		Root:   StrongRef{URI: "at://did:plc:test/app.bsky.feed.post/1", CID: "cid1"},
+		Parent: StrongRef{URI: "at://did:plc:test/app.bsky.feed.post/1", CID: "cid1"},
+	}
+	ref, err := c.Post(context.Background(), Session{DID: "did:plc:test", AccessJwt: "access"}, PostRequest{
+		Text:      "hello #momoclo",
+		ImageURLs: []string{s.URL + "/images/1.jpg", s.URL + "/images/2.jpg"},
+		Reply:     reply,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ref.CID != "cid2" {
+		t.Errorf("Expected cid2, got %v", ref.CID)
+	}
+	if auth != "Bearer access" {
+		t.Errorf("Expected bearer access, got %v", auth)
+	}
+	if blobs != 2 {
+		t.Errorf("Expected 2 blobs, got %v", blobs)
+	}
+	if record["text"] != "hello #momoclo" || record["$type"] != "app.bsky.feed.post" {
+		t.Errorf("Unexpected record %v", record)
+	}
+	if facets, ok := record["facets"].([]interface{}); !ok || len(facets) != 1 {
+		t.Errorf("Expected a facet, got %v", record["facets"])
+	}
+	if embed, ok := record["embed"].(map[string]interface{}); !ok || len(embed["images"].([]interface{})) != 2 {
+		t.Errorf("Expected 2 images, got %v", record["embed"])
+	}
+	if _, ok := record["reply"]; !ok {
+		t.Errorf("Expected reply, got %v", record)
+	}
+}
+
+func TestClient_Session(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/xrpc/com.atproto.server.createSession", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != `{"identifier":"test","password":"pass"}` {
+			t.Errorf("Unexpected body %s", b)
+		}
+		w.Write([]byte(`{"did":"did:plc:test","handle":"test","accessJwt":"access","refreshJwt":"refresh"}`))
+	})
+	mux.HandleFunc("/xrpc/com.atproto.server.refreshSession", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"ExpiredToken","message":"Token has expired"}`))
+			return
+		}
+		w.Write([]byte(`{"did":"did:plc:test","handle":"test","accessJwt":"access2","refreshJwt":"refresh2"}`))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	c := NewWithHTTPClient(s.URL, "test", "pass", func(context.Context) *http.Client { return http.DefaultClient })
+	session, err := c.CreateSession(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.AccessJwt != "access" || session.RefreshJwt != "refresh" {
+		t.Errorf("Unexpected session %v", session)
+	}
+
+	session, err = c.RefreshSession(context.Background(), session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.AccessJwt != "access2" {
+		t.Errorf("Expected access2, got %v", session.AccessJwt)
+	}
+
+	if _, err := c.RefreshSession(context.Background(), session); errors.Cause(err) != ErrExpiredToken {
+		t.Errorf("Expected ErrExpiredToken, got %v", err)
+	}
+}
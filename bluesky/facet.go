@@ -0,0 +1,57 @@
+package bluesky
+
+import (
+	"regexp"
+	"strings"
+)
+
+type (
+	// facet annotates a range of text (see: app.bsky.richtext.facet)
+	facet struct {
+		Index    facetIndex     `json:"index"`
+		Features []facetFeature `json:"features"`
+	}
+
+	// facetIndex is the range of text in UTF-8 bytes
+	facetIndex struct {
+		ByteStart int `json:"byteStart"`
+		ByteEnd   int `json:"byteEnd"`
+	}
+
+	facetFeature struct {
+		Type string `json:"$type"`
+		URI  string `json:"uri,omitempty"`
+		Tag  string `json:"tag,omitempty"`
+	}
+)
+
+var (
+	linkRegexp = regexp.MustCompile(`https?://[^\s]+`)
+	tagRegexp  = regexp.MustCompile(`(?:^|\s)(#[^\s#]+)`)
+)
+
+// trailingPunctuation is trimmed from the end of links and tags
+const trailingPunctuation = ".,;:!?)」』、。"
+
+// detectFacets returns link and hashtag facets in given text
+func detectFacets(text string) []facet {
+	var facets []facet
+	for _, loc := range linkRegexp.FindAllStringIndex(text, -1) {
+		uri := strings.TrimRight(text[loc[0]:loc[1]], trailingPunctuation)
+		facets = append(facets, facet{
+			Index:    facetIndex{ByteStart: loc[0], ByteEnd: loc[0] + len(uri)},
+			Features: []facetFeature{{Type: "app.bsky.richtext.facet#link", URI: uri}},
+		})
+	}
+	for _, loc := range tagRegexp.FindAllStringSubmatchIndex(text, -1) {
+		tag := strings.TrimRight(text[loc[2]:loc[3]], trailingPunctuation)
+		if len(tag) <= 1 {
+			continue
+		}
+		facets = append(facets, facet{
+			Index:    facetIndex{ByteStart: loc[2], ByteEnd: loc[2] + len(tag)},
+			Features: []facetFeature{{Type: "app.bsky.richtext.facet#tag", Tag: tag[1:]}},
+		})
+	}
+	return facets
+}
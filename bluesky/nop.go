@@ -0,0 +1,22 @@
+package bluesky
+
+import "context"
+
+type nop struct{}
+
+// NewNop returns no operation client
+func NewNop() Client {
+	return &nop{}
+}
+
+func (c *nop) CreateSession(_ context.Context) (Session, error) {
+	return Session{}, nil
+}
+
+func (c *nop) RefreshSession(_ context.Context, s Session) (Session, error) {
+	return s, nil
+}
+
+func (c *nop) Post(_ context.Context, _ Session, _ PostRequest) (StrongRef, error) {
+	return StrongRef{}, nil
+}
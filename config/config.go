@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/pelletier/go-toml"
+	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/timeutil"
 )
 
@@ -14,11 +15,26 @@ type Config struct {
 	LineBot            LineBot
 	GoogleCustomSearch GoogleCustomSearch
 	LineNotify         LineNotify
+	Discord            Discord
+	Slack              Slack
+	Mastodon           Mastodon
+	Bluesky            Bluesky
+	Mail               Mail
+	Webhook            Webhook
+	WebSub             WebSub
+	WebPush            WebPush
+	Telegram           Telegram
+	Crawler            Crawler
+	Feeds              []Feed
+	Notifier           Notifier
 }
 
 // App represents app entire settings
 type App struct {
 	BaseURL string
+
+	// DryRun records rendered messages as previews instead of delivering them
+	DryRun bool
 }
 
 // Twitter represents twitter settings
@@ -47,9 +63,147 @@ type LineNotify struct {
 	ClientID     string
 	ClientSecret string
 	TokenKey     string
+	SigningKey   string // the key to sign preferences links and LINE bot users
 	Disabled     bool
 }
 
+// Discord represents Discord webhook settings
+type Discord struct {
+	TokenKey string // the key to encrypt webhook urls
+	Disabled bool
+}
+
+// Slack represents Slack incoming webhook settings
+type Slack struct {
+	TokenKey string // the key to encrypt webhook urls
+	Disabled bool
+}
+
+// Mastodon represents Mastodon account settings
+type Mastodon struct {
+	InstanceURL string // e.g. "https://mstdn.jp"
+	AccessToken string
+
+	// Visibility is the default visibility of statuses (public, unlisted, private or direct)
+	Visibility string
+
+	// SpoilerText is the default content warning of statuses, no warning if empty
+	SpoilerText string
+
+	Disabled bool
+}
+
+// Bluesky represents Bluesky account settings
+type Bluesky struct {
+	Service     string // PDS url, "https://bsky.social" if empty
+	Identifier  string // handle or email of the account
+	AppPassword string
+	TokenKey    string // the key to encrypt session tokens
+	Disabled    bool
+}
+
+// Mail represents SMTP settings of email subscriptions
+type Mail struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	SigningKey string // the key to sign confirmation and unsubscribe links
+	Disabled   bool
+}
+
+// Webhook represents outgoing webhook settings
+type Webhook struct {
+	TokenKey string // the key to encrypt secrets of subscribers
+}
+
+// WebSub represents WebSub hub settings
+type WebSub struct {
+	TokenKey string // the key to encrypt secrets of subscribers
+}
+
+// WebPush represents Web Push settings
+type WebPush struct {
+	Subject    string // the contact of the sender (e.g. mailto:admin@example.com)
+	PrivateKey string // base64url encoded VAPID private key
+	TokenKey   string // the key to encrypt push subscriptions
+	Disabled   bool
+}
+
+// Telegram represents Telegram bot settings
+type Telegram struct {
+	BotToken    string
+	SecretToken string // the secret token of webhook that is registered by setWebhook
+	Disabled    bool
+}
+
+// Crawler represents crawler settings
+type Crawler struct {
+	CatchUpLimit int
+	DetectEdits  bool
+
+	// Concurrency is the max number of feeds that are crawled at the same time
+	Concurrency int
+
+	// HostInterval is the minimum interval between requests to the same host (e.g. "1s")
+	HostInterval string
+
+	// BackfillMaxPages is the max number of archive pages that a backfill walks through
+	BackfillMaxPages int
+
+	// notices of the follow-up notification when an entry is edited after notified
+	// the edit is not announced if empty
+	ImageAddedNotice   string
+	VideoAddedNotice   string
+	TitleChangedNotice string
+
+	// ExtractBody enables to fetch the entry page to extract the body on Ameblo
+	// the body of syndication source is always extracted from the feed document
+	ExtractBody bool
+
+	// SummaryLength is the max character count of the summary in LINE messages
+	// the summary is not included if zero
+	SummaryLength int
+
+	// FailureThreshold is the number of consecutive failures that alerts admins
+	// the alert is disabled if zero
+	FailureThreshold int
+
+	// StaleAfter is the duration without new items that alerts admins (e.g. "72h")
+	// the alert is disabled if empty
+	StaleAfter string
+}
+
+// Feed represents a feed settings that the crawler follows
+type Feed struct {
+	Code          string
+	Source        string
+	URL           string
+	URLPattern    string
+	Title         string
+	Enabled       bool
+	Schedule      string
+	ActiveWindows []string
+	StaleAfter    string
+	Color         string // theme colour of the member (e.g. "#ff0000")
+	Member        string // name of the member who writes the blog (e.g. "百田夏菜子")
+
+	// rule to canonicalize entry urls
+	CanonicalScheme   string
+	CanonicalHost     string
+	HostAliases       []string
+	StripParams       []string
+	TrimTrailingSlash bool
+}
+
+// Notifier represents notification channels settings
+type Notifier struct {
+	// Channels are the names of enabled channels (e.g. "twitter", "line")
+	// all registered channels are enabled if empty
+	Channels []string
+}
+
 var (
 	c *Config
 )
@@ -68,16 +222,28 @@ func MustLoad(path string) {
 }
 
 // Load loads config file
+// it fails if the host interval or the schedule of any feed is invalid, instead of ignoring them at every crawl
 func Load(path string) error {
 	t, err := toml.LoadFile(path)
 	if err != nil {
 		return err
 	}
 
-	c = &Config{}
-	if err := t.Unmarshal(c); err != nil {
+	v := &Config{}
+	if err := t.Unmarshal(v); err != nil {
 		return err
 	}
+	if v.Crawler.HostInterval != "" {
+		if _, err := time.ParseDuration(v.Crawler.HostInterval); err != nil {
+			return errors.Wrap(err, "invalid crawler host interval")
+		}
+	}
+	for _, f := range v.Feeds {
+		if _, err := timeutil.ParseSchedule(f.Schedule); err != nil {
+			return errors.Wrapf(err, "feed code:%v", f.Code)
+		}
+	}
+	c = v
 
 	time.Local = timeutil.JST()
 	return nil
@@ -0,0 +1,269 @@
+package crawler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// amebloEntryItemMarkers are attributes of the element of an entry in the entry list
+	amebloEntryItemMarkers = []html.Attribute{
+		{Key: "data-uranus-component", Val: "entryItem"},
+		{Key: "class", Val: "skin-borderQuiet"},
+	}
+
+	// amebloEntryTitleMarkers are attributes of the element of an entry title in the entry list
+	amebloEntryTitleMarkers = []html.Attribute{
+		{Key: "data-uranus-component", Val: "entryItemTitle"},
+		{Key: "class", Val: "contentTitle"},
+	}
+
+	// amebloNextPageMarkers are attributes of the link to the next page of the entry list
+	amebloNextPageMarkers = []html.Attribute{
+		{Key: "data-uranus-component", Val: "paginationNext"},
+		{Key: "class", Val: "skin-paginationNext"},
+	}
+
+	// amebloTimeLayouts are layouts of the published time in the entry list, parsed in JST if no zone
+	amebloTimeLayouts = []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006年01月02日 15時04分05秒",
+		"2006-01-02",
+	}
+)
+
+type (
+	// amebloEntryList represents a page of the entry list of the blog on Ameblo
+	amebloEntryList struct {
+		Title   string
+		Items   []FeedItem
+		NextURL string // empty if the last page
+	}
+)
+
+// amebloBlogURL returns the top url of the blog of given feed (e.g. https://ameblo.jp/momota-sd)
+func amebloBlogURL(feed Feed) (string, error) {
+	urlStr := feed.URL
+	if urlStr == "" {
+		urlStr = feed.URLPattern
+	}
+	urlStr = strings.TrimSuffix(urlStr, "/")
+	if urlStr == "" {
+		return "", errors.Errorf("code:%s has no blog url", feed.Code)
+	}
+	return urlStr, nil
+}
+
+// amebloEntryListURL returns the url of the entry list given page number that starts from 1
+func amebloEntryListURL(blogURL string, page int) string {
+	if page <= 1 {
+		return blogURL + "/entrylist.html"
+	}
+	return fmt.Sprintf("%s/entrylist-%d.html", blogURL, page)
+}
+
+// fetchAmeblo fetches the latest entries of the blog on Ameblo given the feed
+// images and videos are taken from each entry page, as well as the body if withBody is true
+func fetchAmeblo(httpClient *http.Client, feed Feed, maxItemNum int, latestURL string, withBody bool) ([]FeedItem, error) {
+	blogURL, err := amebloBlogURL(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := fetchAmebloEntryList(httpClient, feed, amebloEntryListURL(blogURL, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	// the latest url is compared in canonical form as well
+	items := limitItems(list.Items, maxItemNum, latestURL)
+	if err := fillAmebloEntries(httpClient, items, withBody); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// fetchAmebloEntryList fetches a page of the entry list given url
+func fetchAmebloEntryList(httpClient *http.Client, feed Feed, urlStr string) (*amebloEntryList, error) {
+	doc, err := getHTML(httpClient, urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	list := parseAmebloEntryList(doc, urlStr)
+	if len(list.Items) == 0 {
+		return nil, errors.Errorf("entries not found url:%v", urlStr)
+	}
+
+	title := list.Title
+	if feed.Title != "" {
+		title = feed.Title
+	}
+	blogURL, _ := amebloBlogURL(feed)
+	for i := range list.Items {
+		list.Items[i].Title = title
+		list.Items[i].URL = blogURL
+	}
+	return list, nil
+}
+
+// fillAmebloEntries fills images, videos and the body of items from each entry page
+// it fails if any entry page failed to fetch, so that the entries are crawled again with their images
+func fillAmebloEntries(httpClient *http.Client, items []FeedItem, withBody bool) error {
+	for i := range items {
+		doc, err := getHTML(httpClient, items[i].EntryURL)
+		if err != nil {
+			return errors.Wrapf(err, "entry url:%v", items[i].EntryURL)
+		}
+
+		body := findAmebloBody(doc)
+		if body == nil {
+			continue
+		}
+		items[i].ImageURLs, items[i].VideoURLs = amebloMedia(body, items[i].EntryURL)
+		if withBody {
+			items[i].Body, _ = amebloBodyText(doc)
+		}
+	}
+	return nil
+}
+
+// parseAmebloEntryList parses the entry list, the urls in it are resolved against given url
+func parseAmebloEntryList(doc *html.Node, urlStr string) *amebloEntryList {
+	list := &amebloEntryList{Title: amebloBlogTitle(doc)}
+
+	for _, n := range findNodes(doc, matchAny(amebloEntryTitleMarkers)) {
+		a := n
+		if a.DataAtom != atom.A {
+			a = findNode(n, func(n *html.Node) bool { return n.DataAtom == atom.A && attr(n, "href") != "" })
+		}
+		if a == nil {
+			continue
+		}
+
+		item := FeedItem{
+			EntryTitle: renderText(a, nil),
+			EntryURL:   resolveURL(urlStr, attr(a, "href")),
+		}
+		if container := findAncestor(n, matchAny(amebloEntryItemMarkers)); container != nil {
+			if t := findNode(container, func(n *html.Node) bool { return n.DataAtom == atom.Time }); t != nil {
+				item.PublishedAt = parseAmebloTime(attr(t, "datetime"), renderText(t, nil))
+			}
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	if n := findNode(doc, matchAny(amebloNextPageMarkers)); n != nil {
+		a := n
+		if a.DataAtom != atom.A {
+			a = findNode(n, func(n *html.Node) bool { return n.DataAtom == atom.A })
+		}
+		if a != nil && attr(a, "href") != "" {
+			list.NextURL = resolveURL(urlStr, attr(a, "href"))
+		}
+	}
+	return list
+}
+
+// amebloBlogTitle returns the title of the blog
+func amebloBlogTitle(doc *html.Node) string {
+	if n := findNode(doc, func(n *html.Node) bool {
+		return n.DataAtom == atom.Meta && attr(n, "property") == "og:site_name"
+	}); n != nil && attr(n, "content") != "" {
+		return strings.TrimSpace(attr(n, "content"))
+	}
+	if n := findNode(doc, func(n *html.Node) bool { return n.DataAtom == atom.Title }); n != nil && n.FirstChild != nil {
+		return strings.TrimSpace(n.FirstChild.Data)
+	}
+	return ""
+}
+
+// amebloMedia returns the urls of the images uploaded by the author and the embedded videos in the entry body
+func amebloMedia(body *html.Node, entryURL string) (images []string, videos []string) {
+	for _, n := range findNodes(body, func(n *html.Node) bool { return n.DataAtom == atom.Img || n.DataAtom == atom.Iframe }) {
+		src := attr(n, "src")
+		switch {
+		case n.DataAtom == atom.Img && strings.Contains(src, "/user_images/"):
+			images = append(images, resolveURL(entryURL, src))
+		case n.DataAtom == atom.Iframe && strings.Contains(src, "youtube.com/embed/"):
+			videos = append(videos, resolveURL(entryURL, src))
+		}
+	}
+	return images, videos
+}
+
+// parseAmebloTime parses the published time given the datetime attribute and the text of the time element
+func parseAmebloTime(values ...string) time.Time {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		for _, layout := range amebloTimeLayouts {
+			if t, err := time.ParseInLocation(layout, v, timeutil.JST()); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// getHTML gets the page and parses it
+func getHTML(httpClient *http.Client, urlStr string) (*html.Node, error) {
+	resp, err := httpClient.Get(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code:%v url:%v", resp.StatusCode, urlStr)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseHTML(b, resp.Header.Get("Content-Type"))
+}
+
+func matchAny(markers []html.Attribute) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		for _, marker := range markers {
+			if hasAttr(n, marker.Key, marker.Val) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func findNodes(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var nodes []*html.Node
+	if n.Type == html.ElementNode && match(n) {
+		nodes = append(nodes, n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		nodes = append(nodes, findNodes(c, match)...)
+	}
+	return nodes
+}
+
+func findAncestor(n *html.Node, match func(*html.Node) bool) *html.Node {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && match(p) {
+			return p
+		}
+	}
+	return nil
+}
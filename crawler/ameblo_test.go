@@ -0,0 +1,185 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/timeutil"
+)
+
+// newAmebloServer returns the server of the blog of ariyasu-sd that has given entry lists
+func newAmebloServer(t *testing.T, entryLists map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name, ok := entryLists[r.URL.Path]; ok {
+			b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(b)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/ariyasu-sd/entry-") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprintf(w, `<html><body><div data-uranus-component="entryBody">本文%s<img src="/user_images/1.jpg"><img src="/emoji/1.gif"><iframe src="https://www.youtube.com/embed/abc"></iframe></div></body></html>`, r.URL.Path)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+}
+
+// loadAmebloConfig loads config that has only the blog of ariyasu-sd given url
+func loadAmebloConfig(t *testing.T, blogURL string) {
+	tmpfile, err := ioutil.TempFile("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	_, err = fmt.Fprintf(tmpfile, `
+[Crawler]
+  ExtractBody = true
+
+[[Feeds]]
+  Code = "ariyasu-sd"
+  Source = "ameblo"
+  URLPattern = "%s"
+  Enabled = true
+`, blogURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Load(tmpfile.Name()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFetch_AmebloConfigOnly(t *testing.T) {
+	ts := newAmebloServer(t, map[string]string{"/ariyasu-sd/entrylist.html": "ameblo_entrylist.html"})
+	defer ts.Close()
+	loadAmebloConfig(t, ts.URL+"/ariyasu-sd/")
+	defer config.Load(os.DevNull)
+
+	c := NewWithTransport(nil, nil, func(context.Context, FeedCode) http.RoundTripper {
+		return http.DefaultTransport
+	})
+	items, err := c.Fetch(context.Background(), "ariyasu-sd", 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected items length 2, got %v", len(items))
+	}
+
+	item := items[0]
+	if item.Title != "有安杏果オフィシャルブログ" || item.URL != ts.URL+"/ariyasu-sd" {
+		t.Errorf("Expected the blog, got title:%v url:%v", item.Title, item.URL)
+	}
+	if item.EntryTitle != "エントリー3" || item.EntryURL != ts.URL+"/ariyasu-sd/entry-3.html" {
+		t.Errorf("Expected entry 3, got title:%v url:%v", item.EntryTitle, item.EntryURL)
+	}
+	if expected := time.Date(2018, 1, 3, 21, 0, 0, 0, timeutil.JST()); !item.PublishedAt.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, item.PublishedAt)
+	}
+	if expected := []string{ts.URL + "/user_images/1.jpg"}; !reflect.DeepEqual(item.ImageURLs, expected) {
+		t.Errorf("Expected %v, got %v", expected, item.ImageURLs)
+	}
+	if expected := []string{"https://www.youtube.com/embed/abc"}; !reflect.DeepEqual(item.VideoURLs, expected) {
+		t.Errorf("Expected %v, got %v", expected, item.VideoURLs)
+	}
+	if expected := "本文/ariyasu-sd/entry-3.html"; item.Body != expected {
+		t.Errorf("Expected %q, got %q", expected, item.Body)
+	}
+	if expected := time.Date(2018, 1, 2, 20, 0, 0, 0, timeutil.JST()); !items[1].PublishedAt.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, items[1].PublishedAt)
+	}
+
+	// up to the latest entry
+	items, err = c.Fetch(context.Background(), "ariyasu-sd", 10, ts.URL+"/ariyasu-sd/entry-2.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].EntryTitle != "エントリー3" {
+		t.Errorf("Expected only entry 3, got %v", items)
+	}
+}
+
+func TestFetch_AmebloEntryError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ariyasu-sd/entrylist.html" {
+			http.Error(w, "error", http.StatusInternalServerError)
+			return
+		}
+		b, err := ioutil.ReadFile(filepath.Join("testdata", "ameblo_entrylist.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(b)
+	}))
+	defer ts.Close()
+	loadAmebloConfig(t, ts.URL+"/ariyasu-sd/")
+	defer config.Load(os.DevNull)
+
+	// the entry is crawled again instead of being notified without its images
+	c := NewWithTransport(nil, nil, func(context.Context, FeedCode) http.RoundTripper {
+		return http.DefaultTransport
+	})
+	if _, err := c.Fetch(context.Background(), "ariyasu-sd", 1, ""); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestFetchArchive_Ameblo(t *testing.T) {
+	ts := newAmebloServer(t, map[string]string{
+		"/ariyasu-sd/entrylist.html":   "ameblo_entrylist.html",
+		"/ariyasu-sd/entrylist-2.html": "ameblo_entrylist-2.html",
+	})
+	defer ts.Close()
+	loadAmebloConfig(t, ts.URL+"/ariyasu-sd")
+	defer config.Load(os.DevNull)
+
+	c := NewArchiveFetcherWithTransport(func(context.Context, FeedCode) http.RoundTripper {
+		return http.DefaultTransport
+	})
+
+	// the first page
+	page, err := c.FetchArchive(context.Background(), "ariyasu-sd", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 3 {
+		t.Fatalf("Expected items length 3, got %v", len(page.Items))
+	}
+	if expected := []string{ts.URL + "/user_images/1.jpg"}; !reflect.DeepEqual(page.Items[2].ImageURLs, expected) {
+		t.Errorf("Expected %v, got %v", expected, page.Items[2].ImageURLs)
+	}
+	if page.Items[2].Body != "" {
+		t.Errorf("Expected no body, got %q", page.Items[2].Body)
+	}
+	if expected := ts.URL + "/ariyasu-sd/entrylist-2.html"; page.NextCursor != expected {
+		t.Fatalf("Expected next cursor %v, got %v", expected, page.NextCursor)
+	}
+
+	// resumes from the cursor until the last page
+	page, err = c.FetchArchive(context.Background(), "ariyasu-sd", page.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 || page.Items[0].EntryURL != ts.URL+"/ariyasu-sd/entry-0.html" {
+		t.Errorf("Expected entry 0, got %v", page.Items)
+	}
+	if page.NextCursor != "" {
+		t.Errorf("Expected no next cursor, got %v", page.NextCursor)
+	}
+}
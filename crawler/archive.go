@@ -0,0 +1,117 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"google.golang.org/appengine/urlfetch"
+)
+
+// maxArchiveItemNum is the max number of items in the archive of the feed that has no pages
+const maxArchiveItemNum = 50
+
+type (
+	// ArchiveFetcher interface
+	ArchiveFetcher interface {
+		FetchArchive(ctx context.Context, code FeedCode, cursor string) (*ArchivePage, error)
+	}
+
+	// ArchiveRequest represents a request of the archive page
+	ArchiveRequest struct {
+		Code   FeedCode `validate:"required"`
+		Cursor string
+		Page   int // number of pages that have been fetched
+	}
+
+	// ArchivePage represents a page of the feed archive
+	ArchivePage struct {
+		Items      []FeedItem
+		NextCursor string // empty if the last page
+	}
+)
+
+// NewArchiveFetcher returns ArchiveFetcher
+func NewArchiveFetcher() ArchiveFetcher {
+	return NewArchiveFetcherWithTransport(func(ctx context.Context, _ FeedCode) http.RoundTripper {
+		return urlfetch.Client(ctx).Transport
+	})
+}
+
+// NewArchiveFetcherWithTransport returns ArchiveFetcher that sends requests through the transport given by fn
+func NewArchiveFetcherWithTransport(fn func(context.Context, FeedCode) http.RoundTripper) ArchiveFetcher {
+	return &client{transport: fn, limiter: newHostLimiter()}
+}
+
+// FetchArchive fetches a page of the feed archive given cursor
+// the cursor is the url of the page of syndication and Ameblo source, the page of the others is only the latest
+func (c *client) FetchArchive(ctx context.Context, code FeedCode, cursor string) (*ArchivePage, error) {
+	const errTag = "crawler FetchArchive failed"
+
+	feed, ok := FindFeed(code)
+	if !ok {
+		return nil, errors.Errorf("%v: code:%s did not register", errTag, code)
+	}
+	httpClient := &http.Client{Transport: &limitedTransport{base: c.transport(ctx, code), limiter: c.limiter}}
+
+	if feed.Source == FeedSourceAmeblo {
+		page, err := fetchAmebloArchive(httpClient, feed, cursor)
+		if err != nil {
+			return nil, errors.Wrap(err, errTag)
+		}
+		return page, nil
+	}
+	if feed.Source != FeedSourceSyndication {
+		items, err := fetchChannel(httpClient, feed, maxArchiveItemNum, "")
+		if err != nil {
+			return nil, errors.Wrap(err, errTag)
+		}
+		return &ArchivePage{Items: items}, nil
+	}
+
+	if feed.URL == "" {
+		return nil, errors.Errorf("%v: code:%s has no feed url", errTag, code)
+	}
+	urlStr := cursor
+	if urlStr == "" {
+		urlStr = feed.URL
+	}
+
+	channel, err := fetchSyndicationChannel(httpClient, feed, urlStr)
+	if err != nil {
+		return nil, errors.Wrap(err, errTag)
+	}
+
+	page := &ArchivePage{Items: channel.Items}
+	if channel.NextURL != urlStr {
+		page.NextCursor = channel.NextURL
+	}
+	return page, nil
+}
+
+// fetchAmebloArchive fetches a page of the entry list given cursor
+// the cursor is the url of the entry list page, the first page is fetched if empty
+func fetchAmebloArchive(httpClient *http.Client, feed Feed, cursor string) (*ArchivePage, error) {
+	urlStr := cursor
+	if urlStr == "" {
+		blogURL, err := amebloBlogURL(feed)
+		if err != nil {
+			return nil, err
+		}
+		urlStr = amebloEntryListURL(blogURL, 1)
+	}
+
+	list, err := fetchAmebloEntryList(httpClient, feed, urlStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := fillAmebloEntries(httpClient, list.Items, false); err != nil {
+		return nil, err
+	}
+
+	page := &ArchivePage{Items: list.Items}
+	if list.NextURL != urlStr {
+		page.NextCursor = list.NextURL
+	}
+	return page, nil
+}
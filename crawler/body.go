@@ -0,0 +1,228 @@
+package crawler
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// maxBodyLength is the max character count of FeedItem.Body
+const maxBodyLength = 2000
+
+var (
+	// amebloBodyMarkers are attributes of the element that contains the entry body on Ameblo
+	amebloBodyMarkers = []html.Attribute{
+		{Key: "data-uranus-component", Val: "entryBody"},
+		{Key: "id", Val: "entryBody"},
+		{Key: "class", Val: "articleText"},
+	}
+
+	// amebloBoilerplateClasses are prefixes of the class of the elements in the entry body that are not written by the author
+	amebloBoilerplateClasses = []string{"reblog", "hashtag", "snsButton", "ad_", "adsense", "skin-entryFooter", "amemberEntry"}
+
+	// amebloBoilerplateLines are lines that are appended to the entry body on Ameblo
+	amebloBoilerplateLines = map[string]bool{
+		"いいね！":        true,
+		"コメント":        true,
+		"リブログ":        true,
+		"シェア":         true,
+		"ツイート":        true,
+		"このブログの読者になる": true,
+	}
+
+	// skipElements are elements that have no text to read
+	skipElements = map[atom.Atom]bool{
+		atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+		atom.Button: true, atom.Form: true, atom.Head: true, atom.Svg: true,
+	}
+
+	// blockElements are elements that break lines
+	blockElements = map[atom.Atom]bool{
+		atom.P: true, atom.Div: true, atom.Li: true, atom.Tr: true, atom.Blockquote: true,
+		atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+		atom.Section: true, atom.Article: true, atom.Ul: true, atom.Ol: true, atom.Table: true,
+	}
+)
+
+// ExtractText returns readable text of given HTML
+func ExtractText(s string) string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return ""
+	}
+	return renderText(doc, nil)
+}
+
+// extractAmebloBody returns the entry body text of given Ameblo entry page
+func extractAmebloBody(b []byte, contentType string) (string, error) {
+	doc, err := parseHTML(b, contentType)
+	if err != nil {
+		return "", err
+	}
+	return amebloBodyText(doc)
+}
+
+// amebloBodyText returns the entry body text of given parsed Ameblo entry page
+func amebloBodyText(doc *html.Node) (string, error) {
+	body := findAmebloBody(doc)
+	if body == nil {
+		return "", errors.New("entry body not found")
+	}
+
+	text := renderText(body, func(n *html.Node) bool {
+		for _, v := range strings.Fields(attr(n, "class")) {
+			for _, prefix := range amebloBoilerplateClasses {
+				if strings.HasPrefix(v, prefix) {
+					return true
+				}
+			}
+		}
+		return false
+	})
+
+	// trim the trailing boilerplate lines
+	lines := strings.Split(text, "\n")
+	for len(lines) > 0 {
+		last := strings.TrimSpace(lines[len(lines)-1])
+		if last != "" && !amebloBoilerplateLines[last] {
+			break
+		}
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// findAmebloBody returns the element that contains the entry body, nil if not found
+func findAmebloBody(doc *html.Node) *html.Node {
+	return findNode(doc, matchAny(amebloBodyMarkers))
+}
+
+// parseHTML parses given HTML in the charset of contentType
+func parseHTML(b []byte, contentType string) (*html.Node, error) {
+	r, err := charset.NewReader(bytes.NewReader(b), contentType)
+	if err != nil {
+		return nil, err
+	}
+	return html.Parse(r)
+}
+
+// renderText renders text of given node and its descendants
+// the elements that skip returns true are ignored
+func renderText(n *html.Node, skip func(*html.Node) bool) string {
+	var buf bytes.Buffer
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			buf.WriteString(collapseSpace(n.Data))
+			return
+		case html.ElementNode:
+			if skipElements[n.DataAtom] || (skip != nil && skip(n)) {
+				return
+			}
+			if n.DataAtom == atom.Br {
+				buf.WriteString("\n")
+				return
+			}
+		}
+
+		block := n.Type == html.ElementNode && blockElements[n.DataAtom]
+		if block {
+			buf.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			buf.WriteString("\n")
+		}
+	}
+	walk(n)
+	return normalizeText(buf.String())
+}
+
+// normalizeText trims each line, squeezes blank lines and truncates the text to maxBodyLength
+func normalizeText(s string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return truncateRunes(strings.Join(lines, "\n"), maxBodyLength)
+}
+
+// collapseSpace replaces each run of white spaces with a single space
+func collapseSpace(s string) string {
+	var buf bytes.Buffer
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			buf.WriteRune(' ')
+			space = false
+		}
+		buf.WriteRune(r)
+	}
+	if space {
+		buf.WriteRune(' ')
+	}
+	return buf.String()
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+func findNode(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && match(n) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findNode(c, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key, val string) bool {
+	if key == "class" {
+		for _, v := range strings.Fields(attr(n, key)) {
+			if v == val {
+				return true
+			}
+		}
+		return false
+	}
+	return attr(n, key) == val
+}
@@ -0,0 +1,73 @@
+package crawler
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractText(t *testing.T) {
+	tests := []struct {
+		html     string
+		expected string
+	}{
+		{"", ""},
+		{"plain text", "plain text"},
+		{"<p>a\n  b</p><p>c</p>", "a b\n\nc"},
+		{"a<br>b<br/><br/>c", "a\nb\n\nc"},
+		{"<div>a<script>b</script><style>c</style></div>d", "a\nd"},
+		{"&lt;tag&gt; &amp;", "<tag> &"},
+	}
+
+	for _, test := range tests {
+		if text := ExtractText(test.html); text != test.expected {
+			t.Errorf("Expected %q, got %q. html:%q", test.expected, text, test.html)
+		}
+	}
+
+	if text := ExtractText(strings.Repeat("あ", maxBodyLength+1)); len([]rune(text)) != maxBodyLength {
+		t.Errorf("Expected length %v, got %v", maxBodyLength, len([]rune(text)))
+	}
+}
+
+func TestExtractAmebloBody(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "ameblo_entry.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := extractAmebloBody(b, "text/html; charset=utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "こんにちは。\n今日は晴れです！\n\n写真を撮りました"; body != expected {
+		t.Errorf("Expected %q, got %q", expected, body)
+	}
+
+	if _, err := extractAmebloBody([]byte("<html><body>no entry</body></html>"), ""); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestFeedItem_Summary(t *testing.T) {
+	tests := []struct {
+		body     string
+		n        int
+		expected string
+	}{
+		{"", 10, ""},
+		{"short", 0, ""},
+		{"short", 10, "short"},
+		{"今日は晴れです。明日は雨です。", 12, "今日は晴れです。"},
+		{"今日は晴れです！\n明日は雨です。", 10, "今日は晴れです！"},
+		{"あいうえおかきくけこさしすせそ。", 10, "あいうえおかきくけ…"},
+		{"あ。いうえおかきくけこさしすせそ", 10, "あ。いうえおかきく…"},
+	}
+
+	for _, test := range tests {
+		if summary := (FeedItem{Body: test.body}).Summary(test.n); summary != test.expected {
+			t.Errorf("Expected %q, got %q. body:%q n:%v", test.expected, summary, test.body, test.n)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// FetchCache represents the validators of the last response of a feed
+	FetchCache struct {
+		Code         FeedCode
+		URL          string
+		ETag         string
+		LastModified string
+		LatestURL    string // the latest entry url at the time
+	}
+
+	// FetchCacheRepository interface
+	FetchCacheRepository interface {
+		Find(context.Context, FeedCode) (*FetchCache, error) // nil if not found
+		Save(context.Context, *FetchCache) error
+	}
+
+	// conditionalTransport sends a conditional request for the first request of a fetch
+	// it is the feed document, the following requests (e.g. entry pages) are sent as is
+	conditionalTransport struct {
+		base  http.RoundTripper
+		cache *FetchCache // nil if the conditional request is not allowed
+
+		mu           sync.Mutex
+		requested    bool
+		notModified  bool
+		url          string
+		etag         string
+		lastModified string
+	}
+)
+
+var (
+	// ErrNotModified is returned by Fetch when the feed document is not modified since the last fetch
+	ErrNotModified = errors.New("mcz: feed not modified")
+
+	// errNotModified aborts the fetch when the feed document is not modified
+	errNotModified = errors.New("not modified")
+)
+
+// newConditionalTransport returns conditionalTransport
+// the cache is used only if it has been stored with the same latest entry url,
+// so that a crawl that failed after fetching is retried without condition
+// a fetch without the latest url (e.g. the re-crawl of edit detection) is always sent without condition
+func newConditionalTransport(base http.RoundTripper, cache *FetchCache, latestURL string) *conditionalTransport {
+	t := &conditionalTransport{base: base}
+	if cache != nil && latestURL != "" && cache.LatestURL == latestURL {
+		t.cache = cache
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	first := !t.requested
+	t.requested = true
+	t.mu.Unlock()
+	if !first {
+		return t.base.RoundTrip(req)
+	}
+
+	urlStr := req.URL.String()
+	if t.cache != nil && t.cache.URL == urlStr {
+		r := new(http.Request)
+		*r = *req
+		r.Header = make(http.Header, len(req.Header)+2)
+		for k, v := range req.Header {
+			r.Header[k] = v
+		}
+		if t.cache.ETag != "" {
+			r.Header.Set("If-None-Match", t.cache.ETag)
+		}
+		if t.cache.LastModified != "" {
+			r.Header.Set("If-Modified-Since", t.cache.LastModified)
+		}
+		req = r
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		resp.Body.Close()
+		t.notModified = true
+		return nil, errNotModified
+	case http.StatusOK:
+		t.url = urlStr
+		t.etag = resp.Header.Get("ETag")
+		t.lastModified = resp.Header.Get("Last-Modified")
+	}
+	return resp, nil
+}
+
+// NotModified returns true if the feed document was not modified
+func (t *conditionalTransport) NotModified() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.notModified
+}
+
+// Cache returns FetchCache of the fetched feed document
+// it returns nil if the response has no validators
+func (t *conditionalTransport) Cache(code FeedCode, latestURL string) *FetchCache {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.url == "" || (t.etag == "" && t.lastModified == "") {
+		return nil
+	}
+	return &FetchCache{
+		Code:         code,
+		URL:          t.url,
+		ETag:         t.etag,
+		LastModified: t.lastModified,
+		LatestURL:    latestURL,
+	}
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConditionalTransport(t *testing.T) {
+	const etag = `"v1"`
+	var conditional []bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match") != "")
+		if r.URL.Path == "/feed" && r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	get := func(transport *conditionalTransport, path string) error {
+		resp, err := (&http.Client{Transport: transport}).Get(ts.URL + path)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	// first fetch has no cache
+	transport := newConditionalTransport(http.DefaultTransport, nil, "")
+	if err := get(transport, "/feed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport, "/entry"); err != nil {
+		t.Fatal(err)
+	}
+	cache := transport.Cache(FeedCodeAeNews, "http://localhost/latest")
+	if cache == nil {
+		t.Fatal("Expected got cache, but nil")
+	}
+	if cache.URL != ts.URL+"/feed" || cache.ETag != etag {
+		t.Errorf("Expected cache of the feed document, got %v", cache)
+	}
+
+	// not modified
+	transport = newConditionalTransport(http.DefaultTransport, cache, "http://localhost/latest")
+	if err := get(transport, "/feed"); err == nil {
+		t.Error("Expected got error, but nil")
+	}
+	if !transport.NotModified() {
+		t.Error("Expected not modified, got modified")
+	}
+
+	// the latest entry url differs from the cache, e.g. the previous crawl failed after fetching
+	transport = newConditionalTransport(http.DefaultTransport, cache, "http://localhost/old")
+	if err := get(transport, "/feed"); err != nil {
+		t.Fatal(err)
+	}
+	if transport.NotModified() {
+		t.Error("Expected modified, got not modified")
+	}
+
+	// the fetch without the latest entry url, e.g. the re-crawl of edit detection
+	transport = newConditionalTransport(http.DefaultTransport, cache, "")
+	if err := get(transport, "/feed"); err != nil {
+		t.Fatal(err)
+	}
+	if transport.NotModified() {
+		t.Error("Expected modified, got not modified")
+	}
+
+	expected := []bool{false, false, true, false, false}
+	if len(conditional) != len(expected) {
+		t.Fatalf("Expected %v requests, got %v", len(expected), len(conditional))
+	}
+	for i := range expected {
+		if conditional[i] != expected[i] {
+			t.Errorf("Expected conditional %v at %v, got %v", expected[i], i, conditional[i])
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package crawler
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+type (
+	// CanonicalRule represents the rule to canonicalize entry urls of a feed
+	CanonicalRule struct {
+		Scheme            string   // scheme to be forced (e.g. "https")
+		Host              string   // host that HostAliases are rewritten to
+		HostAliases       []string // hosts that serve the same entries (e.g. "s.ameblo.jp")
+		StripParams       []string // query parameters to be removed, a trailing "*" matches any suffix (e.g. "frm*")
+		TrimTrailingSlash bool
+	}
+)
+
+// globalStripParams are tracking parameters that are removed from any url
+var globalStripParams = []string{"utm_*"}
+
+// amebloRule is the canonical rule of the entry urls on Ameblo
+var amebloRule = CanonicalRule{
+	Scheme:      "https",
+	Host:        "ameblo.jp",
+	HostAliases: []string{"s.ameblo.jp", "www.ameblo.jp"},
+	StripParams: []string{"frm*"},
+}
+
+// CanonicalURL returns the canonical form of given entry url
+// the rule of the feed that the canonicalized url belongs to is applied
+func CanonicalURL(urlStr string) string {
+	u, ok := parseNormalizedURL(urlStr)
+	if !ok {
+		return urlStr
+	}
+
+	if rule, ok := findCanonicalRule(*u); ok {
+		return rule.apply(*u)
+	}
+	return u.String()
+}
+
+// LegacyURLs returns the variants of given entry url that may have been stored before canonicalization
+// they are the combinations of http / https and the hosts of the rule, the canonical url itself is not included
+func LegacyURLs(urlStr string) []string {
+	u, ok := parseNormalizedURL(urlStr)
+	if !ok {
+		return nil
+	}
+	rule, _ := findCanonicalRule(*u)
+	canonical := rule.apply(*u)
+	c, err := url.Parse(canonical)
+	if err != nil {
+		return nil
+	}
+
+	hosts := []string{c.Host}
+	if rule.Host != "" && c.Host == rule.Host {
+		hosts = append(hosts, rule.HostAliases...)
+	}
+	paths := []string{c.Path}
+	if rule.TrimTrailingSlash && c.Path != "/" && c.Path != "" {
+		paths = append(paths, c.Path+"/")
+	}
+
+	var urls []string
+	seen := map[string]bool{canonical: true}
+	for _, scheme := range []string{"http", "https"} {
+		for _, host := range hosts {
+			for _, p := range paths {
+				v := *c
+				v.Scheme, v.Host, v.Path, v.RawPath = scheme, host, p, ""
+				if s := v.String(); !seen[s] {
+					seen[s] = true
+					urls = append(urls, s)
+				}
+			}
+		}
+	}
+	return urls
+}
+
+// findCanonicalRule finds the rule of the feed that given url belongs to
+func findCanonicalRule(u url.URL) (CanonicalRule, bool) {
+	for _, f := range Feeds() {
+		if s := f.Canonical.apply(u); f.URLPattern != "" && strings.HasPrefix(s, f.URLPattern) {
+			return f.Canonical, true
+		}
+	}
+	return CanonicalRule{}, false
+}
+
+// parseNormalizedURL parses url and normalizes its case, default port, fragment and global tracking parameters
+func parseNormalizedURL(urlStr string) (*url.URL, bool) {
+	u, err := url.Parse(strings.TrimSpace(urlStr))
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && strings.HasSuffix(u.Host, ":80")) || (u.Scheme == "https" && strings.HasSuffix(u.Host, ":443")) {
+		u.Host = u.Host[:strings.LastIndex(u.Host, ":")]
+	}
+	u.Fragment = ""
+	stripParams(u, globalStripParams)
+	return u, true
+}
+
+// apply applies the rule to given url and returns it as string
+func (r CanonicalRule) apply(u url.URL) string {
+	for _, alias := range r.HostAliases {
+		if u.Host == alias {
+			u.Host = r.Host
+		}
+	}
+	if r.Scheme != "" && (r.Host == "" || u.Host == r.Host) {
+		u.Scheme = r.Scheme
+	}
+	stripParams(&u, r.StripParams)
+	if r.TrimTrailingSlash && u.Path != "/" && strings.HasSuffix(u.Path, "/") {
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = ""
+	}
+	return u.String()
+}
+
+// stripParams removes query parameters that match any of given patterns
+// the query is kept as is if no parameters are removed, the order of the others is kept as well
+func stripParams(u *url.URL, patterns []string) {
+	if u.RawQuery == "" || len(patterns) == 0 {
+		return
+	}
+
+	var (
+		params  []string
+		removed bool
+	)
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		if matchParam(param, patterns) {
+			removed = true
+			continue
+		}
+		params = append(params, param)
+	}
+	if removed {
+		u.RawQuery = strings.Join(params, "&")
+	}
+}
+
+// matchParam returns true if the key of given raw parameter (e.g. "key=value") matches any of patterns
+func matchParam(param string, patterns []string) bool {
+	key := param
+	if i := strings.Index(key, "="); i >= 0 {
+		key = key[:i]
+	}
+	key, err := url.QueryUnescape(key)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://ameblo.jp/momota-sd/entry-1.html", "https://ameblo.jp/momota-sd/entry-1.html"},
+		{"http://s.ameblo.jp/momota-sd/entry-1.html?frm=theme", "https://ameblo.jp/momota-sd/entry-1.html"},
+		{"https://ameblo.jp/tamai-sd/entry-1.html?frm_src=a&utm_source=b#top", "https://ameblo.jp/tamai-sd/entry-1.html"},
+		{"HTTP://WWW.MOMOCLO.NET:80/pc/news/1?utm_campaign=a", "http://www.momoclo.net/pc/news/1"},
+		{"http://www.tfm.co.jp/clover/index.php?itemid=1", "http://www.tfm.co.jp/clover/index.php?itemid=1"},
+		{"https://www.youtube.com/watch?v=1&utm_medium=b", "https://www.youtube.com/watch?v=1"},
+		{"http://localhost/entry", "http://localhost/entry"},
+		{"entry", "entry"},
+	}
+
+	for _, test := range tests {
+		if u := CanonicalURL(test.url); u != test.expected {
+			t.Errorf("Expected %v, got %v. url:%v", test.expected, u, test.url)
+		}
+	}
+}
+
+func TestCanonicalRule_Apply(t *testing.T) {
+	rule := CanonicalRule{
+		Host:              "example.com",
+		HostAliases:       []string{"m.example.com"},
+		StripParams:       []string{"ref", "sid*"},
+		TrimTrailingSlash: true,
+	}
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"http://m.example.com/entry/1/?ref=top&sid_a=1&page=2", "http://example.com/entry/1?page=2"},
+		{"https://example.com/", "https://example.com/"},
+		{"http://other.example.com/entry/", "http://other.example.com/entry"},
+		{"http://example.com/search?q=a%20b&page=2&lang=ja", "http://example.com/search?q=a%20b&page=2&lang=ja"}, // not re-encoded nor sorted
+		{"http://example.com/search?page=2&ref=top&lang=ja", "http://example.com/search?page=2&lang=ja"},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := rule.apply(*u); s != test.expected {
+			t.Errorf("Expected %v, got %v. url:%v", test.expected, s, test.url)
+		}
+	}
+}
+
+func TestFeedItem_UniqueURL(t *testing.T) {
+	item := FeedItem{EntryURL: "http://s.ameblo.jp/momota-sd/entry-1.html?frm=theme"}
+	if item.UniqueURL() != "https://ameblo.jp/momota-sd/entry-1.html" {
+		t.Errorf("Expected canonical url, got %v", item.UniqueURL())
+	}
+	if item.LegacyUniqueURL() != item.EntryURL {
+		t.Errorf("Expected entry url as is, got %v", item.LegacyUniqueURL())
+	}
+	expected := []string{
+		"http://s.ameblo.jp/momota-sd/entry-1.html?frm=theme",
+		"http://ameblo.jp/momota-sd/entry-1.html",
+		"http://s.ameblo.jp/momota-sd/entry-1.html",
+		"http://www.ameblo.jp/momota-sd/entry-1.html",
+		"https://s.ameblo.jp/momota-sd/entry-1.html",
+		"https://www.ameblo.jp/momota-sd/entry-1.html",
+	}
+	if ids := item.LegacyUniqueURLs(); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+	if code := item.FeedCode(); code != FeedCodeMomota {
+		t.Errorf("Expected %v, got %v", FeedCodeMomota, code)
+	}
+}
@@ -2,8 +2,11 @@ package crawler
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/log"
 	"github.com/utahta/momoclo-crawler"
 	"google.golang.org/appengine/urlfetch"
 )
@@ -15,71 +18,143 @@ type (
 	}
 
 	client struct {
+		log       log.Logger
+		cache     FetchCacheRepository
+		transport func(context.Context, FeedCode) http.RoundTripper
+		limiter   *hostLimiter
 	}
 )
 
 // New returns FeedFetcher that wraps momoclo-crawler
-func New() FeedFetcher {
-	return &client{}
+// feeds of syndication and Ameblo source are fetched by the fetchers of this package
+// the feed document is requested conditionally with ETag / Last-Modified stored by given repository,
+// and Fetch returns ErrNotModified if it is not modified
+func New(log log.Logger, repo FetchCacheRepository) FeedFetcher {
+	return NewWithTransport(log, repo, func(ctx context.Context, _ FeedCode) http.RoundTripper {
+		return urlfetch.Client(ctx).Transport
+	})
+}
+
+// NewWithTransport returns FeedFetcher that sends requests through the transport given by fn
+// the conditional request is disabled if repo is nil
+func NewWithTransport(log log.Logger, repo FetchCacheRepository, fn func(context.Context, FeedCode) http.RoundTripper) FeedFetcher {
+	return &client{log: log, cache: repo, transport: fn, limiter: newHostLimiter()}
 }
 
 func (c *client) Fetch(ctx context.Context, code FeedCode, maxItemNum int, latestURL string) ([]FeedItem, error) {
 	const errTag = "crawler Fetch failed"
+
+	feed, ok := FindFeed(code)
+	if !ok {
+		return nil, errors.Errorf("%v: code:%s did not register", errTag, code)
+	}
+
+	// the cache is best-effort, a failure of it never fails the fetch
+	var cache *FetchCache
+	if c.cache != nil {
+		var err error
+		if cache, err = c.cache.Find(ctx, code); err != nil {
+			c.log.Warningf(ctx, "%v: find fetch cache code:%v err:%v", errTag, code, err)
+		}
+	}
+	base := &limitedTransport{base: c.transport(ctx, code), limiter: c.limiter}
+	transport := newConditionalTransport(base, cache, latestURL)
+	httpClient := &http.Client{Transport: transport}
+
+	var (
+		items []FeedItem
+		err   error
+	)
+	switch feed.Source {
+	case FeedSourceSyndication:
+		items, err = fetchSyndication(httpClient, feed, maxItemNum, latestURL)
+	case FeedSourceAmeblo:
+		items, err = fetchAmeblo(httpClient, feed, maxItemNum, latestURL, crawlerSettings().ExtractBody)
+	default:
+		items, err = fetchChannel(httpClient, feed, maxItemNum, latestURL)
+	}
+	if err != nil {
+		if transport.NotModified() {
+			return nil, ErrNotModified
+		}
+		return nil, errors.Wrap(err, errTag)
+	}
+
+	if len(items) > 0 {
+		latestURL = items[0].EntryURL
+	}
+	if cache := transport.Cache(code, latestURL); cache != nil && c.cache != nil {
+		if err := c.cache.Save(ctx, cache); err != nil {
+			c.log.Errorf(ctx, "%v: save fetch cache code:%v err:%v", errTag, code, err)
+		}
+	}
+	return items, nil
+}
+
+// crawlerSettings returns the crawler settings
+// the zero value is returned if config is not loaded
+func crawlerSettings() config.Crawler {
+	if config.C() == nil {
+		return config.Crawler{}
+	}
+	return config.C().Crawler
+}
+
+// fetchChannel fetches the feed using momoclo-crawler
+func fetchChannel(httpClient *http.Client, feed Feed, maxItemNum int, latestURL string) ([]FeedItem, error) {
 	var (
 		cli  *crawler.ChannelClient
 		err  error
-		opts = crawler.WithHTTPClient(urlfetch.Client(ctx))
+		opts = crawler.WithHTTPClient(httpClient)
 	)
 
-	switch code {
-	case FeedCodeTamai:
-		cli, err = crawler.NewTamaiBlogChannelClient(maxItemNum, latestURL, opts)
-	case FeedCodeMomota:
-		cli, err = crawler.NewMomotaBlogChannelClient(maxItemNum, latestURL, opts)
-	case FeedCodeSasaki:
-		cli, err = crawler.NewSasakiBlogChannelClient(maxItemNum, latestURL, opts)
-	case FeedCodeTakagi:
-		cli, err = crawler.NewTakagiBlogChannelClient(maxItemNum, latestURL, opts)
-	case FeedCodeHappyclo:
+	switch feed.Source {
+	case FeedSourceHappyclo:
 		cli, err = crawler.NewHappycloChannelClient(latestURL, opts)
-	case FeedCodeAeNews:
+	case FeedSourceAeNews:
 		cli, err = crawler.NewAeNewsChannelClient(opts)
-	case FeedCodeYoutube:
+	case FeedSourceYoutube:
 		cli, err = crawler.NewYoutubeChannelClient(opts)
 	default:
-		err = errors.Errorf("code:%s did not support", code)
+		err = errors.Errorf("source:%s did not support", feed.Source)
 	}
-
 	if err != nil {
-		return nil, errors.Wrap(err, errTag)
+		return nil, err
 	}
 
 	channel, err := cli.Fetch()
 	if err != nil {
-		return nil, errors.Wrap(err, errTag)
+		return nil, err
+	}
+
+	title := channel.Title
+	if feed.Title != "" {
+		title = feed.Title
 	}
 
 	var items = make([]FeedItem, len(channel.Items))
-	for i, feed := range channel.Items {
+	for i, entry := range channel.Items {
 		item := FeedItem{
-			Title:       channel.Title,
+			Title:       title,
 			URL:         channel.URL,
-			EntryTitle:  feed.Title,
-			EntryURL:    feed.URL,
-			PublishedAt: feed.PublishedAt,
+			EntryTitle:  entry.Title,
+			EntryURL:    entry.URL,
+			PublishedAt: entry.PublishedAt,
 		}
 
-		item.ImageURLs = make([]string, len(feed.Images))
-		for i, image := range feed.Images {
+		item.ImageURLs = make([]string, len(entry.Images))
+		for i, image := range entry.Images {
 			item.ImageURLs[i] = image.URL
 		}
 
-		item.VideoURLs = make([]string, len(feed.Videos))
-		for i, video := range feed.Videos {
+		item.VideoURLs = make([]string, len(entry.Videos))
+		for i, video := range entry.Videos {
 			item.VideoURLs[i] = video.URL
 		}
 
 		items[i] = item
 	}
-	return items, nil
+
+	// the latest url is compared in canonical form as well
+	return limitItems(items, maxItemNum, latestURL), nil
 }
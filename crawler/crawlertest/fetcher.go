@@ -0,0 +1,208 @@
+package crawlertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/log"
+)
+
+type (
+	// Fixture represents a recorded HTTP response
+	Fixture struct {
+		URL        string      `json:"url"`
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header,omitempty"`
+		Body       string      `json:"body"`
+	}
+
+	// replayTransport replays recorded responses of a feed
+	// the responses of the same url are replayed in order and the last one is repeated
+	replayTransport struct {
+		path string
+
+		mu       sync.Mutex
+		fixtures []Fixture
+		loaded   bool
+		replayed map[string]int
+	}
+
+	// recordTransport records responses of a feed
+	recordTransport struct {
+		base http.RoundTripper
+		path string
+
+		mu       sync.Mutex
+		fixtures []Fixture
+	}
+)
+
+// NewFeedFetcher returns FeedFetcher that replays fixtures in dir
+// fixtures are stored per feed code (e.g. dir/aenews.json)
+func NewFeedFetcher(dir string) crawler.FeedFetcher {
+	return crawler.NewWithTransport(nil, nil, replayTransports(dir))
+}
+
+// NewFeedFetcherWithCache returns FeedFetcher that replays fixtures in dir and requests them conditionally
+// the fixture that has ETag header is replayed as not modified if the request has the same ETag
+func NewFeedFetcherWithCache(dir string, log log.Logger, repo crawler.FetchCacheRepository) crawler.FeedFetcher {
+	return crawler.NewWithTransport(log, repo, replayTransports(dir))
+}
+
+// NewArchiveFetcher returns ArchiveFetcher that replays fixtures in dir
+func NewArchiveFetcher(dir string) crawler.ArchiveFetcher {
+	return crawler.NewArchiveFetcherWithTransport(replayTransports(dir))
+}
+
+// NewRecordingFeedFetcher returns FeedFetcher that fetches feeds through the network and records fixtures in dir
+// existing fixtures of the feed are overwritten
+func NewRecordingFeedFetcher(dir string) crawler.FeedFetcher {
+	return crawler.NewWithTransport(nil, nil, recordTransports(dir))
+}
+
+// NewRecordingArchiveFetcher returns ArchiveFetcher that fetches archives through the network and records fixtures in dir
+func NewRecordingArchiveFetcher(dir string) crawler.ArchiveFetcher {
+	return crawler.NewArchiveFetcherWithTransport(recordTransports(dir))
+}
+
+func replayTransports(dir string) func(context.Context, crawler.FeedCode) http.RoundTripper {
+	var (
+		mu         sync.Mutex
+		transports = map[crawler.FeedCode]*replayTransport{}
+	)
+	return func(_ context.Context, code crawler.FeedCode) http.RoundTripper {
+		mu.Lock()
+		defer mu.Unlock()
+		t, ok := transports[code]
+		if !ok {
+			t = &replayTransport{path: FixturePath(dir, code), replayed: map[string]int{}}
+			transports[code] = t
+		}
+		return t
+	}
+}
+
+func recordTransports(dir string) func(context.Context, crawler.FeedCode) http.RoundTripper {
+	var (
+		mu         sync.Mutex
+		transports = map[crawler.FeedCode]*recordTransport{}
+	)
+	return func(_ context.Context, code crawler.FeedCode) http.RoundTripper {
+		mu.Lock()
+		defer mu.Unlock()
+		t, ok := transports[code]
+		if !ok {
+			t = &recordTransport{base: http.DefaultTransport, path: FixturePath(dir, code)}
+			transports[code] = t
+		}
+		return t
+	}
+}
+
+// FixturePath returns the fixture file path of given feed code
+func FixturePath(dir string, code crawler.FeedCode) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.json", code))
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.loaded {
+		b, err := ioutil.ReadFile(t.path)
+		if err != nil {
+			return nil, errors.Wrap(err, "fixture not found")
+		}
+		if err := json.Unmarshal(b, &t.fixtures); err != nil {
+			return nil, errors.Wrapf(err, "invalid fixture path:%v", t.path)
+		}
+		t.loaded = true
+	}
+
+	urlStr := req.URL.String()
+	var matched []Fixture
+	for _, f := range t.fixtures {
+		if f.URL == urlStr {
+			matched = append(matched, f)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, errors.Errorf("fixture not found url:%v path:%v", urlStr, t.path)
+	}
+
+	i := t.replayed[urlStr]
+	if i >= len(matched) {
+		i = len(matched) - 1
+	}
+	t.replayed[urlStr]++
+
+	f := matched[i]
+	header := http.Header{}
+	for k, v := range f.Header {
+		header[k] = v
+	}
+	if etag := header.Get("ETag"); etag != "" && req.Header.Get("If-None-Match") == etag {
+		f = Fixture{URL: f.URL, StatusCode: http.StatusNotModified}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.StatusCode, http.StatusText(f.StatusCode)),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fixtures = append(t.fixtures, Fixture{
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(b),
+	})
+	if err := writeFixtures(t.path, t.fixtures); err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return resp, nil
+}
+
+func writeFixtures(path string, fixtures []Fixture) error {
+	b, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"fmt"
+
+	"github.com/utahta/momoclo-channel/linenotify"
+	"github.com/utahta/momoclo-channel/twitter"
+)
+
+type (
+	// EditedFeedItem represents an entry that is edited after notified
+	// ImageURLs and VideoURLs contain only added ones
+	EditedFeedItem struct {
+		FeedItem
+		Notice string
+	}
+)
+
+// DiffFeedItem returns the edit of cur since prev
+// it returns false if the edit is not worth announcing
+func DiffFeedItem(prev FeedItem, cur FeedItem) (EditedFeedItem, bool) {
+	edited := EditedFeedItem{FeedItem: cur}
+	edited.ImageURLs = addedURLs(prev.ImageURLs, cur.ImageURLs)
+	edited.VideoURLs = addedURLs(prev.VideoURLs, cur.VideoURLs)
+
+	c := crawlerSettings()
+	switch {
+	case len(edited.ImageURLs) > 0 && c.ImageAddedNotice != "":
+		edited.Notice = c.ImageAddedNotice
+	case len(edited.VideoURLs) > 0 && c.VideoAddedNotice != "":
+		edited.Notice = c.VideoAddedNotice
+	case prev.EntryTitle != cur.EntryTitle && c.TitleChangedNotice != "":
+		edited.Notice = c.TitleChangedNotice
+	default:
+		return edited, false
+	}
+	return edited, true
+}
+
+// ToLineNotifyMessages converts EditedFeedItem to []linenotify.Message
+func (i EditedFeedItem) ToLineNotifyMessages() []linenotify.Message {
+	messages := i.FeedItem.ToLineNotifyMessages()
+	messages[0].Text = fmt.Sprintf("\n%s\n%s\n%s\n%s", i.Notice, i.Title, i.EntryTitle, i.EntryURL)
+	return messages
+}
+
+// ToTweetRequests converts EditedFeedItem to []twitter.TweetRequest
+func (i EditedFeedItem) ToTweetRequests() []twitter.TweetRequest {
+	requests := i.FeedItem.ToTweetRequests()
+	item := i.FeedItem
+	item.Title = fmt.Sprintf("【%s】%s", i.Notice, i.Title)
+	requests[0].Text = item.toTweetText()
+	return requests
+}
+
+// addedURLs returns urls that are in cur but not in prev
+func addedURLs(prev []string, cur []string) []string {
+	exists := map[string]bool{}
+	for _, u := range prev {
+		exists[u] = true
+	}
+
+	var urls []string
+	for _, u := range cur {
+		if !exists[u] {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
@@ -0,0 +1,74 @@
+package crawler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/utahta/momoclo-channel/testutil"
+)
+
+func TestDiffFeedItem(t *testing.T) {
+	testutil.MustConfigLoad()
+
+	prev := FeedItem{
+		Title:      "title",
+		EntryTitle: "entry title",
+		EntryURL:   "http://localhost/entry",
+		ImageURLs:  []string{"http://localhost/img_1"},
+	}
+
+	tests := []struct {
+		cur              FeedItem
+		expected         bool
+		expectedNotice   string
+		expectedImageURL []string
+	}{
+		{prev, false, "", nil},
+		{FeedItem{EntryTitle: "entry title", ImageURLs: []string{"http://localhost/img_1", "http://localhost/img_2"}}, true, "写真が追加されました", []string{"http://localhost/img_2"}},
+		{FeedItem{EntryTitle: "entry title", ImageURLs: []string{"http://localhost/img_1"}, VideoURLs: []string{"http://localhost/mp4_1"}}, true, "動画が追加されました", nil},
+		{FeedItem{EntryTitle: "entry title"}, false, "", nil},   // removed images
+		{FeedItem{EntryTitle: "entry title z"}, false, "", nil}, // title changed notice is disabled
+	}
+
+	for _, test := range tests {
+		edited, ok := DiffFeedItem(prev, test.cur)
+		if ok != test.expected {
+			t.Errorf("Expected %v, got %v. cur:%v", test.expected, ok, test.cur)
+		}
+		if edited.Notice != test.expectedNotice {
+			t.Errorf("Expected notice %v, got %v", test.expectedNotice, edited.Notice)
+		}
+		if !reflect.DeepEqual(edited.ImageURLs, test.expectedImageURL) {
+			t.Errorf("Expected image urls %v, got %v", test.expectedImageURL, edited.ImageURLs)
+		}
+	}
+}
+
+func TestEditedFeedItem_ToLineNotifyMessages(t *testing.T) {
+	edited := EditedFeedItem{
+		FeedItem: FeedItem{
+			Title:      "title",
+			EntryTitle: "entry title",
+			EntryURL:   "http://localhost/entry",
+			ImageURLs:  []string{"http://localhost/img_2", "http://localhost/img_3"},
+		},
+		Notice: "写真が追加されました",
+	}
+
+	messages := edited.ToLineNotifyMessages()
+	if len(messages) != 2 {
+		t.Fatalf("Expected messages length 2, got %v", len(messages))
+	}
+	if !strings.HasPrefix(messages[0].Text, "\n写真が追加されました\ntitle") {
+		t.Errorf("Expected notice prefix, got %v", messages[0].Text)
+	}
+	if messages[0].ImageURL != "http://localhost/img_2" {
+		t.Errorf("Expected img_2, got %v", messages[0].ImageURL)
+	}
+
+	requests := edited.ToTweetRequests()
+	if !strings.HasPrefix(requests[0].Text, "【写真が追加されました】title") {
+		t.Errorf("Expected notice prefix, got %v", requests[0].Text)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/utahta/momoclo-channel/twitter"
 )
 
+// codes of the built-in feeds
 const (
 	FeedCodeMomota   FeedCode = "momota-sd"
 	FeedCodeTamai    FeedCode = "tamai-sd"
@@ -31,6 +32,7 @@ type (
 		EntryURL    string `validate:"required,url"`
 		ImageURLs   []string
 		VideoURLs   []string
+		Body        string    // text of the entry body, it may be empty
 		PublishedAt time.Time `validate:"required"`
 	}
 )
@@ -40,38 +42,66 @@ func (f FeedCode) String() string {
 	return string(f)
 }
 
-// UniqueURL builds unique url
+// UniqueURL builds unique url based on the canonical entry url
 func (i FeedItem) UniqueURL() string {
-	id := i.EntryURL
-	if !i.PublishedAt.IsZero() {
-		id = fmt.Sprintf("%s&t=%s", id, i.PublishedAt.Format("20060102150405"))
+	return uniqueURL(CanonicalURL(i.EntryURL), i.PublishedAt)
+}
+
+// LegacyUniqueURL builds unique url based on the entry url as is
+// it is the id of the items that were stored before canonicalization
+func (i FeedItem) LegacyUniqueURL() string {
+	return uniqueURL(i.EntryURL, i.PublishedAt)
+}
+
+// LegacyUniqueURLs returns the ids of the items that may have been stored before canonicalization
+// the first one is LegacyUniqueURL, the others are built on the variants of the entry url (e.g. http, s.ameblo.jp)
+func (i FeedItem) LegacyUniqueURLs() []string {
+	id := i.UniqueURL()
+	var ids []string
+	seen := map[string]bool{id: true}
+	for _, urlStr := range append([]string{i.EntryURL}, LegacyURLs(i.EntryURL)...) {
+		if v := uniqueURL(urlStr, i.PublishedAt); !seen[v] {
+			seen[v] = true
+			ids = append(ids, v)
+		}
+	}
+	return ids
+}
+
+func uniqueURL(urlStr string, publishedAt time.Time) string {
+	id := urlStr
+	if !publishedAt.IsZero() {
+		id = fmt.Sprintf("%s&t=%s", id, publishedAt.Format("20060102150405"))
 	}
 	return id
 }
 
 // FeedCode returns identify code based on entry url
 func (i FeedItem) FeedCode() FeedCode {
-	var code FeedCode
-	blogCodes := []FeedCode{
-		FeedCodeTamai,
-		FeedCodeMomota,
-		FeedCodeSasaki,
-		FeedCodeTakagi,
-	}
-	for _, c := range blogCodes {
-		if strings.HasPrefix(i.EntryURL, fmt.Sprintf("https://ameblo.jp/%v", c)) {
-			code = c
-			break
-		}
+	if f, ok := FindFeedByURL(i.EntryURL); ok {
+		return f.Code
+	}
+	return ""
+}
+
+// Summary returns the first n characters of the body that are cut at a sentence boundary
+// it is cut at n characters with ellipsis if the boundary is not found in the latter part
+func (i FeedItem) Summary(n int) string {
+	runes := []rune(i.Body)
+	if n <= 0 || len(runes) == 0 {
+		return ""
 	}
-	if strings.HasPrefix(i.EntryURL, "http://www.tfm.co.jp/clover/") {
-		code = FeedCodeHappyclo
-	} else if strings.HasPrefix(i.EntryURL, "http://www.momoclo.net") {
-		code = FeedCodeAeNews
-	} else if strings.HasPrefix(i.EntryURL, "https://www.youtube.com") {
-		code = FeedCodeYoutube
+	if len(runes) <= n {
+		return i.Body
 	}
-	return code
+
+	for j := n - 1; j >= n/2; j-- {
+		switch runes[j] {
+		case '。', '！', '？', '!', '?', '\n':
+			return strings.TrimSpace(string(runes[:j+1]))
+		}
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
 }
 
 // ToLineNotifyMessages converts FeedItem to []linenotify.Message
@@ -79,6 +109,9 @@ func (i FeedItem) ToLineNotifyMessages() []linenotify.Message {
 	var messages []linenotify.Message
 
 	text := fmt.Sprintf("\n%s\n%s\n%s", i.Title, i.EntryTitle, i.EntryURL)
+	if summary := i.Summary(crawlerSettings().SummaryLength); summary != "" {
+		text = fmt.Sprintf("\n%s\n%s\n\n%s\n\n%s", i.Title, i.EntryTitle, summary, i.EntryURL)
+	}
 	if len(i.ImageURLs) > 0 {
 		messages = append(messages, linenotify.Message{Text: text, ImageURL: i.ImageURLs[0]})
 		i.ImageURLs = i.ImageURLs[1:]
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// hostLimiter spaces requests to the same host in the process
+	// the limiter is per instance and per client, so requests from other App Engine instances are not spaced
+	hostLimiter struct {
+		mu   sync.Mutex
+		next map[string]time.Time
+	}
+
+	// limitedTransport waits for the host limiter before sending request
+	limitedTransport struct {
+		base    http.RoundTripper
+		limiter *hostLimiter
+	}
+)
+
+func newHostLimiter() *hostLimiter {
+	return &hostLimiter{next: map[string]time.Time{}}
+}
+
+// Wait blocks until a request to given host is allowed
+// the requests to the same host are spaced at least given interval
+func (l *hostLimiter) Wait(ctx context.Context, host string, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	next := l.next[host]
+	if next.Before(now) {
+		next = now
+	}
+	l.next[host] = next.Add(interval)
+	l.mu.Unlock()
+
+	d := next.Sub(now)
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context(), req.URL.Host, hostInterval()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// hostInterval returns the minimum interval between requests to the same host
+// the interval is validated when config is loaded
+func hostInterval() time.Duration {
+	v := crawlerSettings().HostInterval
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,41 @@
+package crawler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter_Wait(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	l := newHostLimiter()
+	ctx := context.Background()
+
+	begin := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx, "ameblo.jp", interval); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(begin); d < 2*interval {
+		t.Errorf("Expected requests to the same host are spaced, got %v", d)
+	}
+
+	begin = time.Now()
+	if err := l.Wait(ctx, "www.momoclo.net", interval); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(begin); d >= interval {
+		t.Errorf("Expected a request to another host is not blocked, got %v", d)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(ctx, "ameblo.jp", time.Hour); err == nil {
+		t.Error("Expected error when canceled, got nil")
+	}
+
+	if err := l.Wait(ctx, "ameblo.jp", 0); err != nil {
+		t.Errorf("Expected no wait without interval, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package crawler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/timeutil"
+)
+
+const (
+	FeedSourceAmeblo   FeedSource = "ameblo"
+	FeedSourceHappyclo FeedSource = "happyclo"
+	FeedSourceAeNews   FeedSource = "aenews"
+	FeedSourceYoutube  FeedSource = "youtube"
+
+	// FeedSourceSyndication represents RSS 2.0, Atom 1.0 or JSON Feed
+	FeedSourceSyndication FeedSource = "syndication"
+)
+
+type (
+	// FeedSource represents the type of source that a feed is fetched from
+	FeedSource string
+
+	// Feed represents a feed that the crawler follows
+	Feed struct {
+		Code       FeedCode
+		Source     FeedSource
+		URL        string // url of the feed document (syndication source only)
+		URLPattern string // prefix of the entry urls that belong to the feed
+		Title      string // overrides the fetched title if not empty
+		Enabled    bool
+
+		// Schedule is a cron expression or an interval (e.g. "@every 10m") in JST
+		// the feed is crawled every minute if empty
+		Schedule string
+
+		// ActiveWindows are time windows in a day in JST (e.g. "17:00-18:00")
+		// the feed is crawled only within any of the windows if not empty
+		ActiveWindows []string
+
+		// StaleAfter is the duration without new items that is regarded as stale (e.g. "72h")
+		// it falls back to the crawler settings if empty
+		StaleAfter string
+
+		// Color is the theme colour of the member (e.g. "#ff0000")
+		Color string
+
+		// Member is the name of the member who writes the blog (e.g. "百田夏菜子")
+		Member string
+
+		// Canonical is the rule to canonicalize entry urls before comparing them
+		Canonical CanonicalRule
+	}
+)
+
+// defaultFeeds are used when no feeds are given by config
+var defaultFeeds = []Feed{
+	{Code: FeedCodeMomota, Source: FeedSourceAmeblo, URLPattern: "https://ameblo.jp/momota-sd", Enabled: true, Color: "#ff0000", Member: "百田夏菜子", Canonical: amebloRule},
+	{Code: FeedCodeTamai, Source: FeedSourceAmeblo, URLPattern: "https://ameblo.jp/tamai-sd", Enabled: true, Color: "#ffd700", Member: "玉井詩織", Canonical: amebloRule},
+	{Code: FeedCodeSasaki, Source: FeedSourceAmeblo, URLPattern: "https://ameblo.jp/sasaki-sd", Enabled: true, Color: "#ff69b4", Member: "佐々木彩夏", Canonical: amebloRule},
+	{Code: FeedCodeTakagi, Source: FeedSourceAmeblo, URLPattern: "https://ameblo.jp/takagi-sd", Enabled: true, Color: "#800080", Member: "高城れに", Canonical: amebloRule},
+	{Code: FeedCodeHappyclo, Source: FeedSourceHappyclo, URLPattern: "http://www.tfm.co.jp/clover/", Enabled: true, Schedule: "* 17 * * 0"},
+	{Code: FeedCodeAeNews, Source: FeedSourceAeNews, URLPattern: "http://www.momoclo.net", Enabled: true},
+	{Code: FeedCodeYoutube, Source: FeedSourceYoutube, URLPattern: "https://www.youtube.com", Enabled: true},
+}
+
+// String returns string representation of FeedSource
+func (s FeedSource) String() string {
+	return string(s)
+}
+
+// Feeds returns all registered feeds
+// it returns the built-in feeds if config does not have any feeds
+func Feeds() []Feed {
+	c := config.C()
+	if c == nil || len(c.Feeds) == 0 {
+		return defaultFeeds
+	}
+
+	feeds := make([]Feed, len(c.Feeds))
+	for i, f := range c.Feeds {
+		feeds[i] = Feed{
+			Code:       FeedCode(f.Code),
+			Source:     FeedSource(f.Source),
+			URL:        f.URL,
+			URLPattern: f.URLPattern,
+			Title:      f.Title,
+			Enabled:    f.Enabled,
+
+			Schedule:      f.Schedule,
+			ActiveWindows: f.ActiveWindows,
+			StaleAfter:    f.StaleAfter,
+			Color:         f.Color,
+			Member:        f.Member,
+
+			Canonical: CanonicalRule{
+				Scheme:            f.CanonicalScheme,
+				Host:              f.CanonicalHost,
+				HostAliases:       f.HostAliases,
+				StripParams:       f.StripParams,
+				TrimTrailingSlash: f.TrimTrailingSlash,
+			},
+		}
+	}
+	return feeds
+}
+
+// maxScheduleLookback limits how far the slots missed since the last crawl are looked back
+const maxScheduleLookback = 24 * time.Hour
+
+// Due returns true if the feed has a scheduled minute after the last crawl until given time
+// the slot of a delayed or skipped tick is crawled at the next tick, the last crawl is zero if the feed has never been crawled
+func (f Feed) Due(last, now time.Time) (bool, error) {
+	s, err := timeutil.ParseSchedule(f.Schedule)
+	if err != nil {
+		return false, errors.Wrapf(err, "code:%v", f.Code)
+	}
+	windows := make([]timeutil.Window, len(f.ActiveWindows))
+	for i, v := range f.ActiveWindows {
+		if windows[i], err = timeutil.ParseWindow(v); err != nil {
+			return false, errors.Wrapf(err, "code:%v", f.Code)
+		}
+	}
+
+	now = now.Truncate(time.Minute)
+	since := now.Add(-maxScheduleLookback)
+	if last.IsZero() {
+		since = now.Add(-time.Minute)
+	} else if v := last.Truncate(time.Minute); v.After(since) {
+		since = v
+	}
+	for t := now; t.After(since); t = t.Add(-time.Minute) {
+		if s.Match(t) && inWindows(windows, t) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// inWindows returns true if given time is in any of the windows, or windows are empty
+func inWindows(windows []timeutil.Window, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// StaleDuration returns the duration without new items that is regarded as stale
+// it returns zero if staleness is not checked
+func (f Feed) StaleDuration() (time.Duration, error) {
+	v := f.StaleAfter
+	if v == "" {
+		v = crawlerSettings().StaleAfter
+	}
+	if v == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, errors.Wrapf(err, "code:%v invalid stale after:%v", f.Code, v)
+	}
+	return d, nil
+}
+
+// ColorValue returns the theme colour as RGB integer
+// it returns zero if the colour is empty or invalid
+func (f Feed) ColorValue() int {
+	v, err := strconv.ParseInt(strings.TrimPrefix(f.Color, "#"), 16, 32)
+	if err != nil {
+		return 0
+	}
+	return int(v)
+}
+
+// EnabledFeeds returns registered feeds that are enabled
+func EnabledFeeds() []Feed {
+	var feeds []Feed
+	for _, f := range Feeds() {
+		if f.Enabled {
+			feeds = append(feeds, f)
+		}
+	}
+	return feeds
+}
+
+// FindFeed returns the registered feed given code
+func FindFeed(code FeedCode) (Feed, bool) {
+	for _, f := range Feeds() {
+		if f.Code == code {
+			return f, true
+		}
+	}
+	return Feed{}, false
+}
+
+// FindFeedByURL returns the registered feed that the given entry url belongs to
+// the url is canonicalized by the rule of each feed before matching
+func FindFeedByURL(urlStr string) (Feed, bool) {
+	u, ok := parseNormalizedURL(urlStr)
+	for _, f := range Feeds() {
+		if f.URLPattern == "" {
+			continue
+		}
+		if strings.HasPrefix(urlStr, f.URLPattern) || (ok && strings.HasPrefix(f.Canonical.apply(*u), f.URLPattern)) {
+			return f, true
+		}
+	}
+	return Feed{}, false
+}
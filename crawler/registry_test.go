@@ -0,0 +1,152 @@
+package crawler
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/timeutil"
+)
+
+func TestFindFeed(t *testing.T) {
+	tests := []struct {
+		code     FeedCode
+		expected bool
+	}{
+		{FeedCodeMomota, true},
+		{FeedCodeHappyclo, true},
+		{FeedCode("unknown"), false},
+	}
+
+	for _, test := range tests {
+		f, ok := FindFeed(test.code)
+		if ok != test.expected {
+			t.Errorf("Expected %v, got %v. code:%v", test.expected, ok, test.code)
+		}
+		if ok && f.Code != test.code {
+			t.Errorf("Expected code %v, got %v", test.code, f.Code)
+		}
+	}
+}
+
+func TestFeedItem_FeedCode(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected FeedCode
+	}{
+		{"https://ameblo.jp/momota-sd/entry-1.html", FeedCodeMomota},
+		{"https://ameblo.jp/tamai-sd/entry-1.html", FeedCodeTamai},
+		{"https://ameblo.jp/sasaki-sd/entry-1.html", FeedCodeSasaki},
+		{"https://ameblo.jp/takagi-sd/entry-1.html", FeedCodeTakagi},
+		{"http://s.ameblo.jp/takagi-sd/entry-1.html?frm=theme", FeedCodeTakagi},
+		{"http://www.tfm.co.jp/clover/index.php?itemid=1", FeedCodeHappyclo},
+		{"http://www.momoclo.net/pc/news/1", FeedCodeAeNews},
+		{"https://www.youtube.com/watch?v=1", FeedCodeYoutube},
+		{"http://localhost/entry", ""},
+	}
+
+	for _, test := range tests {
+		code := FeedItem{EntryURL: test.url}.FeedCode()
+		if code != test.expected {
+			t.Errorf("Expected %v, got %v. url:%v", test.expected, code, test.url)
+		}
+	}
+}
+
+func TestFeeds_Config(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.WriteString(`
+[[Feeds]]
+  Code = "news"
+  Source = "aenews"
+  URLPattern = "http://example.com/news/"
+  Title = "news title"
+  Enabled = true
+
+[[Feeds]]
+  Code = "retired"
+  Source = "youtube"
+  URLPattern = "http://example.com/retired/"
+  Enabled = false
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Load(tmpfile.Name()); err != nil {
+		t.Fatal(err)
+	}
+	defer config.Load(os.DevNull)
+
+	if len(Feeds()) != 2 {
+		t.Fatalf("Expected feeds length 2, got %v", len(Feeds()))
+	}
+
+	feeds := EnabledFeeds()
+	if len(feeds) != 1 {
+		t.Fatalf("Expected enabled feeds length 1, got %v", len(feeds))
+	}
+	if feeds[0].Code != "news" || feeds[0].Source != FeedSourceAeNews || feeds[0].Title != "news title" {
+		t.Errorf("Expected news feed, got %v", feeds[0])
+	}
+
+	if code := (FeedItem{EntryURL: "http://example.com/retired/1"}).FeedCode(); code != "retired" {
+		t.Errorf("Expected retired, got %v", code)
+	}
+	if _, ok := FindFeed(FeedCodeMomota); ok {
+		t.Errorf("Expected built-in feed not found, but found")
+	}
+}
+
+func TestFeed_Due(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, timeutil.JST())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		feed     Feed
+		last     time.Time
+		t        time.Time
+		expected bool
+	}{
+		{Feed{}, time.Time{}, at("2008-05-17 12:34"), true},
+		{Feed{Schedule: "* 17 * * 0"}, time.Time{}, at("2008-05-18 17:00"), true},
+		{Feed{Schedule: "* 17 * * 0"}, time.Time{}, at("2008-05-17 17:00"), false},
+		{Feed{Schedule: "@every 5m", ActiveWindows: []string{"07:00-01:00"}}, time.Time{}, at("2008-05-17 00:30"), true},
+		{Feed{Schedule: "@every 5m", ActiveWindows: []string{"07:00-01:00"}}, time.Time{}, at("2008-05-17 03:00"), false},
+		{Feed{ActiveWindows: []string{"09:00-10:00", "21:00-22:00"}}, time.Time{}, at("2008-05-17 21:15"), true},
+		// the slot of the delayed tick
+		{Feed{Schedule: "@every 10m"}, at("2008-05-17 12:30"), at("2008-05-17 12:41"), true},
+		{Feed{Schedule: "@every 10m"}, at("2008-05-17 12:40").Add(30 * time.Second), at("2008-05-17 12:41"), false},
+		{Feed{Schedule: "0 0 * * *"}, at("2008-05-16 23:59"), at("2008-05-17 00:03"), true},
+		{Feed{Schedule: "0 0 * * *"}, at("2008-05-17 00:00"), at("2008-05-17 00:03"), false},
+		{Feed{Schedule: "@every 5m", ActiveWindows: []string{"07:00-01:00"}}, at("2008-05-17 00:50"), at("2008-05-17 01:02"), true},
+		{Feed{Schedule: "@every 5m", ActiveWindows: []string{"07:00-01:00"}}, at("2008-05-17 00:55"), at("2008-05-17 01:02"), false},
+		// the slot older than the lookback is dropped
+		{Feed{Schedule: "* 17 * * 0"}, at("2008-05-11 17:59"), at("2008-05-19 18:30"), false},
+	}
+
+	for _, test := range tests {
+		due, err := test.feed.Due(test.last, test.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if due != test.expected {
+			t.Errorf("Expected %v, got %v. feed:%v last:%v t:%v", test.expected, due, test.feed, test.last, test.t)
+		}
+	}
+
+	if _, err := (Feed{Schedule: "invalid"}).Due(time.Time{}, at("2008-05-17 12:34")); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,480 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html/charset"
+	"google.golang.org/appengine/urlfetch"
+)
+
+type (
+	syndicationClient struct {
+	}
+
+	// syndicationChannel represents a parsed RSS, Atom or JSON Feed document
+	syndicationChannel struct {
+		Title   string
+		URL     string
+		NextURL string // url of the next page of the archive (RFC 5005 or JSON Feed next_url)
+		Items   []FeedItem
+	}
+
+	rssDocument struct {
+		Channel struct {
+			Title string `xml:"title"`
+			// atom:link must precede link, an element matches the first field of its name
+			AtomLinks []atomLink `xml:"http://www.w3.org/2005/Atom link"`
+			Link      string     `xml:"link"`
+			Items     []rssItem  `xml:"item"`
+		} `xml:"channel"`
+	}
+
+	rssItem struct {
+		Title     string         `xml:"title"`
+		Link      string         `xml:"link"`
+		GUID      rssGUID        `xml:"guid"`
+		PubDate   string         `xml:"pubDate"`
+		Date      string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+		Desc      string         `xml:"description"`
+		Content   string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		Enclosure []rssEnclosure `xml:"enclosure"`
+		Media     []mediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	}
+
+	rssGUID struct {
+		IsPermaLink string `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+
+	rssEnclosure struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	}
+
+	mediaContent struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Medium string `xml:"medium,attr"`
+	}
+
+	atomDocument struct {
+		Title   string      `xml:"title"`
+		Links   []atomLink  `xml:"link"`
+		Entries []atomEntry `xml:"entry"`
+	}
+
+	atomEntry struct {
+		Title     string         `xml:"title"`
+		Links     []atomLink     `xml:"link"`
+		Published string         `xml:"published"`
+		Updated   string         `xml:"updated"`
+		Content   atomText       `xml:"content"`
+		Summary   atomText       `xml:"summary"`
+		Media     []mediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	}
+
+	atomText struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+		Inner string `xml:",innerxml"` // keeps the child elements that chardata drops
+	}
+
+	atomLink struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr"`
+	}
+
+	jsonFeedDocument struct {
+		Title       string         `json:"title"`
+		HomePageURL string         `json:"home_page_url"`
+		NextURL     string         `json:"next_url"`
+		Items       []jsonFeedItem `json:"items"`
+	}
+
+	jsonFeedItem struct {
+		ID            string `json:"id"`
+		URL           string `json:"url"`
+		Title         string `json:"title"`
+		Image         string `json:"image"`
+		ContentHTML   string `json:"content_html"`
+		ContentText   string `json:"content_text"`
+		Summary       string `json:"summary"`
+		DatePublished string `json:"date_published"`
+		DateModified  string `json:"date_modified"`
+		Attachments   []struct {
+			URL      string `json:"url"`
+			MimeType string `json:"mime_type"`
+		} `json:"attachments"`
+	}
+)
+
+// dateLayouts are the layouts of date that appear in feeds
+var dateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// NewSyndicationFetcher returns FeedFetcher that fetches RSS 2.0, Atom 1.0 and JSON Feed
+func NewSyndicationFetcher() FeedFetcher {
+	return &syndicationClient{}
+}
+
+func (c *syndicationClient) Fetch(ctx context.Context, code FeedCode, maxItemNum int, latestURL string) ([]FeedItem, error) {
+	const errTag = "syndication Fetch failed"
+
+	feed, ok := FindFeed(code)
+	if !ok {
+		return nil, errors.Errorf("%v: code:%s did not register", errTag, code)
+	}
+
+	items, err := fetchSyndication(urlfetch.Client(ctx), feed, maxItemNum, latestURL)
+	if err != nil {
+		return nil, errors.Wrap(err, errTag)
+	}
+	return items, nil
+}
+
+// fetchSyndication fetches the feed document of given feed using given http client
+func fetchSyndication(httpClient *http.Client, feed Feed, maxItemNum int, latestURL string) ([]FeedItem, error) {
+	if feed.URL == "" {
+		return nil, errors.Errorf("code:%s has no feed url", feed.Code)
+	}
+
+	channel, err := fetchSyndicationChannel(httpClient, feed, feed.URL)
+	if err != nil {
+		return nil, err
+	}
+	return limitItems(channel.Items, maxItemNum, latestURL), nil
+}
+
+// fetchSyndicationChannel fetches the feed document of given url that belongs to given feed
+func fetchSyndicationChannel(httpClient *http.Client, feed Feed, urlStr string) (*syndicationChannel, error) {
+	resp, err := httpClient.Get(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code:%v url:%v", resp.StatusCode, urlStr)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := parseSyndication(b, urlStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "url:%v", urlStr)
+	}
+
+	title := channel.Title
+	if feed.Title != "" {
+		title = feed.Title
+	}
+	for i := range channel.Items {
+		channel.Items[i].Title = title
+		channel.Items[i].URL = channel.URL
+	}
+	return channel, nil
+}
+
+// parseSyndication parses RSS 2.0, Atom 1.0 or JSON Feed document
+// items are sorted by published time in descending order
+func parseSyndication(b []byte, baseURL string) (*syndicationChannel, error) {
+	var (
+		channel *syndicationChannel
+		err     error
+	)
+
+	b = bytes.TrimSpace(b)
+	if bytes.HasPrefix(b, []byte("{")) {
+		channel, err = parseJSONFeed(b)
+	} else {
+		channel, err = parseXMLFeed(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	channel.URL = resolveURL(baseURL, channel.URL)
+	if channel.URL == "" {
+		channel.URL = baseURL
+	}
+	channel.NextURL = resolveURL(baseURL, channel.NextURL)
+	for i, item := range channel.Items {
+		channel.Items[i].EntryURL = resolveURL(baseURL, item.EntryURL)
+		for j, imageURL := range item.ImageURLs {
+			channel.Items[i].ImageURLs[j] = resolveURL(baseURL, imageURL)
+		}
+		for j, videoURL := range item.VideoURLs {
+			channel.Items[i].VideoURLs[j] = resolveURL(baseURL, videoURL)
+		}
+	}
+
+	sort.SliceStable(channel.Items, func(i, j int) bool {
+		return channel.Items[i].PublishedAt.After(channel.Items[j].PublishedAt)
+	})
+	return channel, nil
+}
+
+func parseXMLFeed(b []byte) (*syndicationChannel, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := unmarshalXML(b, &root); err != nil {
+		return nil, err
+	}
+
+	switch root.XMLName.Local {
+	case "rss":
+		return parseRSS(b)
+	case "feed":
+		return parseAtom(b)
+	}
+	return nil, errors.Errorf("unknown feed format root:%v", root.XMLName.Local)
+}
+
+func parseRSS(b []byte) (*syndicationChannel, error) {
+	var doc rssDocument
+	if err := unmarshalXML(b, &doc); err != nil {
+		return nil, err
+	}
+
+	channel := &syndicationChannel{
+		Title:   strings.TrimSpace(doc.Channel.Title),
+		URL:     strings.TrimSpace(doc.Channel.Link),
+		NextURL: atomLinkByRel(doc.Channel.AtomLinks, "next"),
+	}
+	for _, v := range doc.Channel.Items {
+		item := FeedItem{
+			EntryTitle:  strings.TrimSpace(v.Title),
+			EntryURL:    strings.TrimSpace(v.Link),
+			PublishedAt: parseDate(v.PubDate, v.Date),
+			Body:        htmlText(v.Content, v.Desc),
+		}
+		if item.EntryURL == "" && v.GUID.IsPermaLink != "false" {
+			item.EntryURL = strings.TrimSpace(v.GUID.Value)
+		}
+		for _, e := range v.Enclosure {
+			item.appendMedia(e.URL, e.Type, "")
+		}
+		for _, m := range v.Media {
+			item.appendMedia(m.URL, m.Type, m.Medium)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	return channel, nil
+}
+
+func parseAtom(b []byte) (*syndicationChannel, error) {
+	var doc atomDocument
+	if err := unmarshalXML(b, &doc); err != nil {
+		return nil, err
+	}
+
+	channel := &syndicationChannel{
+		Title:   strings.TrimSpace(doc.Title),
+		URL:     atomAlternateLink(doc.Links),
+		NextURL: atomLinkByRel(doc.Links, "next"),
+	}
+	for _, v := range doc.Entries {
+		item := FeedItem{
+			EntryTitle:  strings.TrimSpace(v.Title),
+			EntryURL:    atomAlternateLink(v.Links),
+			PublishedAt: parseDate(v.Published, v.Updated),
+			Body:        v.Content.text(),
+		}
+		if item.Body == "" {
+			item.Body = v.Summary.text()
+		}
+		for _, l := range v.Links {
+			if l.Rel == "enclosure" {
+				item.appendMedia(l.Href, l.Type, "")
+			}
+		}
+		for _, m := range v.Media {
+			item.appendMedia(m.URL, m.Type, m.Medium)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	return channel, nil
+}
+
+func parseJSONFeed(b []byte) (*syndicationChannel, error) {
+	var doc jsonFeedDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	channel := &syndicationChannel{
+		Title:   strings.TrimSpace(doc.Title),
+		URL:     doc.HomePageURL,
+		NextURL: doc.NextURL,
+	}
+	for _, v := range doc.Items {
+		item := FeedItem{
+			EntryTitle:  strings.TrimSpace(v.Title),
+			EntryURL:    v.URL,
+			PublishedAt: parseDate(v.DatePublished, v.DateModified),
+			Body:        normalizeText(v.ContentText),
+		}
+		if item.Body == "" {
+			item.Body = htmlText(v.ContentHTML, v.Summary)
+		}
+		if item.EntryURL == "" && strings.HasPrefix(v.ID, "http") {
+			item.EntryURL = v.ID
+		}
+		if v.Image != "" {
+			item.ImageURLs = append(item.ImageURLs, v.Image)
+		}
+		for _, a := range v.Attachments {
+			item.appendMedia(a.URL, a.MimeType, "")
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	return channel, nil
+}
+
+// unmarshalXML decodes XML document that may be encoded other than UTF-8
+func unmarshalXML(b []byte, v interface{}) error {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	d.CharsetReader = charset.NewReaderLabel
+	return d.Decode(v)
+}
+
+// appendMedia appends given url to ImageURLs or VideoURLs by its mime type or medium
+func (i *FeedItem) appendMedia(urlStr, mimeType, medium string) {
+	if urlStr == "" {
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(mimeType, "image/") || medium == "image":
+		for _, u := range i.ImageURLs {
+			if u == urlStr {
+				return
+			}
+		}
+		i.ImageURLs = append(i.ImageURLs, urlStr)
+	case strings.HasPrefix(mimeType, "video/") || medium == "video":
+		for _, u := range i.VideoURLs {
+			if u == urlStr {
+				return
+			}
+		}
+		i.VideoURLs = append(i.VideoURLs, urlStr)
+	}
+}
+
+// text returns readable text of the atom text construct
+// the html is escaped as text, but some feeds put it as child elements as well as the xhtml
+func (t atomText) text() string {
+	switch t.Type {
+	case "xhtml":
+		return ExtractText(t.Inner)
+	case "html":
+		if inner := strings.TrimSpace(t.Inner); strings.HasPrefix(inner, "<") && !strings.HasPrefix(inner, "<![CDATA[") {
+			return ExtractText(inner)
+		}
+		return ExtractText(t.Value)
+	}
+	return normalizeText(t.Value)
+}
+
+// htmlText returns readable text of the first HTML that is not empty
+func htmlText(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return ExtractText(v)
+		}
+	}
+	return ""
+}
+
+func atomLinkByRel(links []atomLink, rel string) string {
+	for _, l := range links {
+		if l.Rel == rel {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	return ""
+}
+
+func atomAlternateLink(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	return ""
+}
+
+// parseDate returns the first date that can be parsed
+func parseDate(values ...string) time.Time {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func resolveURL(baseURL, urlStr string) string {
+	if urlStr == "" {
+		return ""
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return urlStr
+	}
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return urlStr
+	}
+	return base.ResolveReference(u).String()
+}
+
+// limitItems returns items that are newer than latestURL up to maxItemNum
+func limitItems(items []FeedItem, maxItemNum int, latestURL string) []FeedItem {
+	if latestURL != "" {
+		latestURL = CanonicalURL(latestURL)
+	}
+
+	var results []FeedItem
+	for _, item := range items {
+		if latestURL != "" && CanonicalURL(item.EntryURL) == latestURL {
+			break
+		}
+		if maxItemNum > 0 && len(results) >= maxItemNum {
+			break
+		}
+		results = append(results, item)
+	}
+	return results
+}
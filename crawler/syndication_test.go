@@ -0,0 +1,122 @@
+package crawler
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSyndication(t *testing.T) {
+	tests := []struct {
+		file          string
+		expectedTitle string
+		expectedURL   string
+		expectedNext  string
+		expectedItems []FeedItem
+	}{
+		{
+			"rss.xml", "rss title", "http://localhost/rss", "http://localhost/rss.xml?page=2",
+			[]FeedItem{
+				{EntryTitle: "entry 3", EntryURL: "http://localhost/rss/3", VideoURLs: []string{"http://localhost/rss/3.mp4"}, Body: "entry 3 body"},
+				{EntryTitle: "entry 2", EntryURL: "http://localhost/rss/2", ImageURLs: []string{"http://localhost/rss/2.jpg", "http://localhost/rss/2_b.jpg"}, Body: "hello\nworld\n\nbye"},
+				{EntryTitle: "entry 1", EntryURL: "http://localhost/rss/1"},
+			},
+		},
+		{
+			"atom.xml", "atom title", "http://localhost/atom", "http://localhost/atom.xml?page=2",
+			[]FeedItem{
+				{EntryTitle: "entry 2", EntryURL: "http://localhost/atom/2", ImageURLs: []string{"http://localhost/atom/2.png"}, Body: "atom & body"},
+				{EntryTitle: "entry 1", EntryURL: "http://localhost/atom/1", Body: "atom summary"},
+			},
+		},
+		{
+			"atom_xhtml.xml", "xhtml title", "http://localhost/xhtml", "",
+			[]FeedItem{
+				{EntryTitle: "entry 2", EntryURL: "http://localhost/xhtml/2", Body: "xhtml bold body\n\nsecond & last"},
+				{EntryTitle: "entry 1", EntryURL: "http://localhost/xhtml/1", Body: "raw html"},
+			},
+		},
+		{
+			"feed.json", "json title", "http://localhost/json", "",
+			[]FeedItem{
+				{EntryTitle: "entry 2", EntryURL: "http://localhost/json/2", ImageURLs: []string{"http://localhost/json/2.jpg"}, VideoURLs: []string{"http://localhost/json/2.mp4"}, Body: "json html"},
+				{EntryTitle: "entry 1", EntryURL: "http://localhost/json/1", Body: "json\n\ntext"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		b, err := ioutil.ReadFile(filepath.Join("testdata", test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		channel, err := parseSyndication(b, "http://localhost/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if channel.Title != test.expectedTitle {
+			t.Errorf("Expected title %v, got %v", test.expectedTitle, channel.Title)
+		}
+		if channel.URL != test.expectedURL {
+			t.Errorf("Expected url %v, got %v", test.expectedURL, channel.URL)
+		}
+		if channel.NextURL != test.expectedNext {
+			t.Errorf("Expected next url %v, got %v", test.expectedNext, channel.NextURL)
+		}
+		if len(channel.Items) != len(test.expectedItems) {
+			t.Fatalf("Expected items length %v, got %v. file:%v", len(test.expectedItems), len(channel.Items), test.file)
+		}
+
+		for i, expected := range test.expectedItems {
+			item := channel.Items[i]
+			if item.EntryTitle != expected.EntryTitle || item.EntryURL != expected.EntryURL {
+				t.Errorf("Expected %v %v, got %v %v", expected.EntryTitle, expected.EntryURL, item.EntryTitle, item.EntryURL)
+			}
+			if !reflect.DeepEqual(item.ImageURLs, expected.ImageURLs) {
+				t.Errorf("Expected image urls %v, got %v", expected.ImageURLs, item.ImageURLs)
+			}
+			if !reflect.DeepEqual(item.VideoURLs, expected.VideoURLs) {
+				t.Errorf("Expected video urls %v, got %v", expected.VideoURLs, item.VideoURLs)
+			}
+			if item.Body != expected.Body {
+				t.Errorf("Expected body %q, got %q", expected.Body, item.Body)
+			}
+			if item.PublishedAt.IsZero() {
+				t.Errorf("Expected published at, got zero. file:%v i:%v", test.file, i)
+			}
+		}
+	}
+
+	if _, err := parseSyndication([]byte("<html></html>"), "http://localhost/"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestLimitItems(t *testing.T) {
+	items := []FeedItem{
+		{EntryURL: "http://localhost/3"},
+		{EntryURL: "http://localhost/2"},
+		{EntryURL: "http://localhost/1"},
+	}
+
+	tests := []struct {
+		maxItemNum  int
+		latestURL   string
+		expectedLen int
+	}{
+		{0, "", 3},
+		{1, "", 1},
+		{5, "http://localhost/2", 1},
+		{5, "http://localhost/3", 0},
+		{2, "http://localhost/unknown", 2},
+	}
+
+	for _, test := range tests {
+		results := limitItems(items, test.maxItemNum, test.latestURL)
+		if len(results) != test.expectedLen {
+			t.Errorf("Expected length %v, got %v. max:%v latest:%v", test.expectedLen, len(results), test.maxItemNum, test.latestURL)
+		}
+	}
+}
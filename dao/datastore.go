@@ -6,6 +6,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/dao/hook"
+	"google.golang.org/appengine"
 	"google.golang.org/appengine/datastore"
 )
 
@@ -78,8 +79,22 @@ func (h *datastoreHandler) Get(ctx context.Context, dst interface{}) error {
 }
 
 // GetMulti wraps goon.GetMulti()
+// the error of the missing entity is ErrNoSuchEntity in MultiError
 func (h *datastoreHandler) GetMulti(ctx context.Context, dst interface{}) error {
-	return FromContext(ctx).GetMulti(dst)
+	err := FromContext(ctx).GetMulti(dst)
+	me, ok := err.(appengine.MultiError)
+	if !ok {
+		return err
+	}
+
+	errs := make(MultiError, len(me))
+	for i, err := range me {
+		if err == datastore.ErrNoSuchEntity {
+			err = ErrNoSuchEntity
+		}
+		errs[i] = err
+	}
+	return errs
 }
 
 // Delete wraps goon.Delete()
@@ -8,6 +8,8 @@ type (
 	// PersistenceQuery interface
 	PersistenceQuery interface {
 		Filter(string, interface{}) PersistenceQuery
+		Order(string) PersistenceQuery
+		Limit(int) PersistenceQuery
 	}
 
 	datastoreQuery struct {
@@ -27,3 +29,15 @@ func (q *datastoreQuery) Filter(filterStr string, value interface{}) Persistence
 	q.Query = q.Query.Filter(filterStr, value)
 	return q
 }
+
+// Order wraps datastore.Query.Order
+func (q *datastoreQuery) Order(fieldName string) PersistenceQuery {
+	q.Query = q.Query.Order(fieldName)
+	return q
+}
+
+// Limit wraps datastore.Query.Limit
+func (q *datastoreQuery) Limit(limit int) PersistenceQuery {
+	q.Query = q.Query.Limit(limit)
+	return q
+}
@@ -5,3 +5,15 @@ import "github.com/pkg/errors"
 var (
 	ErrNoSuchEntity = errors.New("mcz: no such entity")
 )
+
+// MultiError is returned by batch operations, each error corresponds to the element of the same index
+type MultiError []error
+
+func (m MultiError) Error() string {
+	for _, err := range m {
+		if err != nil {
+			return err.Error()
+		}
+	}
+	return "(0 errors)"
+}
@@ -0,0 +1,136 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"google.golang.org/appengine/urlfetch"
+)
+
+type (
+	// Message represents webhook message that has rich embeds
+	// see: https://discordapp.com/developers/docs/resources/webhook#execute-webhook
+	Message struct {
+		Content string  `json:"content,omitempty"`
+		Embeds  []Embed `json:"embeds,omitempty" validate:"dive"`
+	}
+
+	// Embed represents rich embed
+	Embed struct {
+		Title       string      `json:"title,omitempty" validate:"required"`
+		URL         string      `json:"url,omitempty" validate:"omitempty,url"`
+		Description string      `json:"description,omitempty"`
+		Color       int         `json:"color,omitempty"`
+		Timestamp   string      `json:"timestamp,omitempty"` // ISO8601
+		Author      *EmbedName  `json:"author,omitempty"`
+		Image       *EmbedImage `json:"image,omitempty"`
+	}
+
+	// EmbedName represents author of embed
+	EmbedName struct {
+		Name string `json:"name"`
+	}
+
+	// EmbedImage represents image of embed
+	EmbedImage struct {
+		URL string `json:"url" validate:"url"`
+	}
+
+	// Request represents request that posts message to a webhook
+	Request struct {
+		ID         string `validate:"required"`
+		WebhookURL string `validate:"required,url"`
+		Message    Message
+	}
+
+	// Client interface
+	Client interface {
+		Post(context.Context, string, Message) error
+	}
+
+	// RateLimitError represents the response that is rate limited
+	RateLimitError struct {
+		RetryAfter time.Duration
+	}
+
+	client struct {
+		httpClient func(context.Context) *http.Client
+	}
+)
+
+var (
+	// ErrInvalidWebhook is returned when the webhook is deleted or its token is revoked
+	ErrInvalidWebhook = errors.New("mcz: invalid discord webhook")
+)
+
+// New returns Client that posts messages to Discord webhooks
+func New() Client {
+	if config.C().Discord.Disabled {
+		return NewNop()
+	}
+	return &client{httpClient: urlfetch.Client}
+}
+
+// Error implements error
+func (e *RateLimitError) Error() string {
+	return "mcz: discord rate limited retry after:" + e.RetryAfter.String()
+}
+
+// Post posts message to given webhook url
+func (c *client) Post(ctx context.Context, webhookURL string, msg Message) error {
+	const errTag = "discord.Post failed"
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient(ctx).Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized:
+		return ErrInvalidWebhook
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{RetryAfter: retryAfter(resp)}
+	case resp.StatusCode/100 != 2:
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("%v: status:%v body:%s", errTag, resp.StatusCode, body)
+	}
+	return nil
+}
+
+// retryAfter returns the duration to wait from the rate limited response
+// Retry-After header is in seconds and retry_after in the body may be fractional seconds
+func retryAfter(resp *http.Response) time.Duration {
+	const defaultRetryAfter = 5 * time.Second
+
+	if v, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && v > 0 {
+		return time.Duration(v * float64(time.Second))
+	}
+
+	var body struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024)).Decode(&body); err == nil && body.RetryAfter > 0 {
+		return time.Duration(body.RetryAfter * float64(time.Second))
+	}
+	return defaultRetryAfter
+}
@@ -0,0 +1,59 @@
+package discord
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Post(t *testing.T) {
+	tests := []struct {
+		status     int
+		header     map[string]string
+		body       string
+		expected   error
+		retryAfter time.Duration
+	}{
+		{http.StatusNoContent, nil, "", nil, 0},
+		{http.StatusNotFound, nil, `{"message": "Unknown Webhook", "code": 10015}`, ErrInvalidWebhook, 0},
+		{http.StatusUnauthorized, nil, `{"message": "Invalid Webhook Token", "code": 50027}`, ErrInvalidWebhook, 0},
+		{http.StatusTooManyRequests, map[string]string{"Retry-After": "3"}, `{"retry_after": 3}`, nil, 3 * time.Second},
+		{http.StatusTooManyRequests, nil, `{"retry_after": 1.5}`, nil, 1500 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		var contentType string
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			for k, v := range test.header {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		}))
+
+		c := &client{httpClient: func(context.Context) *http.Client { return http.DefaultClient }}
+		err := c.Post(context.Background(), s.URL, Message{Embeds: []Embed{{Title: "title"}}})
+		s.Close()
+
+		if contentType != "application/json" {
+			t.Errorf("Expected content type application/json, got %v", contentType)
+		}
+		if test.retryAfter > 0 {
+			e, ok := err.(*RateLimitError)
+			if !ok {
+				t.Errorf("Expected rate limit error, got %v", err)
+				continue
+			}
+			if e.RetryAfter != test.retryAfter {
+				t.Errorf("Expected retry after %v, got %v", test.retryAfter, e.RetryAfter)
+			}
+			continue
+		}
+		if err != test.expected {
+			t.Errorf("Expected %v, got %v. status:%v", test.expected, err, test.status)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package discord
+
+import "context"
+
+type nop struct{}
+
+// NewNop returns no operation client
+func NewNop() Client {
+	return &nop{}
+}
+
+func (c *nop) Post(_ context.Context, _ string, _ Message) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package dryrun
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/config"
+)
+
+const (
+	// Header is the http header that carries dry-run mode through the task queue
+	Header = "X-Momoclo-Dry-Run"
+
+	// Param is the query parameter that enables dry-run mode per request (e.g. /cron/crawl?dry_run=1)
+	Param = "dry_run"
+)
+
+type contextKey struct{}
+
+// WithContext returns the context in dry-run mode
+func WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, true)
+}
+
+// Enabled returns true if dry-run mode is enabled by the context or config
+// in dry-run mode, the pipeline records rendered messages as previews instead of delivering them
+func Enabled(ctx context.Context) bool {
+	if c := config.C(); c != nil && c.App.DryRun {
+		return true
+	}
+	v, _ := ctx.Value(contextKey{}).(bool)
+	return v
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/utahta/momoclo-channel/bluesky"
+)
+
+type (
+	// BlueskySession represents the session of Bluesky account
+	// the tokens are encrypted, and the session is shared between task handlers
+	BlueskySession struct {
+		ID              string    `datastore:"-" goon:"id" validate:"required"` // identifier of the account
+		DID             string    `datastore:",noindex"`
+		Handle          string    `datastore:",noindex"`
+		AccessJwtCrypt  string    `datastore:",noindex" validate:"required"`
+		RefreshJwtCrypt string    `datastore:",noindex" validate:"required"`
+		CreatedAt       time.Time `validate:"required"`
+		UpdatedAt       time.Time `validate:"required"`
+	}
+)
+
+// NewBlueskySession returns BlueskySession given key, account identifier and session
+func NewBlueskySession(tokenKey, identifier string, s bluesky.Session) (*BlueskySession, error) {
+	accessJwtCrypt, err := encrypt(tokenKey, s.AccessJwt)
+	if err != nil {
+		return nil, err
+	}
+	refreshJwtCrypt, err := encrypt(tokenKey, s.RefreshJwt)
+	if err != nil {
+		return nil, err
+	}
+	return &BlueskySession{
+		ID:              identifier,
+		DID:             s.DID,
+		Handle:          s.Handle,
+		AccessJwtCrypt:  accessJwtCrypt,
+		RefreshJwtCrypt: refreshJwtCrypt,
+	}, nil
+}
+
+// Session returns decrypted session
+func (b *BlueskySession) Session(tokenKey string) (bluesky.Session, error) {
+	accessJwt, err := decrypt(tokenKey, b.AccessJwtCrypt)
+	if err != nil {
+		return bluesky.Session{}, err
+	}
+	refreshJwt, err := decrypt(tokenKey, b.RefreshJwtCrypt)
+	if err != nil {
+		return bluesky.Session{}, err
+	}
+	return bluesky.Session{DID: b.DID, Handle: b.Handle, AccessJwt: accessJwt, RefreshJwt: refreshJwt}, nil
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (b *BlueskySession) SetCreatedAt(t time.Time) {
+	b.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (b *BlueskySession) GetCreatedAt() time.Time {
+	return b.CreatedAt
+}
+
+// SetUpdatedAt sets given time to UpdatedAt
+func (b *BlueskySession) SetUpdatedAt(t time.Time) {
+	b.UpdatedAt = t
+}
+
+// BeforeSave hook
+func (b *BlueskySession) BeforeSave() {
+	beforeSave(b)
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// BlueskySessionRepository interface
+	BlueskySessionRepository interface {
+		Find(context.Context, string) (*BlueskySession, error)
+		Save(context.Context, *BlueskySession) error
+	}
+
+	// blueskySessionRepository operates BlueskySession entity
+	blueskySessionRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewBlueskySessionRepository returns the BlueskySessionRepository
+func NewBlueskySessionRepository(h dao.PersistenceHandler) BlueskySessionRepository {
+	return &blueskySessionRepository{h}
+}
+
+// Find finds bluesky session given identifier of the account
+func (repo *blueskySessionRepository) Find(ctx context.Context, id string) (*BlueskySession, error) {
+	s := &BlueskySession{ID: id}
+	return s, repo.Get(ctx, s)
+}
+
+// Save saves bluesky session
+func (repo *blueskySessionRepository) Save(ctx context.Context, s *BlueskySession) error {
+	return repo.Put(ctx, s)
+}
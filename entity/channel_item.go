@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+)
+
+type (
+	// ChannelItem represents notification history of the channels that are added by notifier
+	// the id consists of the channel name and the unique url of the entry
+	ChannelItem struct {
+		ID          string    `datastore:"-" goon:"id" validate:"required"`
+		Channel     string    `validate:"required"`
+		Title       string    `validate:"required"`
+		URL         string    `validate:"required,url"`
+		PublishedAt time.Time `validate:"required"`
+		CreatedAt   time.Time `validate:"required"`
+	}
+)
+
+// NewChannelItem returns ChannelItem
+func NewChannelItem(channel string, uniqueURL string, title string, url string, publishedAt time.Time) *ChannelItem {
+	return &ChannelItem{
+		ID:          ChannelItemID(channel, uniqueURL),
+		Channel:     channel,
+		Title:       title,
+		URL:         url,
+		PublishedAt: publishedAt,
+	}
+}
+
+// ChannelItemID returns the id of ChannelItem
+func ChannelItemID(channel string, uniqueURL string) string {
+	return channel + ":" + uniqueURL
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (e *ChannelItem) SetCreatedAt(t time.Time) {
+	e.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (e *ChannelItem) GetCreatedAt() time.Time {
+	return e.CreatedAt
+}
+
+// BeforeSave hook
+func (e *ChannelItem) BeforeSave() {
+	beforeSave(e)
+}
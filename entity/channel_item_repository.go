@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// ChannelItemRepository interface
+	ChannelItemRepository interface {
+		Exists(context.Context, string) bool
+		Find(context.Context, string) (*ChannelItem, error)
+		Save(context.Context, *ChannelItem) error
+	}
+
+	// channelItemRepository operates ChannelItem entity
+	channelItemRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewChannelItemRepository returns the ChannelItemRepository
+func NewChannelItemRepository(h dao.PersistenceHandler) ChannelItemRepository {
+	return &channelItemRepository{h}
+}
+
+// Exists exists channel item
+func (repo *channelItemRepository) Exists(ctx context.Context, id string) bool {
+	_, err := repo.Find(ctx, id)
+	return err == nil
+}
+
+// Find finds channel item given id
+func (repo *channelItemRepository) Find(ctx context.Context, id string) (*ChannelItem, error) {
+	item := &ChannelItem{ID: id}
+	return item, repo.Get(ctx, item)
+}
+
+// Save saves channel item
+func (repo *channelItemRepository) Save(ctx context.Context, item *ChannelItem) error {
+	return repo.Put(ctx, item)
+}
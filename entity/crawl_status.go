@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"time"
+)
+
+type (
+	// CrawlStatus represents crawl health of a feed
+	CrawlStatus struct {
+		ID                  string `datastore:"-" goon:"id" validate:"required"` // feed code
+		LastSucceededAt     time.Time
+		LastError           string `datastore:",noindex"`
+		LastErrorAt         time.Time
+		ConsecutiveFailures int
+		LastItemAt          time.Time // published time of the latest item
+		FailureAlerted      bool
+		StaleAlerted        bool
+		UpdatedAt           time.Time `validate:"required"`
+	}
+)
+
+// NewCrawlStatus returns CrawlStatus given feed code
+func NewCrawlStatus(code string) *CrawlStatus {
+	return &CrawlStatus{ID: code}
+}
+
+// Fail records crawl failure
+func (s *CrawlStatus) Fail(now time.Time, err error) {
+	s.LastError = err.Error()
+	s.LastErrorAt = now
+	s.ConsecutiveFailures++
+}
+
+// Succeed records crawl success
+// lastItemAt is the published time of the latest item, it is ignored if zero or older
+func (s *CrawlStatus) Succeed(now time.Time, lastItemAt time.Time) {
+	s.LastSucceededAt = now
+	s.ConsecutiveFailures = 0
+	if lastItemAt.After(s.LastItemAt) {
+		s.LastItemAt = lastItemAt
+	}
+}
+
+// LastCrawledAt returns the time of the last crawl whether it succeeded or not
+func (s *CrawlStatus) LastCrawledAt() time.Time {
+	if s.LastErrorAt.After(s.LastSucceededAt) {
+		return s.LastErrorAt
+	}
+	return s.LastSucceededAt
+}
+
+// IsStale returns true if the feed has no new items for given duration
+func (s *CrawlStatus) IsStale(now time.Time, d time.Duration) bool {
+	if d <= 0 || s.LastItemAt.IsZero() {
+		return false
+	}
+	return now.Sub(s.LastItemAt) > d
+}
+
+// SetUpdatedAt sets given time to UpdatedAt
+func (s *CrawlStatus) SetUpdatedAt(t time.Time) {
+	s.UpdatedAt = t
+}
+
+// BeforeSave hook
+func (s *CrawlStatus) BeforeSave() {
+	beforeSave(s)
+}
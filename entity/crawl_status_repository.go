@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// CrawlStatusRepository interface
+	CrawlStatusRepository interface {
+		FindOrNew(context.Context, string) (*CrawlStatus, error)
+		Save(context.Context, *CrawlStatus) error
+	}
+
+	crawlStatusRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewCrawlStatusRepository returns the CrawlStatusRepository
+func NewCrawlStatusRepository(h dao.PersistenceHandler) CrawlStatusRepository {
+	return &crawlStatusRepository{h}
+}
+
+// FindOrNew finds CrawlStatus given feed code
+// if not found, returns new CrawlStatus
+func (repo *crawlStatusRepository) FindOrNew(ctx context.Context, code string) (*CrawlStatus, error) {
+	s := NewCrawlStatus(code)
+	err := repo.Get(ctx, s)
+	if err == dao.ErrNoSuchEntity {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save saves CrawlStatus
+func (repo *crawlStatusRepository) Save(ctx context.Context, s *CrawlStatus) error {
+	return repo.Put(ctx, s)
+}
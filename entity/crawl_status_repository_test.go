@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/testutil"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestCrawlStatusRepository_Save(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	repo := NewCrawlStatusRepository(dao.NewDatastoreHandler())
+	s, err := repo.FindOrNew(ctx, crawler.FeedCodeMomota.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ConsecutiveFailures != 0 || !s.LastSucceededAt.IsZero() {
+		t.Errorf("Expected new crawl status, got %v", s)
+	}
+
+	now := time.Now()
+	s.Fail(now, errors.New("fetch failed"))
+	s.Fail(now, errors.New("fetch failed"))
+	if err := repo.Save(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = repo.FindOrNew(ctx, crawler.FeedCodeMomota.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ConsecutiveFailures != 2 {
+		t.Errorf("Expected consecutive failures 2, got %v", s.ConsecutiveFailures)
+	}
+	if s.LastError != "fetch failed" {
+		t.Errorf("Expected last error fetch failed, got %v", s.LastError)
+	}
+	if s.UpdatedAt.IsZero() {
+		t.Error("Expected updated at, got zero")
+	}
+
+	if err := repo.Save(ctx, &CrawlStatus{}); err == nil {
+		t.Error("Expected got error, but nil")
+	}
+}
+
+func TestCrawlStatus_Succeed(t *testing.T) {
+	now := time.Date(2017, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewCrawlStatus(crawler.FeedCodeMomota.String())
+	if s.IsStale(now, time.Hour) {
+		t.Error("Expected not stale if no items, got stale")
+	}
+
+	s.Fail(now, errors.New("fetch failed"))
+	s.Succeed(now, now.Add(-2*time.Hour))
+	if s.ConsecutiveFailures != 0 {
+		t.Errorf("Expected consecutive failures 0, got %v", s.ConsecutiveFailures)
+	}
+	if !s.IsStale(now, time.Hour) {
+		t.Error("Expected stale, got not stale")
+	}
+	if s.IsStale(now, 0) {
+		t.Error("Expected not stale if duration is zero, got stale")
+	}
+
+	s.Succeed(now, time.Time{}) // no items
+	s.Succeed(now, now.Add(-3*time.Hour))
+	if !s.LastItemAt.Equal(now.Add(-2 * time.Hour)) {
+		t.Errorf("Expected last item at is not changed, got %v", s.LastItemAt)
+	}
+
+	s.Succeed(now, now.Add(-time.Minute))
+	if s.IsStale(now, time.Hour) {
+		t.Error("Expected not stale, got stale")
+	}
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// hashString returns hex encoded sha256 of given string
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// encrypt encrypts given text with AES-CTR and returns it hex encoded
+func encrypt(key, text string) (string, error) {
+	textBytes := []byte(text)
+	cipherText := make([]byte, aes.BlockSize+len(textBytes))
+	iv := cipherText[:aes.BlockSize]
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return "", err
+	}
+
+	mode := cipher.NewCTR(block, iv)
+	mode.XORKeyStream(cipherText[aes.BlockSize:], textBytes)
+	return hex.EncodeToString(cipherText), nil
+}
+
+// decrypt decrypts given hex encoded text that is encrypted by encrypt
+func decrypt(key, crypt string) (string, error) {
+	cipherText, err := hex.DecodeString(crypt)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return "", err
+	}
+
+	text := make([]byte, len(cipherText[aes.BlockSize:]))
+	mode := cipher.NewCTR(block, cipherText[:aes.BlockSize])
+	mode.XORKeyStream(text, cipherText[aes.BlockSize:])
+	return string(text), nil
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+)
+
+type (
+	// DiscordWebhook represents webhook urls of Discord servers
+	// the url is encrypted because it contains the token of the webhook
+	DiscordWebhook struct {
+		ID        string    `datastore:"-" goon:"id" validate:"required"`
+		URLCrypt  string    `datastore:",noindex" validate:"required"`
+		CreatedAt time.Time `validate:"required"`
+	}
+)
+
+// NewDiscordWebhook returns DiscordWebhook given key and webhook url
+func NewDiscordWebhook(tokenKey, webhookURL string) (*DiscordWebhook, error) {
+	urlCrypt, err := encrypt(tokenKey, webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	return &DiscordWebhook{ID: hashString(webhookURL), URLCrypt: urlCrypt}, nil
+}
+
+// URL returns decrypted webhook url
+func (d *DiscordWebhook) URL(tokenKey string) (string, error) {
+	return decrypt(tokenKey, d.URLCrypt)
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (d *DiscordWebhook) SetCreatedAt(t time.Time) {
+	d.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (d *DiscordWebhook) GetCreatedAt() time.Time {
+	return d.CreatedAt
+}
+
+// BeforeSave hook
+func (d *DiscordWebhook) BeforeSave() {
+	beforeSave(d)
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// DiscordWebhookRepository interface
+	DiscordWebhookRepository interface {
+		FindAll(context.Context) ([]*DiscordWebhook, error)
+		Save(context.Context, *DiscordWebhook) error
+		Delete(context.Context, string) error
+	}
+
+	// discordWebhookRepository operates DiscordWebhook entity
+	discordWebhookRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewDiscordWebhookRepository returns the DiscordWebhookRepository
+func NewDiscordWebhookRepository(h dao.PersistenceHandler) DiscordWebhookRepository {
+	return &discordWebhookRepository{h}
+}
+
+// FindAll finds all discord webhook entities
+func (repo *discordWebhookRepository) FindAll(ctx context.Context) ([]*DiscordWebhook, error) {
+	kind := repo.Kind(ctx, &DiscordWebhook{})
+	q := repo.NewQuery(kind)
+
+	var dst []*DiscordWebhook
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// Save saves given discord webhook entity
+func (repo *discordWebhookRepository) Save(ctx context.Context, item *DiscordWebhook) error {
+	return repo.Put(ctx, item)
+}
+
+// Delete deletes given discord webhook entity
+func (repo *discordWebhookRepository) Delete(ctx context.Context, id string) error {
+	return repo.PersistenceHandler.Delete(ctx, &DiscordWebhook{ID: id})
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// frequencies of email subscribers
+const (
+	EmailFrequencyImmediate = "immediate"
+	EmailFrequencyDaily     = "daily"
+	EmailFrequencyWeekly    = "weekly"
+)
+
+// actions of the signed links
+const (
+	EmailActionConfirm     = "confirm"
+	EmailActionUnsubscribe = "unsubscribe"
+	EmailActionManage      = "manage"
+)
+
+type (
+	// EmailSubscriber represents a subscriber of email notifications
+	// the subscriber receives mails after the address is confirmed (double opt-in)
+	EmailSubscriber struct {
+		ID        string `datastore:"-" goon:"id" validate:"required"`
+		Email     string `datastore:",noindex" validate:"required,email"`
+		Frequency string `validate:"eq=immediate|eq=daily|eq=weekly"`
+		Confirmed bool
+
+		// DigestedAt is the time that the latest digest covers
+		DigestedAt time.Time `datastore:",noindex"`
+		// LinkSentAt is the time that the latest mail of the confirm or manage link was sent
+		LinkSentAt time.Time `datastore:",noindex"`
+		CreatedAt  time.Time `validate:"required"`
+	}
+)
+
+// NewEmailSubscriber returns unconfirmed EmailSubscriber given address and frequency
+func NewEmailSubscriber(email, frequency string) *EmailSubscriber {
+	return &EmailSubscriber{
+		ID:        hashString(strings.ToLower(email)),
+		Email:     email,
+		Frequency: frequency,
+	}
+}
+
+// Token returns the signature of given action for the signed link
+func (s *EmailSubscriber) Token(signingKey, action string) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(action + ":" + s.ID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyToken returns true if the token is the signature of given action
+func (s *EmailSubscriber) VerifyToken(signingKey, action, token string) bool {
+	return hmac.Equal([]byte(s.Token(signingKey, action)), []byte(token))
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (s *EmailSubscriber) SetCreatedAt(t time.Time) {
+	s.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (s *EmailSubscriber) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// BeforeSave hook
+func (s *EmailSubscriber) BeforeSave() {
+	beforeSave(s)
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// EmailSubscriberRepository interface
+	EmailSubscriberRepository interface {
+		Find(context.Context, string) (*EmailSubscriber, error)
+		FindConfirmed(context.Context, string) ([]*EmailSubscriber, error)
+		Save(context.Context, *EmailSubscriber) error
+		SaveMulti(context.Context, []*EmailSubscriber) error
+		Delete(context.Context, string) error
+	}
+
+	// emailSubscriberRepository operates EmailSubscriber entity
+	emailSubscriberRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewEmailSubscriberRepository returns the EmailSubscriberRepository
+func NewEmailSubscriberRepository(h dao.PersistenceHandler) EmailSubscriberRepository {
+	return &emailSubscriberRepository{h}
+}
+
+// Find finds email subscriber given id
+func (repo *emailSubscriberRepository) Find(ctx context.Context, id string) (*EmailSubscriber, error) {
+	s := &EmailSubscriber{ID: id}
+	return s, repo.Get(ctx, s)
+}
+
+// FindConfirmed finds confirmed email subscribers given frequency
+func (repo *emailSubscriberRepository) FindConfirmed(ctx context.Context, frequency string) ([]*EmailSubscriber, error) {
+	kind := repo.Kind(ctx, &EmailSubscriber{})
+	q := repo.NewQuery(kind).Filter("Frequency =", frequency).Filter("Confirmed =", true)
+
+	var dst []*EmailSubscriber
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// Save saves email subscriber
+func (repo *emailSubscriberRepository) Save(ctx context.Context, s *EmailSubscriber) error {
+	return repo.Put(ctx, s)
+}
+
+// SaveMulti saves email subscribers
+func (repo *emailSubscriberRepository) SaveMulti(ctx context.Context, s []*EmailSubscriber) error {
+	return repo.PutMulti(ctx, s)
+}
+
+// Delete deletes email subscriber given id
+func (repo *emailSubscriberRepository) Delete(ctx context.Context, id string) error {
+	return repo.PersistenceHandler.Delete(ctx, &EmailSubscriber{ID: id})
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+)
+
+type (
+	// FetchCache represents the validators of the last response of a feed
+	FetchCache struct {
+		ID           string    `datastore:"-" goon:"id" validate:"required"` // feed code
+		URL          string    `datastore:",noindex" validate:"required,url"`
+		ETag         string    `datastore:",noindex"`
+		LastModified string    `datastore:",noindex"`
+		LatestURL    string    `datastore:",noindex"` // the latest entry url at the time
+		UpdatedAt    time.Time `validate:"required"`
+	}
+)
+
+// NewFetchCache returns FetchCache given feed code
+func NewFetchCache(code string) *FetchCache {
+	return &FetchCache{ID: code}
+}
+
+// SetUpdatedAt sets given time to UpdatedAt
+func (c *FetchCache) SetUpdatedAt(t time.Time) {
+	c.UpdatedAt = t
+}
+
+// BeforeSave hook
+func (c *FetchCache) BeforeSave() {
+	beforeSave(c)
+}
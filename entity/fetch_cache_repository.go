@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// FetchCacheRepository interface
+	// it implements crawler.FetchCacheRepository
+	FetchCacheRepository interface {
+		Find(context.Context, crawler.FeedCode) (*crawler.FetchCache, error)
+		Save(context.Context, *crawler.FetchCache) error
+	}
+
+	fetchCacheRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewFetchCacheRepository returns the FetchCacheRepository
+func NewFetchCacheRepository(h dao.PersistenceHandler) FetchCacheRepository {
+	return &fetchCacheRepository{h}
+}
+
+// Find finds FetchCache given feed code
+// it returns nil if not found
+func (repo *fetchCacheRepository) Find(ctx context.Context, code crawler.FeedCode) (*crawler.FetchCache, error) {
+	c := NewFetchCache(code.String())
+	err := repo.Get(ctx, c)
+	if err == dao.ErrNoSuchEntity {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &crawler.FetchCache{
+		Code:         code,
+		URL:          c.URL,
+		ETag:         c.ETag,
+		LastModified: c.LastModified,
+		LatestURL:    c.LatestURL,
+	}, nil
+}
+
+// Save saves FetchCache
+func (repo *fetchCacheRepository) Save(ctx context.Context, cache *crawler.FetchCache) error {
+	c := NewFetchCache(cache.Code.String())
+	c.URL = cache.URL
+	c.ETag = cache.ETag
+	c.LastModified = cache.LastModified
+	c.LatestURL = cache.LatestURL
+	return repo.Put(ctx, c)
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/testutil"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestFetchCacheRepository_Save(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	repo := NewFetchCacheRepository(dao.NewDatastoreHandler())
+	cache, err := repo.Find(ctx, crawler.FeedCodeAeNews)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache != nil {
+		t.Errorf("Expected not found, got %v", cache)
+	}
+
+	expected := &crawler.FetchCache{
+		Code:      crawler.FeedCodeAeNews,
+		URL:       "http://localhost/feed",
+		ETag:      `"v1"`,
+		LatestURL: "http://localhost/entry/1",
+	}
+	if err := repo.Save(ctx, expected); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err = repo.Find(ctx, crawler.FeedCodeAeNews)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache == nil || *cache != *expected {
+		t.Errorf("Expected %v, got %v", expected, cache)
+	}
+
+	// the url is required
+	if err := repo.Save(ctx, &crawler.FetchCache{Code: crawler.FeedCodeAeNews}); err == nil {
+		t.Error("Expected got error, but nil")
+	}
+}
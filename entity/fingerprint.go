@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Fingerprint returns digest of the entry contents that are notified
+func Fingerprint(title string, imageURLs []string, videoURLs []string) string {
+	s := strings.Join([]string{
+		title,
+		strings.Join(imageURLs, ","),
+		strings.Join(videoURLs, ","),
+	}, "\n")
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// splitURLs splits urls joined by comma
+func splitURLs(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
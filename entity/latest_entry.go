@@ -11,6 +11,7 @@ type (
 		Code        string    `validate:"required"`
 		URL         string    `validate:"required,url"`
 		PublishedAt time.Time `validate:"required"`
+		Fingerprint string    `datastore:",noindex"`
 		CreatedAt   time.Time `validate:"required"`
 		UpdatedAt   time.Time `validate:"required"`
 	}
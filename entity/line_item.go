@@ -14,6 +14,7 @@ type (
 		PublishedAt time.Time `validate:"required"`
 		ImageURLs   string    `datastore:",noindex"`
 		VideoURLs   string    `datastore:",noindex"`
+		Fingerprint string    `datastore:",noindex"`
 		CreatedAt   time.Time `validate:"required"`
 	}
 )
@@ -27,9 +28,40 @@ func NewLineItem(id string, title string, url string, publishedAt time.Time, ima
 		PublishedAt: publishedAt,
 		ImageURLs:   strings.Join(imageURLs, ","),
 		VideoURLs:   strings.Join(videoURLs, ","),
+		Fingerprint: Fingerprint(title, imageURLs, videoURLs),
 	}
 }
 
+// SplitImageURLs returns image urls as slice
+func (e *LineItem) SplitImageURLs() []string {
+	return splitURLs(e.ImageURLs)
+}
+
+// SplitVideoURLs returns video urls as slice
+func (e *LineItem) SplitVideoURLs() []string {
+	return splitURLs(e.VideoURLs)
+}
+
+// SetID sets given id to ID
+func (e *LineItem) SetID(id string) {
+	e.ID = id
+}
+
+// GetID gets ID
+func (e *LineItem) GetID() string {
+	return e.ID
+}
+
+// GetTitle gets Title
+func (e *LineItem) GetTitle() string {
+	return e.Title
+}
+
+// GetFingerprint gets Fingerprint
+func (e *LineItem) GetFingerprint() string {
+	return e.Fingerprint
+}
+
 // SetCreatedAt sets given time to CreatedAt
 func (e *LineItem) SetCreatedAt(t time.Time) {
 	e.CreatedAt = t
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"context"
+	"time"
 
 	"github.com/utahta/momoclo-channel/dao"
 )
@@ -11,7 +12,11 @@ type (
 	LineItemRepository interface {
 		Exists(context.Context, string) bool
 		Find(context.Context, string) (*LineItem, error)
+		FindMulti(context.Context, []string) ([]*LineItem, error)
+		FindSince(context.Context, time.Time) ([]*LineItem, error)
+		FindRecent(context.Context, int) ([]*LineItem, error)
 		Save(context.Context, *LineItem) error
+		Delete(context.Context, string) error
 	}
 
 	// LineItemRepository operates LineItem entity
@@ -37,7 +42,56 @@ func (repo *lineItemRepository) Find(ctx context.Context, id string) (*LineItem,
 	return item, repo.Get(ctx, item)
 }
 
+// FindSince finds line items that are created after given time in order of creation
+func (repo *lineItemRepository) FindSince(ctx context.Context, t time.Time) ([]*LineItem, error) {
+	kind := repo.Kind(ctx, &LineItem{})
+	q := repo.NewQuery(kind).Filter("CreatedAt >", t).Order("CreatedAt")
+
+	var dst []*LineItem
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// FindRecent finds the most recent line items up to given limit
+func (repo *lineItemRepository) FindRecent(ctx context.Context, limit int) ([]*LineItem, error) {
+	kind := repo.Kind(ctx, &LineItem{})
+	q := repo.NewQuery(kind).Order("-CreatedAt").Limit(limit)
+
+	var dst []*LineItem
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// FindMulti finds line items given ids in the same order, the items that do not exist are skipped
+func (repo *lineItemRepository) FindMulti(ctx context.Context, ids []string) ([]*LineItem, error) {
+	items := make([]*LineItem, len(ids))
+	for i, id := range ids {
+		items[i] = &LineItem{ID: id}
+	}
+
+	err := repo.GetMulti(ctx, items)
+	errs, ok := err.(dao.MultiError)
+	if err != nil && !ok {
+		return nil, err
+	}
+
+	var dst []*LineItem
+	for i, item := range items {
+		if ok && errs[i] == dao.ErrNoSuchEntity {
+			continue
+		}
+		if ok && errs[i] != nil {
+			return nil, errs[i]
+		}
+		dst = append(dst, item)
+	}
+	return dst, nil
+}
+
 // Save saves line item
 func (repo *lineItemRepository) Save(ctx context.Context, item *LineItem) error {
 	return repo.Put(ctx, item)
 }
+
+// Delete deletes line item given id
+func (repo *lineItemRepository) Delete(ctx context.Context, id string) error {
+	return repo.PersistenceHandler.Delete(ctx, &LineItem{ID: id})
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -65,3 +66,59 @@ func TestLineItemRepository_Tx(t *testing.T) {
 		t.Errorf("Expected title z, got %v", v.Title)
 	}
 }
+
+func TestLineItemRepository_FindSince(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	repo := NewLineItemRepository(dao.NewDatastoreHandler())
+	now := time.Now()
+	for i, d := range []time.Duration{-48 * time.Hour, -2 * time.Hour, -1 * time.Hour} {
+		item := NewLineItem(fmt.Sprintf("id-%d", i), "title", "http://localhost/", now, nil, nil)
+		item.CreatedAt = now.Add(d)
+		if err := repo.Save(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := repo.FindSince(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected items length 2, got %v", len(items))
+	}
+	if items[0].ID != "id-1" || items[1].ID != "id-2" {
+		t.Errorf("Expected items in order of creation, got %v %v", items[0].ID, items[1].ID)
+	}
+}
+
+func TestLineItemRepository_FindMulti(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	repo := NewLineItemRepository(dao.NewDatastoreHandler())
+	for _, id := range []string{"id-1", "id-3"} {
+		if err := repo.Save(ctx, NewLineItem(id, "title", "http://localhost/", time.Now(), nil, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// missing items are skipped
+	items, err := repo.FindMulti(ctx, []string{"id-3", "id-2", "id-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected items length 2, got %v", len(items))
+	}
+	if items[0].ID != "id-3" || items[1].ID != "id-1" {
+		t.Errorf("Expected items in order of ids, got %v %v", items[0].ID, items[1].ID)
+	}
+}
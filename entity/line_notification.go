@@ -1,11 +1,10 @@
 package entity
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"strings"
 	"time"
 )
 
@@ -16,54 +15,91 @@ type (
 		ID         string `datastore:"-" goon:"id" validate:"required"`
 		TokenCrypt string `datastore:",noindex" validate:"required"`
 		Admin      bool
-		CreatedAt  time.Time `validate:"required"`
+		UserHash   string // hash of the LINE bot user who connected the token, empty if unknown
+
+		// preferences of the subscriber, everything is delivered by default
+		Codes        string `datastore:",noindex"` // comma separated feed codes to deliver, all if empty
+		Members      string `datastore:",noindex"` // comma separated member names that the entries relate to, all if empty
+		MuteUstream  bool   `datastore:",noindex"`
+		MuteReminder bool   `datastore:",noindex"`
+
+		CreatedAt time.Time `validate:"required"`
 	}
 )
 
 // NewLineNotification returns LineNotification given key and token
 func NewLineNotification(tokenKey, token string) (*LineNotification, error) {
-	tokenBytes := []byte(token)
-	tokenSum := sha256.Sum256(tokenBytes)
-	tokenHash := hex.EncodeToString(tokenSum[:])
-
-	// Encrypt
-	cipherText := make([]byte, aes.BlockSize+len(tokenBytes))
-	iv := cipherText[:aes.BlockSize]
-	if _, err := rand.Read(iv); err != nil {
-		return nil, err
-	}
-
-	key := []byte(tokenKey)
-	block, err := aes.NewCipher(key)
+	tokenCrypt, err := encrypt(tokenKey, token)
 	if err != nil {
 		return nil, err
 	}
+	return &LineNotification{ID: hashString(token), TokenCrypt: tokenCrypt}, nil
+}
+
+// LineUserHash returns the hash of given LINE bot user id
+func LineUserHash(userID string) string {
+	return hashString(userID)
+}
 
-	mode := cipher.NewCTR(block, iv)
-	mode.XORKeyStream(cipherText[aes.BlockSize:], tokenBytes)
-	tokenCrypt := hex.EncodeToString(cipherText)
+// LineUserToken returns the signature of given user hash for the connection link
+func LineUserToken(signingKey, userHash string) string {
+	return sign(signingKey, "user:"+userHash)
+}
 
-	return &LineNotification{ID: tokenHash, TokenCrypt: tokenCrypt}, nil
+// VerifyLineUserToken returns true if the token is the signature of given user hash
+func VerifyLineUserToken(signingKey, userHash, token string) bool {
+	return hmac.Equal([]byte(LineUserToken(signingKey, userHash)), []byte(token))
 }
 
 // Token returns decrypted token
 func (l *LineNotification) Token(tokenKey string) (string, error) {
-	// Decrypt
-	cipherText, err := hex.DecodeString(l.TokenCrypt)
-	if err != nil {
-		return "", err
-	}
+	return decrypt(tokenKey, l.TokenCrypt)
+}
 
-	key := []byte(tokenKey)
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
-	}
+// PreferencesToken returns the signature for the link of preferences page
+func (l *LineNotification) PreferencesToken(signingKey string) string {
+	return sign(signingKey, "preferences:"+l.ID)
+}
+
+// VerifyPreferencesToken returns true if the token is the signature for the link of preferences page
+func (l *LineNotification) VerifyPreferencesToken(signingKey, token string) bool {
+	return hmac.Equal([]byte(l.PreferencesToken(signingKey)), []byte(token))
+}
+
+// SplitCodes returns the feed codes to deliver
+func (l *LineNotification) SplitCodes() []string {
+	return splitList(l.Codes)
+}
+
+// SplitMembers returns the member names to deliver
+func (l *LineNotification) SplitMembers() []string {
+	return splitList(l.Members)
+}
+
+// SetCodes sets given feed codes, all feeds are delivered if empty
+func (l *LineNotification) SetCodes(codes []string) {
+	l.Codes = strings.Join(codes, ",")
+}
 
-	token := make([]byte, len(cipherText[aes.BlockSize:]))
-	mode := cipher.NewCTR(block, cipherText[:aes.BlockSize])
-	mode.XORKeyStream(token, cipherText[aes.BlockSize:])
-	return string(token), nil
+// SetMembers sets given member names, all entries are delivered if empty
+func (l *LineNotification) SetMembers(members []string) {
+	l.Members = strings.Join(members, ",")
+}
+
+// AcceptsEntry returns true if the subscriber receives the entry of given feed code that relates to given members
+func (l *LineNotification) AcceptsEntry(code string, members []string) bool {
+	if l.Codes != "" && !containsString(l.SplitCodes(), code) {
+		return false
+	}
+	if l.Members == "" {
+		return true
+	}
+	for _, m := range members {
+		if containsString(l.SplitMembers(), m) {
+			return true
+		}
+	}
+	return false
 }
 
 // SetCreatedAt sets given time to CreatedAt
@@ -80,3 +116,25 @@ func (l *LineNotification) GetCreatedAt() time.Time {
 func (l *LineNotification) BeforeSave() {
 	beforeSave(l)
 }
+
+func sign(key, s string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -9,7 +9,10 @@ import (
 type (
 	// LineNotificationRepository interface
 	LineNotificationRepository interface {
+		Find(context.Context, string) (*LineNotification, error)
 		FindAll(context.Context) ([]*LineNotification, error)
+		FindAdmins(context.Context) ([]*LineNotification, error)
+		FindByUserHash(context.Context, string) ([]*LineNotification, error)
 		Save(context.Context, *LineNotification) error
 		Delete(context.Context, string) error
 	}
@@ -25,6 +28,12 @@ func NewLineNotificationRepository(h dao.PersistenceHandler) LineNotificationRep
 	return &lineNotificationRepository{h}
 }
 
+// Find finds line notification entity given id
+func (repo *lineNotificationRepository) Find(ctx context.Context, id string) (*LineNotification, error) {
+	l := &LineNotification{ID: id}
+	return l, repo.Get(ctx, l)
+}
+
 // FindAll finds all line notification entities
 func (repo *lineNotificationRepository) FindAll(ctx context.Context) ([]*LineNotification, error) {
 	kind := repo.Kind(ctx, &LineNotification{})
@@ -34,6 +43,24 @@ func (repo *lineNotificationRepository) FindAll(ctx context.Context) ([]*LineNot
 	return dst, repo.GetAll(ctx, q, &dst)
 }
 
+// FindAdmins finds line notification entities that flagged admin
+func (repo *lineNotificationRepository) FindAdmins(ctx context.Context) ([]*LineNotification, error) {
+	kind := repo.Kind(ctx, &LineNotification{})
+	q := repo.NewQuery(kind).Filter("Admin =", true)
+
+	var dst []*LineNotification
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// FindByUserHash finds line notification entities that connected by given LINE bot user
+func (repo *lineNotificationRepository) FindByUserHash(ctx context.Context, userHash string) ([]*LineNotification, error) {
+	kind := repo.Kind(ctx, &LineNotification{})
+	q := repo.NewQuery(kind).Filter("UserHash =", userHash)
+
+	var dst []*LineNotification
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
 // Save saves given line notification entity
 func (repo *lineNotificationRepository) Save(ctx context.Context, item *LineNotification) error {
 	return repo.Put(ctx, item)
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	PreviewChannelTwitter = "twitter"
+	PreviewChannelLine    = "line"
+)
+
+type (
+	// Preview represents rendered messages that are recorded in dry-run mode instead of delivering
+	Preview struct {
+		ID        int64     `datastore:"-" goon:"id"`
+		Channel   string    `validate:"required"`
+		Payload   string    `datastore:",noindex" validate:"required"` // JSON of the rendered messages
+		CreatedAt time.Time `validate:"required"`
+	}
+)
+
+// NewPreview returns Preview given channel and payload
+func NewPreview(channel string, payload string) *Preview {
+	return &Preview{
+		Channel: channel,
+		Payload: payload,
+	}
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (p *Preview) SetCreatedAt(t time.Time) {
+	p.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (p *Preview) GetCreatedAt() time.Time {
+	return p.CreatedAt
+}
+
+// BeforeSave hook
+func (p *Preview) BeforeSave() {
+	beforeSave(p)
+}
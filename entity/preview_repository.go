@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// PreviewRepository interface
+	PreviewRepository interface {
+		Save(context.Context, *Preview) error
+		SaveMessages(context.Context, string, interface{}) error
+		FindRecent(context.Context, int) ([]*Preview, error)
+	}
+
+	previewRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewPreviewRepository returns the PreviewRepository
+func NewPreviewRepository(h dao.PersistenceHandler) PreviewRepository {
+	return &previewRepository{h}
+}
+
+// Save saves Preview
+func (repo *previewRepository) Save(ctx context.Context, p *Preview) error {
+	return repo.Put(ctx, p)
+}
+
+// SaveMessages saves given messages as Preview of the channel
+func (repo *previewRepository) SaveMessages(ctx context.Context, channel string, messages interface{}) error {
+	b, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	return repo.Save(ctx, NewPreview(channel, string(b)))
+}
+
+// FindRecent finds the most recent previews up to given limit
+func (repo *previewRepository) FindRecent(ctx context.Context, limit int) ([]*Preview, error) {
+	kind := repo.Kind(ctx, &Preview{})
+	q := repo.NewQuery(kind).Order("-CreatedAt").Limit(limit)
+
+	var dst []*Preview
+	return dst, repo.GetAll(ctx, q, &dst)
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+type (
+	// SlackWebhook represents incoming webhook urls of Slack workspaces
+	// the url is encrypted because it contains the token of the webhook
+	SlackWebhook struct {
+		ID        string    `datastore:"-" goon:"id" validate:"required"`
+		URLCrypt  string    `datastore:",noindex" validate:"required"`
+		Codes     string    `datastore:",noindex"` // comma separated feed codes or notification kinds to deliver, all if empty
+		CreatedAt time.Time `validate:"required"`
+	}
+)
+
+// NewSlackWebhook returns SlackWebhook given key, webhook url and filter codes
+func NewSlackWebhook(tokenKey, webhookURL string, codes []string) (*SlackWebhook, error) {
+	urlCrypt, err := encrypt(tokenKey, webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	return &SlackWebhook{ID: hashString(webhookURL), URLCrypt: urlCrypt, Codes: strings.Join(codes, ",")}, nil
+}
+
+// URL returns decrypted webhook url
+func (s *SlackWebhook) URL(tokenKey string) (string, error) {
+	return decrypt(tokenKey, s.URLCrypt)
+}
+
+// Accepts returns true if the webhook delivers given feed code or notification kind
+func (s *SlackWebhook) Accepts(code string) bool {
+	if s.Codes == "" {
+		return true
+	}
+	for _, v := range strings.Split(s.Codes, ",") {
+		if v == code {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (s *SlackWebhook) SetCreatedAt(t time.Time) {
+	s.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (s *SlackWebhook) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// BeforeSave hook
+func (s *SlackWebhook) BeforeSave() {
+	beforeSave(s)
+}
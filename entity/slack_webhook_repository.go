@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// SlackWebhookRepository interface
+	SlackWebhookRepository interface {
+		FindAll(context.Context) ([]*SlackWebhook, error)
+		Save(context.Context, *SlackWebhook) error
+		Delete(context.Context, string) error
+	}
+
+	// slackWebhookRepository operates SlackWebhook entity
+	slackWebhookRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewSlackWebhookRepository returns the SlackWebhookRepository
+func NewSlackWebhookRepository(h dao.PersistenceHandler) SlackWebhookRepository {
+	return &slackWebhookRepository{h}
+}
+
+// FindAll finds all slack webhook entities
+func (repo *slackWebhookRepository) FindAll(ctx context.Context) ([]*SlackWebhook, error) {
+	kind := repo.Kind(ctx, &SlackWebhook{})
+	q := repo.NewQuery(kind)
+
+	var dst []*SlackWebhook
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// Save saves given slack webhook entity
+func (repo *slackWebhookRepository) Save(ctx context.Context, item *SlackWebhook) error {
+	return repo.Put(ctx, item)
+}
+
+// Delete deletes given slack webhook entity
+func (repo *slackWebhookRepository) Delete(ctx context.Context, id string) error {
+	return repo.PersistenceHandler.Delete(ctx, &SlackWebhook{ID: id})
+}
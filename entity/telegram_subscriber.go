@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"strconv"
+	"time"
+)
+
+type (
+	// TelegramSubscriber represents a chat that subscribes the bot by /start command
+	TelegramSubscriber struct {
+		ID        string    `datastore:"-" goon:"id" validate:"required"`
+		ChatID    int64     `datastore:",noindex" validate:"required"`
+		CreatedAt time.Time `validate:"required"`
+	}
+)
+
+// NewTelegramSubscriber returns TelegramSubscriber given chat id
+func NewTelegramSubscriber(chatID int64) *TelegramSubscriber {
+	return &TelegramSubscriber{ID: TelegramSubscriberID(chatID), ChatID: chatID}
+}
+
+// TelegramSubscriberID returns the id of subscriber given chat id
+func TelegramSubscriberID(chatID int64) string {
+	return strconv.FormatInt(chatID, 10)
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (s *TelegramSubscriber) SetCreatedAt(t time.Time) {
+	s.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (s *TelegramSubscriber) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// BeforeSave hook
+func (s *TelegramSubscriber) BeforeSave() {
+	beforeSave(s)
+}
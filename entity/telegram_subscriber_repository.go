@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// TelegramSubscriberRepository interface
+	TelegramSubscriberRepository interface {
+		FindAll(context.Context) ([]*TelegramSubscriber, error)
+		Save(context.Context, *TelegramSubscriber) error
+		Delete(context.Context, string) error
+	}
+
+	// telegramSubscriberRepository operates TelegramSubscriber entity
+	telegramSubscriberRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewTelegramSubscriberRepository returns the TelegramSubscriberRepository
+func NewTelegramSubscriberRepository(h dao.PersistenceHandler) TelegramSubscriberRepository {
+	return &telegramSubscriberRepository{h}
+}
+
+// FindAll finds all telegram subscribers
+func (repo *telegramSubscriberRepository) FindAll(ctx context.Context) ([]*TelegramSubscriber, error) {
+	kind := repo.Kind(ctx, &TelegramSubscriber{})
+	q := repo.NewQuery(kind)
+
+	var dst []*TelegramSubscriber
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// Save saves telegram subscriber
+func (repo *telegramSubscriberRepository) Save(ctx context.Context, s *TelegramSubscriber) error {
+	return repo.Put(ctx, s)
+}
+
+// Delete deletes telegram subscriber given id
+func (repo *telegramSubscriberRepository) Delete(ctx context.Context, id string) error {
+	return repo.PersistenceHandler.Delete(ctx, &TelegramSubscriber{ID: id})
+}
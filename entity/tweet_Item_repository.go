@@ -11,7 +11,9 @@ type (
 	TweetItemRepository interface {
 		Exists(context.Context, string) bool
 		Find(context.Context, string) (*TweetItem, error)
+		FindMulti(context.Context, []string) ([]*TweetItem, error)
 		Save(context.Context, *TweetItem) error
+		Delete(context.Context, string) error
 	}
 
 	tweetItemRepository struct {
@@ -36,7 +38,38 @@ func (repo *tweetItemRepository) Find(ctx context.Context, id string) (*TweetIte
 	return item, repo.Get(ctx, item)
 }
 
+// FindMulti finds tweet items given ids in the same order, the items that do not exist are skipped
+func (repo *tweetItemRepository) FindMulti(ctx context.Context, ids []string) ([]*TweetItem, error) {
+	items := make([]*TweetItem, len(ids))
+	for i, id := range ids {
+		items[i] = &TweetItem{ID: id}
+	}
+
+	err := repo.GetMulti(ctx, items)
+	errs, ok := err.(dao.MultiError)
+	if err != nil && !ok {
+		return nil, err
+	}
+
+	var dst []*TweetItem
+	for i, item := range items {
+		if ok && errs[i] == dao.ErrNoSuchEntity {
+			continue
+		}
+		if ok && errs[i] != nil {
+			return nil, errs[i]
+		}
+		dst = append(dst, item)
+	}
+	return dst, nil
+}
+
 // Save saves tweet item
 func (repo *tweetItemRepository) Save(ctx context.Context, item *TweetItem) error {
 	return repo.Put(ctx, item)
 }
+
+// Delete deletes tweet item given id
+func (repo *tweetItemRepository) Delete(ctx context.Context, id string) error {
+	return repo.PersistenceHandler.Delete(ctx, &TweetItem{ID: id})
+}
@@ -14,6 +14,7 @@ type (
 		PublishedAt time.Time `validate:"required"`
 		ImageURLs   string    `datastore:",noindex"`
 		VideoURLs   string    `datastore:",noindex"`
+		Fingerprint string    `datastore:",noindex"`
 		CreatedAt   time.Time `validate:"required"`
 	}
 )
@@ -27,9 +28,40 @@ func NewTweetItem(id string, title string, url string, publishedAt time.Time, im
 		PublishedAt: publishedAt,
 		ImageURLs:   strings.Join(imageURLs, ","),
 		VideoURLs:   strings.Join(videoURLs, ","),
+		Fingerprint: Fingerprint(title, imageURLs, videoURLs),
 	}
 }
 
+// SplitImageURLs returns image urls as slice
+func (e *TweetItem) SplitImageURLs() []string {
+	return splitURLs(e.ImageURLs)
+}
+
+// SplitVideoURLs returns video urls as slice
+func (e *TweetItem) SplitVideoURLs() []string {
+	return splitURLs(e.VideoURLs)
+}
+
+// SetID sets given id to ID
+func (e *TweetItem) SetID(id string) {
+	e.ID = id
+}
+
+// GetID gets ID
+func (e *TweetItem) GetID() string {
+	return e.ID
+}
+
+// GetTitle gets Title
+func (e *TweetItem) GetTitle() string {
+	return e.Title
+}
+
+// GetFingerprint gets Fingerprint
+func (e *TweetItem) GetFingerprint() string {
+	return e.Fingerprint
+}
+
 // SetCreatedAt sets given time to CreatedAt
 func (e *TweetItem) SetCreatedAt(t time.Time) {
 	e.CreatedAt = t
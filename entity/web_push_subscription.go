@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/utahta/momoclo-channel/webpush"
+)
+
+type (
+	// WebPushSubscription represents PushSubscription of a browser
+	// the endpoint and the auth secret are encrypted
+	WebPushSubscription struct {
+		ID            string    `datastore:"-" goon:"id" validate:"required"`
+		EndpointCrypt string    `datastore:",noindex" validate:"required"`
+		P256dh        string    `datastore:",noindex" validate:"required"`
+		AuthCrypt     string    `datastore:",noindex" validate:"required"`
+		CreatedAt     time.Time `validate:"required"`
+	}
+)
+
+// NewWebPushSubscription returns WebPushSubscription given key and subscription
+func NewWebPushSubscription(tokenKey string, s webpush.Subscription) (*WebPushSubscription, error) {
+	endpointCrypt, err := encrypt(tokenKey, s.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	authCrypt, err := encrypt(tokenKey, s.Keys.Auth)
+	if err != nil {
+		return nil, err
+	}
+	return &WebPushSubscription{
+		ID:            WebPushSubscriptionID(s.Endpoint),
+		EndpointCrypt: endpointCrypt,
+		P256dh:        s.Keys.P256dh,
+		AuthCrypt:     authCrypt,
+	}, nil
+}
+
+// WebPushSubscriptionID returns the id of subscription given endpoint
+func WebPushSubscriptionID(endpoint string) string {
+	return hashString(endpoint)
+}
+
+// Subscription returns decrypted subscription
+func (s *WebPushSubscription) Subscription(tokenKey string) (webpush.Subscription, error) {
+	endpoint, err := decrypt(tokenKey, s.EndpointCrypt)
+	if err != nil {
+		return webpush.Subscription{}, err
+	}
+	auth, err := decrypt(tokenKey, s.AuthCrypt)
+	if err != nil {
+		return webpush.Subscription{}, err
+	}
+	return webpush.Subscription{
+		Endpoint: endpoint,
+		Keys:     webpush.Keys{P256dh: s.P256dh, Auth: auth},
+	}, nil
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (s *WebPushSubscription) SetCreatedAt(t time.Time) {
+	s.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (s *WebPushSubscription) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// BeforeSave hook
+func (s *WebPushSubscription) BeforeSave() {
+	beforeSave(s)
+}
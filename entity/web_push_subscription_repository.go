@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// WebPushSubscriptionRepository interface
+	WebPushSubscriptionRepository interface {
+		FindAll(context.Context) ([]*WebPushSubscription, error)
+		Save(context.Context, *WebPushSubscription) error
+		Delete(context.Context, string) error
+	}
+
+	// webPushSubscriptionRepository operates WebPushSubscription entity
+	webPushSubscriptionRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewWebPushSubscriptionRepository returns the WebPushSubscriptionRepository
+func NewWebPushSubscriptionRepository(h dao.PersistenceHandler) WebPushSubscriptionRepository {
+	return &webPushSubscriptionRepository{h}
+}
+
+// FindAll finds all web push subscriptions
+func (repo *webPushSubscriptionRepository) FindAll(ctx context.Context) ([]*WebPushSubscription, error) {
+	kind := repo.Kind(ctx, &WebPushSubscription{})
+	q := repo.NewQuery(kind)
+
+	var dst []*WebPushSubscription
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// Save saves web push subscription
+func (repo *webPushSubscriptionRepository) Save(ctx context.Context, s *WebPushSubscription) error {
+	return repo.Put(ctx, s)
+}
+
+// Delete deletes web push subscription given id
+func (repo *webPushSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	return repo.PersistenceHandler.Delete(ctx, &WebPushSubscription{ID: id})
+}
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"time"
+)
+
+// statuses of webhook deliveries
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type (
+	// WebhookDelivery represents a delivery of an event to the webhook subscriber
+	WebhookDelivery struct {
+		ID           string    `datastore:"-" goon:"id" validate:"required"`
+		SubscriberID string    `validate:"required"`
+		Event        string    `validate:"required"`
+		Payload      string    `datastore:",noindex" validate:"required"`
+		Status       string    `validate:"eq=pending|eq=succeeded|eq=failed"`
+		Attempts     int       `datastore:",noindex"`
+		StatusCode   int       `datastore:",noindex"` // status code of the last attempt
+		Error        string    `datastore:",noindex"` // error of the last attempt
+		CreatedAt    time.Time `validate:"required"`
+		UpdatedAt    time.Time `validate:"required"`
+	}
+)
+
+// NewWebhookDelivery returns pending WebhookDelivery given id, subscriber id, event and payload
+func NewWebhookDelivery(id, subscriberID, event, payload string) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:           id,
+		SubscriberID: subscriberID,
+		Event:        event,
+		Payload:      payload,
+		Status:       WebhookDeliveryPending,
+	}
+}
+
+// NewWebhookDeliveryID returns a random id of delivery
+func NewWebhookDeliveryID() (string, error) {
+	return randomString(16)
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (d *WebhookDelivery) SetCreatedAt(t time.Time) {
+	d.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (d *WebhookDelivery) GetCreatedAt() time.Time {
+	return d.CreatedAt
+}
+
+// SetUpdatedAt sets given time to UpdatedAt
+func (d *WebhookDelivery) SetUpdatedAt(t time.Time) {
+	d.UpdatedAt = t
+}
+
+// BeforeSave hook
+func (d *WebhookDelivery) BeforeSave() {
+	beforeSave(d)
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// WebhookDeliveryRepository interface
+	WebhookDeliveryRepository interface {
+		Find(context.Context, string) (*WebhookDelivery, error)
+		FindRecent(context.Context, string, int) ([]*WebhookDelivery, error)
+		Save(context.Context, *WebhookDelivery) error
+		SaveMulti(context.Context, []*WebhookDelivery) error
+	}
+
+	// webhookDeliveryRepository operates WebhookDelivery entity
+	webhookDeliveryRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewWebhookDeliveryRepository returns the WebhookDeliveryRepository
+func NewWebhookDeliveryRepository(h dao.PersistenceHandler) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{h}
+}
+
+// Find finds webhook delivery given id
+func (repo *webhookDeliveryRepository) Find(ctx context.Context, id string) (*WebhookDelivery, error) {
+	d := &WebhookDelivery{ID: id}
+	return d, repo.Get(ctx, d)
+}
+
+// FindRecent finds the most recent deliveries of the subscriber up to given limit
+// it finds deliveries of all subscribers if subscriber id is empty
+func (repo *webhookDeliveryRepository) FindRecent(ctx context.Context, subscriberID string, limit int) ([]*WebhookDelivery, error) {
+	kind := repo.Kind(ctx, &WebhookDelivery{})
+	q := repo.NewQuery(kind)
+	if subscriberID != "" {
+		q = q.Filter("SubscriberID =", subscriberID)
+	}
+	q = q.Order("-CreatedAt").Limit(limit)
+
+	var dst []*WebhookDelivery
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// Save saves webhook delivery
+func (repo *webhookDeliveryRepository) Save(ctx context.Context, d *WebhookDelivery) error {
+	return repo.Put(ctx, d)
+}
+
+// SaveMulti saves webhook deliveries
+func (repo *webhookDeliveryRepository) SaveMulti(ctx context.Context, d []*WebhookDelivery) error {
+	return repo.PutMulti(ctx, d)
+}
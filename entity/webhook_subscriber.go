@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+type (
+	// WebhookSubscriber represents an endpoint of third-party integration that receives signed events
+	// the secret is encrypted, and the subscriber is disabled after repeated failures
+	WebhookSubscriber struct {
+		ID          string `datastore:"-" goon:"id" validate:"required"`
+		URL         string `datastore:",noindex" validate:"required,url"`
+		SecretCrypt string `datastore:",noindex" validate:"required"`
+		Disabled    bool
+		Failures    int       `datastore:",noindex"` // the number of consecutive failed deliveries
+		CreatedAt   time.Time `validate:"required"`
+		UpdatedAt   time.Time `validate:"required"`
+	}
+)
+
+// NewWebhookSubscriber returns WebhookSubscriber given key and url with a new secret
+func NewWebhookSubscriber(tokenKey, url string) (*WebhookSubscriber, error) {
+	secret, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	secretCrypt, err := encrypt(tokenKey, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookSubscriber{ID: hashString(url), URL: url, SecretCrypt: secretCrypt}, nil
+}
+
+// Secret returns decrypted secret to sign requests
+func (s *WebhookSubscriber) Secret(tokenKey string) (string, error) {
+	return decrypt(tokenKey, s.SecretCrypt)
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (s *WebhookSubscriber) SetCreatedAt(t time.Time) {
+	s.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (s *WebhookSubscriber) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// SetUpdatedAt sets given time to UpdatedAt
+func (s *WebhookSubscriber) SetUpdatedAt(t time.Time) {
+	s.UpdatedAt = t
+}
+
+// BeforeSave hook
+func (s *WebhookSubscriber) BeforeSave() {
+	beforeSave(s)
+}
+
+// randomString returns hex encoded random bytes of given length
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// WebhookSubscriberRepository interface
+	WebhookSubscriberRepository interface {
+		Find(context.Context, string) (*WebhookSubscriber, error)
+		FindEnabled(context.Context) ([]*WebhookSubscriber, error)
+		Save(context.Context, *WebhookSubscriber) error
+	}
+
+	// webhookSubscriberRepository operates WebhookSubscriber entity
+	webhookSubscriberRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewWebhookSubscriberRepository returns the WebhookSubscriberRepository
+func NewWebhookSubscriberRepository(h dao.PersistenceHandler) WebhookSubscriberRepository {
+	return &webhookSubscriberRepository{h}
+}
+
+// Find finds webhook subscriber given id
+func (repo *webhookSubscriberRepository) Find(ctx context.Context, id string) (*WebhookSubscriber, error) {
+	s := &WebhookSubscriber{ID: id}
+	return s, repo.Get(ctx, s)
+}
+
+// FindEnabled finds webhook subscribers that are not disabled
+func (repo *webhookSubscriberRepository) FindEnabled(ctx context.Context) ([]*WebhookSubscriber, error) {
+	kind := repo.Kind(ctx, &WebhookSubscriber{})
+	q := repo.NewQuery(kind).Filter("Disabled =", false)
+
+	var dst []*WebhookSubscriber
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// Save saves webhook subscriber
+func (repo *webhookSubscriberRepository) Save(ctx context.Context, s *WebhookSubscriber) error {
+	return repo.Put(ctx, s)
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/utahta/momoclo-channel/timeutil"
+)
+
+type (
+	// WebSubSubscription represents a verified subscription of WebSub subscriber
+	// the secret is encrypted, and the subscription is not distributed after the lease expires
+	WebSubSubscription struct {
+		ID          string    `datastore:"-" goon:"id" validate:"required"`
+		Topic       string    `datastore:",noindex" validate:"required,url"`
+		Callback    string    `datastore:",noindex" validate:"required,url"`
+		SecretCrypt string    `datastore:",noindex"` // no signature if empty
+		ExpiresAt   time.Time `validate:"required"`
+		CreatedAt   time.Time `validate:"required"`
+		UpdatedAt   time.Time `validate:"required"`
+	}
+)
+
+// NewWebSubSubscription returns WebSubSubscription given key, topic, callback, secret and lease
+func NewWebSubSubscription(tokenKey, topic, callback, secret string, lease time.Duration) (*WebSubSubscription, error) {
+	var secretCrypt string
+	if secret != "" {
+		var err error
+		secretCrypt, err = encrypt(tokenKey, secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &WebSubSubscription{
+		ID:          WebSubSubscriptionID(topic, callback),
+		Topic:       topic,
+		Callback:    callback,
+		SecretCrypt: secretCrypt,
+		ExpiresAt:   timeutil.Now().Add(lease),
+	}, nil
+}
+
+// WebSubSubscriptionID returns the id of subscription given topic and callback
+func WebSubSubscriptionID(topic, callback string) string {
+	return hashString(topic + "\n" + callback)
+}
+
+// Secret returns decrypted secret to sign contents
+func (s *WebSubSubscription) Secret(tokenKey string) (string, error) {
+	if s.SecretCrypt == "" {
+		return "", nil
+	}
+	return decrypt(tokenKey, s.SecretCrypt)
+}
+
+// Expired returns true if the lease of subscription is expired at given time
+func (s *WebSubSubscription) Expired(t time.Time) bool {
+	return !t.Before(s.ExpiresAt)
+}
+
+// SetCreatedAt sets given time to CreatedAt
+func (s *WebSubSubscription) SetCreatedAt(t time.Time) {
+	s.CreatedAt = t
+}
+
+// GetCreatedAt gets CreatedAt
+func (s *WebSubSubscription) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// SetUpdatedAt sets given time to UpdatedAt
+func (s *WebSubSubscription) SetUpdatedAt(t time.Time) {
+	s.UpdatedAt = t
+}
+
+// BeforeSave hook
+func (s *WebSubSubscription) BeforeSave() {
+	beforeSave(s)
+}
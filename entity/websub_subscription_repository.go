@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/utahta/momoclo-channel/dao"
+)
+
+type (
+	// WebSubSubscriptionRepository interface
+	WebSubSubscriptionRepository interface {
+		Find(context.Context, string) (*WebSubSubscription, error)
+		FindAll(context.Context) ([]*WebSubSubscription, error)
+		Save(context.Context, *WebSubSubscription) error
+		Delete(context.Context, string) error
+	}
+
+	// webSubSubscriptionRepository operates WebSubSubscription entity
+	webSubSubscriptionRepository struct {
+		dao.PersistenceHandler
+	}
+)
+
+// NewWebSubSubscriptionRepository returns the WebSubSubscriptionRepository
+func NewWebSubSubscriptionRepository(h dao.PersistenceHandler) WebSubSubscriptionRepository {
+	return &webSubSubscriptionRepository{h}
+}
+
+// Find finds websub subscription given id
+func (repo *webSubSubscriptionRepository) Find(ctx context.Context, id string) (*WebSubSubscription, error) {
+	s := &WebSubSubscription{ID: id}
+	return s, repo.Get(ctx, s)
+}
+
+// FindAll finds all websub subscriptions including expired ones
+func (repo *webSubSubscriptionRepository) FindAll(ctx context.Context) ([]*WebSubSubscription, error) {
+	kind := repo.Kind(ctx, &WebSubSubscription{})
+	q := repo.NewQuery(kind)
+
+	var dst []*WebSubSubscription
+	return dst, repo.GetAll(ctx, q, &dst)
+}
+
+// Save saves websub subscription
+func (repo *webSubSubscriptionRepository) Save(ctx context.Context, s *WebSubSubscription) error {
+	return repo.Put(ctx, s)
+}
+
+// Delete deletes websub subscription given id
+func (repo *webSubSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	return repo.PersistenceHandler.Delete(ctx, &WebSubSubscription{ID: id})
+}
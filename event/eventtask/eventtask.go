@@ -1,20 +1,26 @@
 package eventtask
 
 import (
+	"time"
+
+	"github.com/utahta/momoclo-channel/bluesky"
 	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/discord"
 	"github.com/utahta/momoclo-channel/event"
 	"github.com/utahta/momoclo-channel/linenotify"
+	"github.com/utahta/momoclo-channel/mailer"
+	"github.com/utahta/momoclo-channel/mastodon"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/slack"
+	"github.com/utahta/momoclo-channel/telegram"
 	"github.com/utahta/momoclo-channel/twitter"
+	"github.com/utahta/momoclo-channel/webpush"
+	"github.com/utahta/momoclo-channel/websub"
 )
 
-// NewEnqueueTweets returns enqueue tweets task
-func NewEnqueueTweets(v crawler.FeedItem) event.Task {
-	return event.Task{QueueName: "enqueue", Path: "/enqueue/tweets", Object: v}
-}
-
-// NewEnqueueLines returns enqueue lines task
-func NewEnqueueLines(v crawler.FeedItem) event.Task {
-	return event.Task{QueueName: "enqueue", Path: "/enqueue/lines", Object: v}
+// NewEnqueueNotification returns enqueue notification task of given channel
+func NewEnqueueNotification(channel string, v notifier.Notification) event.Task {
+	return event.Task{QueueName: "enqueue", Path: "/enqueue/" + channel, Object: v}
 }
 
 // NewTweet returns tweet task
@@ -29,11 +35,11 @@ func NewTweets(v []twitter.TweetRequest) event.Task {
 
 // NewLineBroadcast returns broadcast line notification task
 func NewLineBroadcast(v linenotify.Message) event.Task {
-	return NewLinesBroadcast([]linenotify.Message{v})
+	return NewLinesBroadcast(linenotify.Broadcast{Messages: []linenotify.Message{v}})
 }
 
 // NewLinesBroadcast returns broadcast line notification task
-func NewLinesBroadcast(v []linenotify.Message) event.Task {
+func NewLinesBroadcast(v linenotify.Broadcast) event.Task {
 	return event.Task{QueueName: "queue-line", Path: "/line/notify/broadcast", Object: v, RetryLimit: 1}
 }
 
@@ -41,3 +47,69 @@ func NewLinesBroadcast(v []linenotify.Message) event.Task {
 func NewLine(v linenotify.Request) event.Task {
 	return event.Task{QueueName: "queue-line", Path: "/line/notify", Object: v, RetryLimit: 3}
 }
+
+// NewBackfillFeed returns backfill feed task
+func NewBackfillFeed(v crawler.ArchiveRequest) event.Task {
+	return event.Task{QueueName: "queue-backfill", Path: "/backfill/feed", Object: v, RetryLimit: 3}
+}
+
+// NewDiscord returns discord webhook task
+func NewDiscord(v discord.Request) event.Task {
+	return event.Task{QueueName: "queue-discord", Path: "/discord/notify", Object: v, RetryLimit: 3}
+}
+
+// NewSlack returns slack webhook task
+func NewSlack(v slack.Request) event.Task {
+	return event.Task{QueueName: "queue-slack", Path: "/slack/notify", Object: v, RetryLimit: 3}
+}
+
+// NewBlueskyPosts returns bluesky post task
+func NewBlueskyPosts(v []bluesky.PostRequest) event.Task {
+	return event.Task{QueueName: "queue-bluesky", Path: "/bluesky/post", Object: v, RetryLimit: 3}
+}
+
+// NewEmail returns email task
+func NewEmail(v mailer.Mail) event.Task {
+	return event.Task{QueueName: "queue-email", Path: "/email/send", Object: v}
+}
+
+// NewWebhookDelivery returns webhook delivery task given delivery id
+func NewWebhookDelivery(id string) event.Task {
+	return event.Task{QueueName: "queue-webhook", Path: "/webhook/deliver", Object: id}
+}
+
+// NewTelegram returns telegram task
+func NewTelegram(v telegram.Request) event.Task {
+	return event.Task{QueueName: "queue-telegram", Path: "/telegram/send", Object: v, RetryLimit: 3}
+}
+
+// NewWebPushBroadcast returns broadcast web push task
+func NewWebPushBroadcast(v webpush.Message) event.Task {
+	return event.Task{QueueName: "queue-webpush", Path: "/webpush/broadcast", Object: v, RetryLimit: 1}
+}
+
+// NewWebPush returns web push task
+func NewWebPush(v webpush.Request) event.Task {
+	return event.Task{QueueName: "queue-webpush", Path: "/webpush/send", Object: v, RetryLimit: 3}
+}
+
+// NewWebSubVerification returns websub verification task given subscription request
+func NewWebSubVerification(v websub.Request) event.Task {
+	return event.Task{QueueName: "queue-websub", Path: "/websub/verify", Object: v}
+}
+
+// NewWebSubPublish returns websub publish task
+// it waits a moment so that the new entry appears in the query of history
+func NewWebSubPublish() event.Task {
+	return event.Task{QueueName: "queue-websub", Path: "/websub/publish", Delay: 10 * time.Second}
+}
+
+// NewWebSubDistribution returns websub content distribution task given subscription id
+func NewWebSubDistribution(id string) event.Task {
+	return event.Task{QueueName: "queue-websub", Path: "/websub/distribute", Object: id}
+}
+
+// NewMastodonStatuses returns mastodon status task
+func NewMastodonStatuses(v []mastodon.StatusRequest) event.Task {
+	return event.Task{QueueName: "queue-mastodon", Path: "/mastodon/status", Object: v, RetryLimit: 3}
+}
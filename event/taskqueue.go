@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/dryrun"
 	"google.golang.org/appengine/taskqueue"
 )
 
@@ -18,7 +19,7 @@ func NewTaskQueue() TaskQueue {
 func (t *taskQueue) Push(ctx context.Context, task Task) error {
 	const errTag = "taskQueue.Push failed"
 
-	req, err := t.newPOSTTask(task)
+	req, err := t.newPOSTTask(ctx, task)
 	if err != nil {
 		return errors.Wrap(err, errTag)
 	}
@@ -38,7 +39,7 @@ func (t *taskQueue) PushMulti(ctx context.Context, tasks []Task) error {
 
 	reqsMap := map[string][]*taskqueue.Task{}
 	for _, task := range tasks {
-		req, err := t.newPOSTTask(task)
+		req, err := t.newPOSTTask(ctx, task)
 		if err != nil {
 			return errors.Wrap(err, errTag)
 		}
@@ -63,7 +64,7 @@ func (t *taskQueue) PushMulti(ctx context.Context, tasks []Task) error {
 	return nil
 }
 
-func (t *taskQueue) newPOSTTask(task Task) (*taskqueue.Task, error) {
+func (t *taskQueue) newPOSTTask(ctx context.Context, task Task) (*taskqueue.Task, error) {
 	v, err := task.Params()
 	if err != nil {
 		return nil, errors.Wrapf(err, "taskQueue.newPOSTTask failed: task:%v", task)
@@ -73,6 +74,9 @@ func (t *taskQueue) newPOSTTask(task Task) (*taskqueue.Task, error) {
 	if task.Delay > 0 {
 		req.Delay = task.Delay
 	}
+	if dryrun.Enabled(ctx) {
+		req.Header.Set(dryrun.Header, "1") // carry dry-run mode to the next task
+	}
 
 	opts := &taskqueue.RetryOptions{}
 	if task.RetryLimit > 0 {
@@ -2,16 +2,49 @@ package linebot
 
 import (
 	"regexp"
+	"strings"
+)
+
+// names of the preferences commands
+const (
+	CommandSettings = "settings"
+	CommandMembers  = "members"
+	CommandFeeds    = "feeds"
+	CommandUstream  = "ustream"
+	CommandReminder = "reminder"
 )
 
 var (
 	reMatchOn      = regexp.MustCompile("^(おん|オン|on)$")
 	reMatchOff     = regexp.MustCompile("^(おふ|オフ|off)$")
+	reMatchAll     = regexp.MustCompile("^(全部|ぜんぶ|全員|ぜんいん|all)$")
 	reMatchMomota  = regexp.MustCompile("百田|[もモ][もモ][たタ]|[夏かカ][菜なナ][子こコ]")
 	reMatchAriyasu = regexp.MustCompile("有安|[あア][りリ][やヤ][すス]|[もモ][もモ][かカ]|杏果")
 	reMatchTamai   = regexp.MustCompile("玉井|[たタ][まマ][いイ]|[しシ][おオ][りリ][んン]?|詩織|玉さん|[たタ][まマ]さん")
 	reMatchSasaki  = regexp.MustCompile("佐々木|[さサ][さサ][きキ]|[あア][やヤ][かカ]|彩夏|[あア]ー[りリ][んン]")
 	reMatchTakagi  = regexp.MustCompile("高城|[たタ][かカ][ぎギ]|[れレ][にニ]")
+
+	members = []struct {
+		name string
+		re   *regexp.Regexp
+	}{
+		{"百田夏菜子", reMatchMomota},
+		{"有安杏果", reMatchAriyasu},
+		{"玉井詩織", reMatchTamai},
+		{"佐々木彩夏", reMatchSasaki},
+		{"高城れに", reMatchTakagi},
+	}
+
+	commands = []struct {
+		name string
+		re   *regexp.Regexp
+	}{
+		{CommandSettings, regexp.MustCompile(`^(?:設定|せってい|settings)$`)},
+		{CommandMembers, regexp.MustCompile(`^(?:推し|おし|members)(?:[\s　]+(.*))?$`)},
+		{CommandFeeds, regexp.MustCompile(`^(?:フィード|ふぃーど|feeds)(?:[\s　]+(.*))?$`)},
+		{CommandUstream, regexp.MustCompile(`^(?:ライブ|らいぶ|live)[\s　]*(おん|オン|on|おふ|オフ|off)$`)},
+		{CommandReminder, regexp.MustCompile(`^(?:リマインダー|りまいんだー|reminder)[\s　]*(おん|オン|on|おふ|オフ|off)$`)},
+	}
 )
 
 // MatchOn return true if text match on
@@ -24,26 +57,75 @@ func MatchOff(text string) bool {
 	return reMatchOff.MatchString(text)
 }
 
-// FindMemberName returns member name if text match member name or nickname
-func FindMemberName(text string) string {
-	if reMatchMomota.MatchString(text) {
-		return "百田夏菜子"
-	}
+// MatchAll return true if text match all (e.g. "全部")
+func MatchAll(text string) bool {
+	return reMatchAll.MatchString(text)
+}
 
-	if reMatchAriyasu.MatchString(text) {
-		return "有安杏果"
+// MatchCommand returns the name and the argument of preferences command if text match it
+// e.g. "推し れにちゃん" returns CommandMembers and "れにちゃん"
+func MatchCommand(text string) (string, string, bool) {
+	text = strings.TrimSpace(text)
+	for _, c := range commands {
+		m := c.re.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		var arg string
+		if len(m) > 1 {
+			arg = strings.TrimSpace(m[1])
+		}
+		return c.name, arg, true
 	}
+	return "", "", false
+}
 
-	if reMatchTamai.MatchString(text) {
-		return "玉井詩織"
+// MemberNames returns all member names
+func MemberNames() []string {
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.name
 	}
+	return names
+}
 
-	if reMatchSasaki.MatchString(text) {
-		return "佐々木彩夏"
+// IsMemberName returns true if given name is the name of a member
+func IsMemberName(name string) bool {
+	for _, m := range members {
+		if m.name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	if reMatchTakagi.MatchString(text) {
-		return "高城れに"
+// FindMemberName returns member name if text match member name or nickname
+func FindMemberName(text string) string {
+	if names := FindMemberNames(text); len(names) > 0 {
+		return names[0]
 	}
 	return ""
 }
+
+// FindMemberNames returns all member names that text match
+func FindMemberNames(text string) []string {
+	var names []string
+	for _, m := range members {
+		if m.re.MatchString(text) {
+			names = append(names, m.name)
+		}
+	}
+	return names
+}
+
+// FindFullMemberNames returns all member names that text contains as is
+// nicknames are not matched, because they are too short to find in long text (e.g. "れに" in "これに")
+func FindFullMemberNames(text string) []string {
+	var names []string
+	for _, m := range members {
+		if strings.Contains(text, m.name) {
+			names = append(names, m.name)
+		}
+	}
+	return names
+}
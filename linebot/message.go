@@ -2,19 +2,20 @@ package linebot
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/utahta/momoclo-channel/config"
 )
 
-// FollowMessage returns message on follow
-func FollowMessage() string {
+// FollowMessage returns message on follow given the url to connect LINE Notify
+func FollowMessage(onURL string) string {
 	return fmt.Sprintf(`友だち追加ありがとうございます。
 こちらは、ももクロちゃんのブログやAE NEWS等を通知する機能との連携を補助したり、画像を返したりするBOTです。
 
 %s
 
 %s
-`, HelpMessage(), OnMessage())
+`, HelpMessage(), OnMessage(onURL))
 }
 
 // HelpMessage returns help message
@@ -23,10 +24,9 @@ func HelpMessage() string {
 	return fmt.Sprintf("ヘルプ（・Θ・）\n%s", urlStr)
 }
 
-// OnMessage returns line notification on message
-func OnMessage() string {
-	urlStr := fmt.Sprintf("%s%s", config.C().App.BaseURL, "/line/notify/on")
-	return fmt.Sprintf("通知機能を有効にする場合は、下記URLをクリックしてください（・Θ・）\n%s", urlStr)
+// OnMessage returns line notification on message given the url to connect LINE Notify
+func OnMessage(onURL string) string {
+	return fmt.Sprintf("通知機能を有効にする場合は、下記URLをクリックしてください（・Θ・）\n%s", onURL)
 }
 
 // OffMessage returns line notification off message
@@ -38,3 +38,42 @@ func OffMessage() string {
 func ImageNotFoundMessage() string {
 	return "画像がみつかりませんでした（・Θ・）"
 }
+
+// ConnectedMessage returns message that is notified after connected given the url of preferences page
+func ConnectedMessage(preferencesURL string) string {
+	return fmt.Sprintf("通知連携しました（・Θ・）\n通知するフィードや推しメンは下記URLから設定できます。\n%s", preferencesURL)
+}
+
+// NotConnectedMessage returns message for the user who has not connected LINE Notify through the bot
+func NotConnectedMessage(onURL string) string {
+	return fmt.Sprintf("通知の設定をするには、下記URLから通知連携してください（・Θ・）\n%s", onURL)
+}
+
+// PreferencesMessage returns message of the preferences given the url of preferences page
+func PreferencesMessage(codes, members []string, ustream, reminder bool, preferencesURL string) string {
+	return fmt.Sprintf("通知の設定（・Θ・）\nフィード: %s\n推し: %s\nライブ: %s\nリマインダー: %s\n\n設定ページ\n%s",
+		listText(codes, "全部"), listText(members, "全員"), onOffText(ustream), onOffText(reminder), preferencesURL)
+}
+
+// InvalidPreferencesMessage returns message for invalid preferences command given feed codes that can be chosen
+func InvalidPreferencesMessage(codes []string) string {
+	return fmt.Sprintf(`設定できませんでした（・Θ・）
+推し: 推し れに / 推し 全員
+フィード: フィード %s / フィード 全部
+ライブ: ライブ おん / ライブ おふ
+リマインダー: リマインダー おん / リマインダー おふ`, strings.Join(codes, " "))
+}
+
+func listText(a []string, all string) string {
+	if len(a) == 0 {
+		return all
+	}
+	return strings.Join(a, "、")
+}
+
+func onOffText(b bool) string {
+	if b {
+		return "おん"
+	}
+	return "おふ"
+}
@@ -23,6 +23,7 @@ type (
 	// Event represents line bot event
 	Event struct {
 		ReplyToken  string
+		UserID      string // id of the user who sent the event, it may be empty
 		Type        EventType
 		MessageType MessageType
 		TextMessage TextMessage
@@ -49,6 +50,9 @@ func ParseRequest(r *http.Request) ([]Event, error) {
 	results := make([]Event, len(events))
 	for i, event := range events {
 		results[i].ReplyToken = event.ReplyToken
+		if event.Source != nil {
+			results[i].UserID = event.Source.UserID
+		}
 
 		switch event.Type {
 		case linebot.EventTypeMessage:
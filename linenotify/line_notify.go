@@ -29,6 +29,14 @@ type (
 		Messages    []Message `validate:"min=1,dive"`
 	}
 
+	// Broadcast represents messages that are delivered to the subscribers who accept them
+	Broadcast struct {
+		Messages []Message `validate:"min=1,dive"`
+		Kind     string    // kind of the notification (e.g. "feed", "ustream"), all subscribers accept it if empty
+		Code     string    // feed code of the entry
+		Members  []string  // member names that the entry relates to
+	}
+
 	// Client interface
 	Client interface {
 		Notify(context.Context, string, Message) error
@@ -0,0 +1,28 @@
+package linenotify
+
+import (
+	"net/url"
+
+	"github.com/utahta/momoclo-channel/config"
+)
+
+// PreferencesURL returns the signed link of the preferences page given notification id and token
+func PreferencesURL(id, token string) string {
+	v := url.Values{}
+	v.Set("id", id)
+	v.Set("token", token)
+	return config.C().App.BaseURL + "/line/notify/preferences?" + v.Encode()
+}
+
+// OnURL returns LINE Notify connection URL
+// the connected token is linked to the LINE bot user if the user hash and the token are given
+func OnURL(userHash, token string) string {
+	urlStr := config.C().App.BaseURL + "/line/notify/on"
+	if userHash == "" {
+		return urlStr
+	}
+	v := url.Values{}
+	v.Set("user", userHash)
+	v.Set("token", token)
+	return urlStr + "?" + v.Encode()
+}
@@ -0,0 +1,144 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/validator"
+	"google.golang.org/appengine/socket"
+)
+
+type (
+	// Mail represents a plain text mail
+	Mail struct {
+		To      string `validate:"required,email"`
+		Subject string `validate:"required"`
+		Body    string `validate:"required"`
+
+		// UnsubscribeURL is the one-click unsubscribe link of the recipient (see: RFC 8058)
+		UnsubscribeURL string `validate:"omitempty,url"`
+	}
+
+	// Mailer interface
+	Mailer interface {
+		Send(context.Context, Mail) error
+	}
+
+	smtpMailer struct {
+		host     string
+		port     int
+		username string
+		password string
+		from     string
+	}
+)
+
+// New returns Mailer that sends mails through the SMTP server of config
+func New() Mailer {
+	c := config.C().Mail
+	if c.Disabled {
+		return NewNop()
+	}
+	return NewSMTP(c.Host, c.Port, c.Username, c.Password, c.From)
+}
+
+// NewSMTP returns Mailer that sends mails through given SMTP server
+// the connection is upgraded by STARTTLS if the server supports it
+func NewSMTP(host string, port int, username, password, from string) Mailer {
+	return &smtpMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send sends the mail
+func (m *smtpMailer) Send(ctx context.Context, mail Mail) error {
+	const errTag = "mailer.Send failed"
+
+	if err := validator.Validate(mail); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	conn, err := socket.DialTimeout(ctx, "tcp", net.JoinHostPort(m.host, strconv.Itoa(m.port)), 30*time.Second)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, errTag)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(nil); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+	}
+	if m.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+	}
+	if err := c.Mail(m.from); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if err := c.Rcpt(mail.To); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if _, err := w.Write(Message(m.from, mail)); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	return c.Quit()
+}
+
+// Message returns RFC 5322 message of the mail
+func Message(from string, mail Mail) []byte {
+	buf := &bytes.Buffer{}
+	header := func(k, v string) {
+		fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+	}
+	header("From", from)
+	header("To", mail.To)
+	header("Subject", mime.BEncoding.Encode("UTF-8", mail.Subject))
+	header("Date", timeutil.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "base64")
+	if mail.UnsubscribeURL != "" {
+		header("List-Unsubscribe", "<"+mail.UnsubscribeURL+">")
+		header("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	buf.WriteString("\r\n")
+
+	// lines of base64 must not be longer than 76 characters
+	const lineLen = 76
+	body := base64.StdEncoding.EncodeToString([]byte(mail.Body))
+	for len(body) > lineLen {
+		buf.WriteString(body[:lineLen] + "\r\n")
+		body = body[lineLen:]
+	}
+	buf.WriteString(body + "\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestMessage(t *testing.T) {
+	body := strings.Repeat("ももいろクローバーZ\n", 10)
+	b := Message("from@example.com", Mail{
+		To:             "to@example.com",
+		Subject:        "新着ブログ",
+		Body:           body,
+		UnsubscribeURL: "https://example.com/email/unsubscribe?id=1&token=a",
+	})
+
+	parts := strings.SplitN(string(b), "\r\n\r\n", 2)
+	if len(parts) != 2 {
+		t.Fatalf("Expected header and body, got %q", b)
+	}
+	header, encoded := parts[0], parts[1]
+
+	for _, expected := range []string{
+		"From: from@example.com",
+		"To: to@example.com",
+		"Subject: =?UTF-8?b?",
+		"Content-Type: text/plain; charset=UTF-8",
+		"List-Unsubscribe: <https://example.com/email/unsubscribe?id=1&token=a>",
+		"List-Unsubscribe-Post: List-Unsubscribe=One-Click",
+	} {
+		if !strings.Contains(header, expected) {
+			t.Errorf("Expected header %q, got %q", expected, header)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(encoded), "\r\n")
+	for _, line := range lines {
+		if len(line) > 76 {
+			t.Errorf("Expected line length <= 76, got %v", len(line))
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(lines, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decoded) != body {
+		t.Errorf("Expected body %q, got %q", body, decoded)
+	}
+
+	if b := Message("from@example.com", Mail{To: "to@example.com", Subject: "a", Body: "b"}); strings.Contains(string(b), "List-Unsubscribe") {
+		t.Errorf("Expected no unsubscribe header, got %q", b)
+	}
+}
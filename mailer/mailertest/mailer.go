@@ -0,0 +1,34 @@
+package mailertest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/utahta/momoclo-channel/mailer"
+)
+
+// Mailer is a fake mailer that records sent mails instead of sending them
+type Mailer struct {
+	mu    sync.Mutex
+	mails []mailer.Mail
+}
+
+// NewMailer returns the fake mailer
+func NewMailer() *Mailer {
+	return &Mailer{}
+}
+
+// Send records the mail
+func (m *Mailer) Send(_ context.Context, mail mailer.Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mails = append(m.mails, mail)
+	return nil
+}
+
+// Mails returns the recorded mails
+func (m *Mailer) Mails() []mailer.Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mailer.Mail{}, m.mails...)
+}
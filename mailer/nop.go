@@ -0,0 +1,14 @@
+package mailer
+
+import "context"
+
+type nop struct{}
+
+// NewNop returns no operation mailer
+func NewNop() Mailer {
+	return &nop{}
+}
+
+func (m *nop) Send(_ context.Context, _ Mail) error {
+	return nil
+}
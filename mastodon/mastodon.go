@@ -0,0 +1,251 @@
+package mastodon
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"google.golang.org/appengine/urlfetch"
+)
+
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+	VisibilityDirect   = "direct"
+
+	// MaxMediaNum is the max number of media attachments in a status
+	MaxMediaNum = 4
+)
+
+type (
+	// StatusRequest represents request that posts a status with media
+	StatusRequest struct {
+		InReplyToID string
+		Text        string
+		ImageURLs   []string `validate:"max=4,dive,omitempty,url"`
+		VideoURL    string   `validate:"omitempty,url"`
+		SpoilerText string   // content warning, the default of config is used if empty
+		Visibility  string   `validate:"omitempty,eq=public|eq=unlisted|eq=private|eq=direct"`
+	}
+
+	// StatusResponse represents the posted status
+	StatusResponse struct {
+		ID string `json:"id"`
+	}
+
+	// Client interface
+	Client interface {
+		PostStatus(context.Context, StatusRequest) (StatusResponse, error)
+	}
+
+	client struct {
+		instanceURL string
+		accessToken string
+		httpClient  func(context.Context) *http.Client
+	}
+
+	attachment struct {
+		ID  string `json:"id"`
+		URL string `json:"url"` // null while the media is processed
+	}
+)
+
+// maxMediaSize is the max size of media to upload (see: instance limits of mastodon)
+const maxMediaSize = 40 * 1024 * 1024
+
+// New returns Client that posts statuses to the instance of config
+func New() Client {
+	c := config.C().Mastodon
+	if c.Disabled {
+		return NewNop()
+	}
+	return NewWithHTTPClient(c.InstanceURL, c.AccessToken, urlfetch.Client)
+}
+
+// NewWithHTTPClient returns Client that posts statuses through given http client
+func NewWithHTTPClient(instanceURL, accessToken string, fn func(context.Context) *http.Client) Client {
+	return &client{
+		instanceURL: strings.TrimSuffix(instanceURL, "/"),
+		accessToken: accessToken,
+		httpClient:  fn,
+	}
+}
+
+// PostStatus uploads media and posts a status
+func (c *client) PostStatus(ctx context.Context, req StatusRequest) (StatusResponse, error) {
+	const errTag = "mastodon.PostStatus failed"
+
+	mediaURLs := append([]string{}, req.ImageURLs...)
+	if req.VideoURL != "" {
+		mediaURLs = append(mediaURLs, req.VideoURL)
+	}
+	var mediaIDs []string
+	for _, mediaURL := range mediaURLs {
+		id, err := c.uploadMedia(ctx, mediaURL)
+		if err != nil {
+			return StatusResponse{}, errors.Wrapf(err, "%v: media:%v", errTag, mediaURL)
+		}
+		mediaIDs = append(mediaIDs, id)
+	}
+
+	v := url.Values{}
+	v.Set("status", req.Text)
+	if req.InReplyToID != "" {
+		v.Set("in_reply_to_id", req.InReplyToID)
+	}
+	for _, id := range mediaIDs {
+		v.Add("media_ids[]", id)
+	}
+	spoilerText, visibility := req.SpoilerText, req.Visibility
+	if c := config.C(); c != nil {
+		if spoilerText == "" {
+			spoilerText = c.Mastodon.SpoilerText
+		}
+		if visibility == "" {
+			visibility = c.Mastodon.Visibility
+		}
+	}
+	if spoilerText != "" {
+		v.Set("spoiler_text", spoilerText)
+	}
+	if visibility != "" {
+		v.Set("visibility", visibility)
+	}
+
+	r, err := http.NewRequest(http.MethodPost, c.instanceURL+"/api/v1/statuses", strings.NewReader(v.Encode()))
+	if err != nil {
+		return StatusResponse{}, errors.Wrap(err, errTag)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// the same request of the task retry is not posted twice
+	r.Header.Set("Idempotency-Key", idempotencyKey(req))
+
+	var res StatusResponse
+	if err := c.do(ctx, r, &res); err != nil {
+		return StatusResponse{}, errors.Wrap(err, errTag)
+	}
+	return res, nil
+}
+
+// uploadMedia uploads the media of given url and returns the attachment id
+func (c *client) uploadMedia(ctx context.Context, mediaURL string) (string, error) {
+	b, err := c.fetchMedia(ctx, mediaURL)
+	if err != nil {
+		return "", err
+	}
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, err := mw.CreateFormFile("file", path.Base(mediaURL))
+	if err != nil {
+		return "", err
+	}
+	if _, err := fw.Write(b); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	r, err := http.NewRequest(http.MethodPost, c.instanceURL+"/api/v2/media", body)
+	if err != nil {
+		return "", err
+	}
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var a attachment
+	if err := c.do(ctx, r, &a); err != nil {
+		return "", err
+	}
+	if a.URL == "" {
+		// large media such as video is processed asynchronously
+		if err := c.waitMedia(ctx, a.ID); err != nil {
+			return "", err
+		}
+	}
+	return a.ID, nil
+}
+
+// waitMedia waits until the media is processed
+func (c *client) waitMedia(ctx context.Context, id string) error {
+	const maxPolls = 10
+	for i := 0; i < maxPolls; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(i+1) * time.Second):
+		}
+
+		r, err := http.NewRequest(http.MethodGet, c.instanceURL+"/api/v1/media/"+id, nil)
+		if err != nil {
+			return err
+		}
+		var a attachment
+		if err := c.do(ctx, r, &a); err != nil {
+			return err
+		}
+		if a.URL != "" {
+			return nil
+		}
+	}
+	return errors.Errorf("media is not processed id:%v", id)
+}
+
+func (c *client) fetchMedia(ctx context.Context, mediaURL string) ([]byte, error) {
+	resp, err := c.httpClient(ctx).Get(mediaURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetch media status:%v", resp.StatusCode)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxMediaSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxMediaSize {
+		return nil, errors.Errorf("media is too large url:%v", mediaURL)
+	}
+	return b, nil
+}
+
+// do sends the request with the access token and decodes json response
+func (c *client) do(ctx context.Context, r *http.Request, v interface{}) error {
+	r.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.httpClient(ctx).Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("status:%v body:%s", resp.StatusCode, b)
+	}
+	return json.Unmarshal(b, v)
+}
+
+func idempotencyKey(req StatusRequest) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v", req)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_PostStatus(t *testing.T) {
+	var (
+		uploaded int
+		form     map[string][]string
+		auth     string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/media/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("image"))
+	})
+	mux.HandleFunc("/api/v2/media", func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := r.FormFile("file"); err != nil {
+			t.Errorf("Expected file, got %v", err)
+		}
+		uploaded++
+		json.NewEncoder(w).Encode(attachment{ID: fmt.Sprintf("m%d", uploaded), URL: "http://localhost/m"})
+	})
+	mux.HandleFunc("/api/v1/statuses", func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.Header.Get("Idempotency-Key") == "" {
+			t.Error("Expected idempotency key, got empty")
+		}
+		r.ParseForm()
+		form = r.PostForm
+		json.NewEncoder(w).Encode(StatusResponse{ID: "100"})
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	c := NewWithHTTPClient(s.URL+"/", "token", func(context.Context) *http.Client { return http.DefaultClient })
+	res, err := c.PostStatus(context.Background(), StatusRequest{
+		InReplyToID: "99",
+		Text:        "hello",
+		ImageURLs:   []string{s.URL + "/media/1.jpg", s.URL + "/media/2.jpg"},
+		SpoilerText: "spoiler",
+		Visibility:  VisibilityUnlisted,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.ID != "100" {
+		t.Errorf("Expected id 100, got %v", res.ID)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("Expected bearer token, got %v", auth)
+	}
+	expected := map[string]string{
+		"status":         "hello",
+		"in_reply_to_id": "99",
+		"spoiler_text":   "spoiler",
+		"visibility":     "unlisted",
+		"media_ids[]":    "m1,m2",
+	}
+	for k, v := range expected {
+		if got := strings.Join(form[k], ","); got != v {
+			t.Errorf("Expected %v %v, got %v", k, v, got)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package mastodon
+
+import "context"
+
+type nop struct{}
+
+// NewNop returns no operation client
+func NewNop() Client {
+	return &nop{}
+}
+
+func (c *nop) PostStatus(_ context.Context, _ StatusRequest) (StatusResponse, error) {
+	return StatusResponse{}, nil
+}
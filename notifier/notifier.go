@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/crawler"
+)
+
+const (
+	KindFeed     Kind = "feed"     // new or edited entry of a feed, edits are delivered to LINE and Twitter only
+	KindUstream  Kind = "ustream"  // live streaming started
+	KindReminder Kind = "reminder" // reminder message
+
+	ChannelTwitter  = "twitter"
+	ChannelLine     = "line"
+	ChannelDiscord  = "discord"
+	ChannelSlack    = "slack"
+	ChannelMastodon = "mastodon"
+	ChannelBluesky  = "bluesky"
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+	ChannelWebPush  = "webpush"
+	ChannelTelegram = "telegram"
+)
+
+type (
+	// Kind represents the kind of notification
+	Kind string
+
+	// Notification represents a channel-neutral notification
+	// each channel formats it to its own messages
+	Notification struct {
+		Kind      Kind              `validate:"required"`
+		FeedItem  *crawler.FeedItem // KindFeed only
+		Text      string            // message of the other kinds
+		URL       string            // link of the other kinds
+		CreatedAt time.Time
+	}
+
+	// Notifier delivers notifications to a channel
+	// implementations own the queue, formatter and dedupe history of the channel
+	Notifier interface {
+		Notify(context.Context, Notification) error
+	}
+
+	// Registry represents registered channels
+	Registry struct {
+		names     []string
+		notifiers map[string]Notifier
+	}
+)
+
+// NewFeedNotification returns Notification of given feed item
+func NewFeedNotification(item crawler.FeedItem) Notification {
+	return Notification{Kind: KindFeed, FeedItem: &item}
+}
+
+// NewMessageNotification returns Notification of given kind and message
+func NewMessageNotification(kind Kind, text, url string, t time.Time) Notification {
+	return Notification{Kind: kind, Text: text, URL: url, CreatedAt: t}
+}
+
+// NewRegistry returns Registry
+func NewRegistry() *Registry {
+	return &Registry{notifiers: map[string]Notifier{}}
+}
+
+// Register registers the notifier of given channel
+// the notifier that is registered with the same name is replaced
+func (r *Registry) Register(name string, n Notifier) {
+	if _, ok := r.notifiers[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.notifiers[name] = n
+}
+
+// Find returns the notifier of given channel
+func (r *Registry) Find(name string) (Notifier, bool) {
+	n, ok := r.notifiers[name]
+	return n, ok
+}
+
+// IsEnabled returns true if given channel is registered and enabled
+func (r *Registry) IsEnabled(name string) bool {
+	for _, v := range r.Enabled() {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled returns the names of enabled channels in registered order
+// all registered channels are enabled if config does not have any channels
+func (r *Registry) Enabled() []string {
+	c := config.C()
+	if c == nil || len(c.Notifier.Channels) == 0 {
+		return r.names
+	}
+
+	var names []string
+	for _, name := range r.names {
+		for _, v := range c.Notifier.Channels {
+			if name == v {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,161 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"google.golang.org/appengine/urlfetch"
+)
+
+const (
+	BlockTypeSection = "section"
+	BlockTypeContext = "context"
+	BlockTypeImage   = "image"
+
+	TextTypeMarkdown  = "mrkdwn"
+	TextTypePlainText = "plain_text"
+)
+
+type (
+	// Message represents incoming webhook message that is composed of Block Kit blocks
+	// Text is the fallback of notifications
+	// see: https://api.slack.com/reference/block-kit/blocks
+	Message struct {
+		Text   string  `json:"text" validate:"required"`
+		Blocks []Block `json:"blocks,omitempty" validate:"dive"`
+	}
+
+	// Block represents a layout block
+	Block struct {
+		Type      string   `json:"type" validate:"required"`
+		Text      *Text    `json:"text,omitempty"`
+		Elements  []Text   `json:"elements,omitempty"`  // context block only
+		Accessory *Element `json:"accessory,omitempty"` // section block only
+		ImageURL  string   `json:"image_url,omitempty" validate:"omitempty,url"`
+		AltText   string   `json:"alt_text,omitempty"`
+	}
+
+	// Text represents a text object
+	Text struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+
+	// Element represents an image element
+	Element struct {
+		Type     string `json:"type"`
+		ImageURL string `json:"image_url" validate:"url"`
+		AltText  string `json:"alt_text"`
+	}
+
+	// Request represents request that posts message to a webhook
+	Request struct {
+		ID         string `validate:"required"`
+		WebhookURL string `validate:"required,url"`
+		Message    Message
+	}
+
+	// Client interface
+	Client interface {
+		Post(context.Context, string, Message) error
+	}
+
+	// RateLimitError represents the response that is rate limited
+	RateLimitError struct {
+		RetryAfter time.Duration
+	}
+
+	client struct {
+		httpClient func(context.Context) *http.Client
+	}
+)
+
+var (
+	// ErrInvalidWebhook is returned when the webhook is removed or its channel is archived
+	ErrInvalidWebhook = errors.New("mcz: invalid slack webhook")
+
+	escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// New returns Client that posts messages to Slack incoming webhooks
+func New() Client {
+	if config.C().Slack.Disabled {
+		return NewNop()
+	}
+	return NewWithHTTPClient(urlfetch.Client)
+}
+
+// NewWithHTTPClient returns Client that posts messages through given http client
+func NewWithHTTPClient(fn func(context.Context) *http.Client) Client {
+	return &client{httpClient: fn}
+}
+
+// NewMarkdown returns markdown text object
+func NewMarkdown(text string) *Text {
+	return &Text{Type: TextTypeMarkdown, Text: text}
+}
+
+// Escape escapes control characters of Slack message formatting
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// Link returns markdown link
+func Link(urlStr, text string) string {
+	if text == "" {
+		return "<" + urlStr + ">"
+	}
+	return "<" + urlStr + "|" + Escape(text) + ">"
+}
+
+// Error implements error
+func (e *RateLimitError) Error() string {
+	return "mcz: slack rate limited retry after:" + e.RetryAfter.String()
+}
+
+// Post posts message to given webhook url
+func (c *client) Post(ctx context.Context, webhookURL string, msg Message) error {
+	const errTag = "slack.Post failed"
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient(ctx).Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	defer resp.Body.Close()
+
+	// see: https://api.slack.com/messaging/webhooks#handling_errors
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		return ErrInvalidWebhook
+	case http.StatusTooManyRequests:
+		retryAfter := 30 * time.Second
+		if v, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && v > 0 {
+			retryAfter = time.Duration(v) * time.Second
+		}
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return errors.Errorf("%v: status:%v body:%s", errTag, resp.StatusCode, body)
+}
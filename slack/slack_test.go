@@ -0,0 +1,58 @@
+package slack_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/utahta/momoclo-channel/slack"
+	"github.com/utahta/momoclo-channel/slack/slacktest"
+)
+
+func TestClient_Post(t *testing.T) {
+	s := slacktest.NewServer()
+	defer s.Close()
+
+	c := slack.NewWithHTTPClient(func(context.Context) *http.Client { return http.DefaultClient })
+	msg := slack.Message{
+		Text:   "fallback",
+		Blocks: []slack.Block{{Type: slack.BlockTypeSection, Text: slack.NewMarkdown("*hello*")}},
+	}
+
+	if err := c.Post(context.Background(), s.WebhookURL("/services/a"), msg); err != nil {
+		t.Fatal(err)
+	}
+	messages := s.Messages("/services/a")
+	if len(messages) != 1 || messages[0].Blocks[0].Text.Text != "*hello*" {
+		t.Errorf("Expected received message, got %v", messages)
+	}
+
+	tests := []struct {
+		status int
+		err    error
+	}{
+		{http.StatusNotFound, slack.ErrInvalidWebhook},
+		{http.StatusForbidden, slack.ErrInvalidWebhook},
+		{http.StatusGone, slack.ErrInvalidWebhook},
+	}
+	for _, test := range tests {
+		s.SetStatus("/services/b", test.status)
+		if err := c.Post(context.Background(), s.WebhookURL("/services/b"), msg); err != test.err {
+			t.Errorf("Expected %v, got %v. status:%v", test.err, err, test.status)
+		}
+	}
+
+	s.SetStatus("/services/b", http.StatusTooManyRequests)
+	if _, ok := c.Post(context.Background(), s.WebhookURL("/services/b"), msg).(*slack.RateLimitError); !ok {
+		t.Errorf("Expected rate limit error")
+	}
+}
+
+func TestLink(t *testing.T) {
+	if v := slack.Link("http://localhost/a", "<a & b>"); v != "<http://localhost/a|&lt;a &amp; b&gt;>" {
+		t.Errorf("Expected escaped link, got %v", v)
+	}
+	if v := slack.Link("http://localhost/a", ""); v != "<http://localhost/a>" {
+		t.Errorf("Expected link, got %v", v)
+	}
+}
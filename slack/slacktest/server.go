@@ -0,0 +1,72 @@
+package slacktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/utahta/momoclo-channel/slack"
+)
+
+type (
+	// Server represents fake Slack incoming webhook server
+	// it records received messages by the path of the webhook
+	Server struct {
+		*httptest.Server
+
+		mu       sync.Mutex
+		messages map[string][]slack.Message
+		statuses map[string]int
+	}
+)
+
+// NewServer starts and returns fake server
+// the caller should call Close when finished
+func NewServer() *Server {
+	s := &Server{
+		messages: map[string][]slack.Message{},
+		statuses: map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// WebhookURL returns the webhook url of given path (e.g. /services/T000/B000/XXXX)
+func (s *Server) WebhookURL(path string) string {
+	return s.URL + path
+}
+
+// SetStatus makes the webhook of given path respond given status code
+func (s *Server) SetStatus(path string, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[path] = code
+}
+
+// Messages returns messages that the webhook of given path received
+func (s *Server) Messages(path string) []slack.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages[path]
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if code, ok := s.statuses[r.URL.Path]; ok && code != http.StatusOK {
+		w.WriteHeader(code)
+		w.Write([]byte("no_service"))
+		return
+	}
+
+	var msg slack.Message
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&msg) != nil || msg.Text == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid_payload"))
+		return
+	}
+	s.messages[r.URL.Path] = append(s.messages[r.URL.Path], msg)
+	w.Write([]byte("ok"))
+}
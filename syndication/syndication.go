@@ -0,0 +1,277 @@
+package syndication
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// content types of the feed documents
+const (
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+	ContentTypeJSON = "application/feed+json; charset=utf-8"
+)
+
+type (
+	// Feed represents an aggregated feed document
+	Feed struct {
+		Title   string
+		Link    string // url of the site
+		FeedURL string // url of the document itself
+		HubURL  string // url of WebSub hub, not advertised if empty
+		Updated time.Time
+		Items   []Item
+	}
+
+	// Item represents an entry of the feed
+	Item struct {
+		ID        string
+		Title     string
+		URL       string
+		Category  string // feed code of the entry
+		Published time.Time
+		ImageURLs []string
+		VideoURLs []string
+	}
+
+	atomFeed struct {
+		XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+		Title   string      `xml:"title"`
+		ID      string      `xml:"id"`
+		Updated string      `xml:"updated"`
+		Links   []atomLink  `xml:"link"`
+		Entries []atomEntry `xml:"entry"`
+	}
+
+	atomEntry struct {
+		Title     string        `xml:"title"`
+		ID        string        `xml:"id"`
+		Updated   string        `xml:"updated"`
+		Published string        `xml:"published"`
+		Links     []atomLink    `xml:"link"`
+		Category  *atomCategory `xml:"category,omitempty"`
+	}
+
+	atomLink struct {
+		Rel  string `xml:"rel,attr,omitempty"`
+		Type string `xml:"type,attr,omitempty"`
+		Href string `xml:"href,attr"`
+	}
+
+	atomCategory struct {
+		Term string `xml:"term,attr"`
+	}
+
+	rssFeed struct {
+		XMLName xml.Name   `xml:"rss"`
+		Version string     `xml:"version,attr"`
+		Atom    string     `xml:"xmlns:atom,attr"`
+		Channel rssChannel `xml:"channel"`
+	}
+
+	rssChannel struct {
+		Title         string     `xml:"title"`
+		Link          string     `xml:"link"`
+		Description   string     `xml:"description"`
+		AtomLinks     []atomLink `xml:"atom:link"`
+		LastBuildDate string     `xml:"lastBuildDate"`
+		Items         []rssItem  `xml:"item"`
+	}
+
+	rssItem struct {
+		Title     string        `xml:"title"`
+		Link      string        `xml:"link"`
+		GUID      rssGUID       `xml:"guid"`
+		PubDate   string        `xml:"pubDate"`
+		Category  string        `xml:"category,omitempty"`
+		Enclosure *rssEnclosure `xml:"enclosure,omitempty"`
+	}
+
+	rssGUID struct {
+		IsPermaLink bool   `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+
+	rssEnclosure struct {
+		URL    string `xml:"url,attr"`
+		Length int    `xml:"length,attr"`
+		Type   string `xml:"type,attr"`
+	}
+
+	jsonFeed struct {
+		Version     string     `json:"version"`
+		Title       string     `json:"title"`
+		HomePageURL string     `json:"home_page_url"`
+		FeedURL     string     `json:"feed_url"`
+		Hubs        []jsonHub  `json:"hubs,omitempty"`
+		Items       []jsonItem `json:"items"`
+	}
+
+	jsonHub struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}
+
+	jsonItem struct {
+		ID            string           `json:"id"`
+		URL           string           `json:"url"`
+		Title         string           `json:"title"`
+		ContentText   string           `json:"content_text"`
+		Image         string           `json:"image,omitempty"`
+		DatePublished string           `json:"date_published"`
+		Tags          []string         `json:"tags,omitempty"`
+		Attachments   []jsonAttachment `json:"attachments,omitempty"`
+	}
+
+	jsonAttachment struct {
+		URL      string `json:"url"`
+		MimeType string `json:"mime_type"`
+	}
+)
+
+// Atom returns Atom 1.0 document of the feed
+func (f Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		Title:   f.Title,
+		ID:      f.FeedURL,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "alternate", Href: f.Link},
+			{Rel: "self", Type: "application/atom+xml", Href: f.FeedURL},
+		},
+	}
+	if f.HubURL != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "hub", Href: f.HubURL})
+	}
+	for _, item := range f.Items {
+		published := item.Published.UTC().Format(time.RFC3339)
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.ID,
+			Updated:   published,
+			Published: published,
+			Links:     []atomLink{{Rel: "alternate", Href: item.URL}},
+		}
+		for _, u := range item.mediaURLs() {
+			entry.Links = append(entry.Links, atomLink{Rel: "enclosure", Type: mediaType(u), Href: u})
+		}
+		if item.Category != "" {
+			entry.Category = &atomCategory{Term: item.Category}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshalXML(doc)
+}
+
+// RSS returns RSS 2.0 document of the feed
+// an item of RSS has an enclosure at most, so the first image or video is used
+func (f Feed) RSS() ([]byte, error) {
+	doc := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Title,
+			AtomLinks:     []atomLink{{Rel: "self", Type: "application/rss+xml", Href: f.FeedURL}},
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		},
+	}
+	if f.HubURL != "" {
+		doc.Channel.AtomLinks = append(doc.Channel.AtomLinks, atomLink{Rel: "hub", Href: f.HubURL})
+	}
+	for _, item := range f.Items {
+		v := rssItem{
+			Title:    item.Title,
+			Link:     item.URL,
+			GUID:     rssGUID{Value: item.ID},
+			PubDate:  item.Published.UTC().Format(time.RFC1123Z),
+			Category: item.Category,
+		}
+		if urls := item.mediaURLs(); len(urls) > 0 {
+			v.Enclosure = &rssEnclosure{URL: urls[0], Type: mediaType(urls[0])}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, v)
+	}
+	return marshalXML(doc)
+}
+
+// JSON returns JSON Feed 1.1 document of the feed
+func (f Feed) JSON() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Items:       []jsonItem{},
+	}
+	if f.HubURL != "" {
+		doc.Hubs = []jsonHub{{Type: "WebSub", URL: f.HubURL}}
+	}
+	for _, item := range f.Items {
+		v := jsonItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentText:   item.Title,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+		}
+		if len(item.ImageURLs) > 0 {
+			v.Image = item.ImageURLs[0]
+		}
+		if item.Category != "" {
+			v.Tags = []string{item.Category}
+		}
+		for _, u := range item.mediaURLs() {
+			v.Attachments = append(v.Attachments, jsonAttachment{URL: u, MimeType: mediaType(u)})
+		}
+		doc.Items = append(doc.Items, v)
+	}
+	return json.Marshal(doc)
+}
+
+// Encoding returns the content type and the encoder given the extension of the document (e.g. ".atom")
+func Encoding(ext string) (string, func(Feed) ([]byte, error), bool) {
+	switch ext {
+	case ".atom":
+		return ContentTypeAtom, Feed.Atom, true
+	case ".rss":
+		return ContentTypeRSS, Feed.RSS, true
+	case ".json":
+		return ContentTypeJSON, Feed.JSON, true
+	}
+	return "", nil, false
+}
+
+func (i Item) mediaURLs() []string {
+	return append(append([]string{}, i.ImageURLs...), i.VideoURLs...)
+}
+
+// mediaType returns the mime type guessed by the extension of the url
+func mediaType(u string) string {
+	ext := path.Ext(strings.SplitN(u, "?", 2)[0])
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	switch strings.ToLower(ext) {
+	case ".mp4":
+		return "video/mp4"
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	}
+	return "image/jpeg"
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
@@ -0,0 +1,138 @@
+package syndication
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	published := time.Date(2017, 11, 1, 20, 0, 0, 0, time.UTC)
+	return Feed{
+		Title:   "feed title",
+		Link:    "http://localhost/",
+		FeedURL: "http://localhost/feed.atom",
+		Updated: published,
+		Items: []Item{
+			{
+				ID:        "http://localhost/entry-2&t=20171101200000",
+				Title:     "entry 2",
+				URL:       "http://localhost/entry-2",
+				Category:  "momota-sd",
+				Published: published,
+				ImageURLs: []string{"http://localhost/2.jpg", "http://localhost/2.png?size=l"},
+				VideoURLs: []string{"http://localhost/2.mp4"},
+			},
+			{ID: "http://localhost/entry-1", Title: "entry 1 & <b>", URL: "http://localhost/entry-1", Published: published.Add(-time.Hour)},
+		},
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	b, err := testFeed().Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "<?xml") {
+		t.Errorf("Expected xml header, got %s", b)
+	}
+
+	var doc atomFeed
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "feed title" || doc.Updated != "2017-11-01T20:00:00Z" || len(doc.Entries) != 2 {
+		t.Fatalf("Unexpected feed %v", doc)
+	}
+	entry := doc.Entries[0]
+	if len(entry.Links) != 4 || entry.Links[1].Rel != "enclosure" || entry.Links[2].Type != "image/png" || entry.Links[3].Type != "video/mp4" {
+		t.Errorf("Expected enclosures of images and video, got %v", entry.Links)
+	}
+	if entry.Category == nil || entry.Category.Term != "momota-sd" {
+		t.Errorf("Expected category momota-sd, got %v", entry.Category)
+	}
+	if doc.Entries[1].Title != "entry 1 & <b>" || doc.Entries[1].Category != nil {
+		t.Errorf("Unexpected entry %v", doc.Entries[1])
+	}
+}
+
+func TestFeed_RSS(t *testing.T) {
+	b, err := testFeed().RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc rssFeed
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != "2.0" || len(doc.Channel.Items) != 2 {
+		t.Fatalf("Unexpected feed %v", doc)
+	}
+	item := doc.Channel.Items[0]
+	if item.Enclosure == nil || item.Enclosure.URL != "http://localhost/2.jpg" || item.Enclosure.Type != "image/jpeg" {
+		t.Errorf("Expected enclosure of the first image, got %v", item.Enclosure)
+	}
+	if item.PubDate != "Wed, 01 Nov 2017 20:00:00 +0000" || item.GUID.Value != "http://localhost/entry-2&t=20171101200000" {
+		t.Errorf("Unexpected item %v", item)
+	}
+	if doc.Channel.Items[1].Enclosure != nil {
+		t.Errorf("Expected no enclosure, got %v", doc.Channel.Items[1].Enclosure)
+	}
+}
+
+func TestFeed_JSON(t *testing.T) {
+	b, err := testFeed().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc jsonFeed
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != "https://jsonfeed.org/version/1.1" || doc.FeedURL != "http://localhost/feed.atom" || len(doc.Items) != 2 {
+		t.Fatalf("Unexpected feed %v", doc)
+	}
+	item := doc.Items[0]
+	if item.Image != "http://localhost/2.jpg" || len(item.Attachments) != 3 || item.Tags[0] != "momota-sd" {
+		t.Errorf("Unexpected item %v", item)
+	}
+
+	b, err = Feed{}.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"items":[]`) {
+		t.Errorf("Expected empty items, got %s", b)
+	}
+}
+
+func TestFeed_HubURL(t *testing.T) {
+	f := testFeed()
+	f.HubURL = "http://localhost/websub/hub"
+
+	for _, encode := range []func(Feed) ([]byte, error){Feed.Atom, Feed.RSS} {
+		b, err := encode(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), `rel="hub" href="http://localhost/websub/hub"`) {
+			t.Errorf("Expected hub link, got %s", b)
+		}
+	}
+
+	b, err := f.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc jsonFeed
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Hubs) != 1 || doc.Hubs[0].Type != "WebSub" || doc.Hubs[0].URL != f.HubURL {
+		t.Errorf("Expected WebSub hub, got %v", doc.Hubs)
+	}
+}
@@ -0,0 +1,27 @@
+package telegram
+
+// StartMessage returns message on /start
+func StartMessage() string {
+	return `Thanks for subscribing! (・Θ・)
+You will receive the updates of Momoiro Clover Z blogs, AE NEWS and more.
+
+Send a member's name (e.g. 百田夏菜子, かなこ) to get a photo.
+Send /stop to unsubscribe.`
+}
+
+// StopMessage returns message on /stop
+func StopMessage() string {
+	return "Unsubscribed. Send /start to subscribe again (・Θ・)"
+}
+
+// HelpMessage returns help message
+func HelpMessage() string {
+	return `/start - subscribe the updates
+/stop - unsubscribe the updates
+member's name (e.g. 百田夏菜子, かなこ) - get a photo`
+}
+
+// ImageNotFoundMessage returns image not found message
+func ImageNotFoundMessage() string {
+	return "No image found (・Θ・)"
+}
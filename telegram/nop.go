@@ -0,0 +1,22 @@
+package telegram
+
+import "context"
+
+type nop struct{}
+
+// NewNop returns no operation client
+func NewNop() Client {
+	return &nop{}
+}
+
+func (c *nop) SendText(_ context.Context, _ int64, _ string) error {
+	return nil
+}
+
+func (c *nop) SendPhoto(_ context.Context, _ int64, _, _ string) error {
+	return nil
+}
+
+func (c *nop) Send(_ context.Context, _ int64, _ Message, _ int) error {
+	return nil
+}
@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+)
+
+type (
+	// Update represents an incoming update of the bot
+	Update struct {
+		UpdateID int64          `json:"update_id"`
+		Message  *UpdateMessage `json:"message"`
+	}
+
+	// UpdateMessage represents a message that is sent to the bot
+	UpdateMessage struct {
+		MessageID int64  `json:"message_id"`
+		Chat      Chat   `json:"chat"`
+		Text      string `json:"text"`
+	}
+
+	// Chat represents a chat of the message
+	Chat struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"`
+	}
+)
+
+const (
+	CommandStart = "/start"
+	CommandStop  = "/stop"
+	CommandHelp  = "/help"
+)
+
+var (
+	// ErrInvalidSecretToken is returned when the update is not sent by Telegram
+	ErrInvalidSecretToken = errors.New("mcz: invalid telegram secret token")
+)
+
+// ParseRequest parses http request of the webhook
+// the request must have the secret token that is registered by setWebhook
+func ParseRequest(r *http.Request) (Update, error) {
+	token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+	secret := config.C().Telegram.SecretToken
+	if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return Update{}, ErrInvalidSecretToken
+	}
+
+	var u Update
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		return Update{}, err
+	}
+	return u, nil
+}
+
+// Command returns the bot command of the text (e.g. "/start@momoclo_bot" returns "/start")
+// it returns empty if the text is not a command
+func Command(text string) string {
+	if !strings.HasPrefix(text, "/") {
+		return ""
+	}
+	cmd := strings.Fields(text)[0]
+	if i := strings.Index(cmd, "@"); i >= 0 {
+		cmd = cmd[:i]
+	}
+	return strings.ToLower(cmd)
+}
@@ -0,0 +1,219 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"google.golang.org/appengine/urlfetch"
+)
+
+const (
+	// MaxMediaGroupNum is the max number of photos in an album
+	MaxMediaGroupNum = 10
+
+	// MaxCaptionLength is the max length of the caption of photos
+	MaxCaptionLength = 1024
+
+	// MaxTextLength is the max length of the text message
+	MaxTextLength = 4096
+)
+
+type (
+	// Message represents a message that is sent to a chat
+	// the photos are sent as albums, and the text is the caption of the first photo
+	Message struct {
+		Text      string   `validate:"required"`
+		ImageURLs []string `validate:"dive,url"`
+	}
+
+	// Request represents request that sends message to a subscriber
+	Request struct {
+		ID      string `validate:"required"` // id of the subscriber
+		ChatID  int64  `validate:"required"`
+		Message Message
+		Offset  int // number of the photos that have already been sent
+	}
+
+	// Client interface
+	Client interface {
+		SendText(context.Context, int64, string) error
+		SendPhoto(context.Context, int64, string, string) error
+		Send(context.Context, int64, Message, int) error
+	}
+
+	// RateLimitError represents the response that is rate limited
+	RateLimitError struct {
+		RetryAfter time.Duration
+		Offset     int // offset of the photos that have not been sent by Send
+	}
+
+	client struct {
+		baseURL    string
+		httpClient func(context.Context) *http.Client
+	}
+
+	inputMedia struct {
+		Type    string `json:"type"`
+		Media   string `json:"media"`
+		Caption string `json:"caption,omitempty"`
+	}
+
+	response struct {
+		OK          bool   `json:"ok"`
+		ErrorCode   int    `json:"error_code"`
+		Description string `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+)
+
+var (
+	// ErrForbidden is returned when the bot is blocked by the user or the chat is not found
+	ErrForbidden = errors.New("mcz: telegram forbidden")
+)
+
+// New returns Client that calls Telegram Bot API
+func New() Client {
+	c := config.C().Telegram
+	if c.Disabled {
+		return NewNop()
+	}
+	return NewWithHTTPClient("https://api.telegram.org/bot"+c.BotToken, urlfetch.Client)
+}
+
+// NewWithHTTPClient returns Client that calls the API of given base url (e.g. https://api.telegram.org/bot<token>) through given http client
+func NewWithHTTPClient(baseURL string, fn func(context.Context) *http.Client) Client {
+	return &client{baseURL: baseURL, httpClient: fn}
+}
+
+// Error implements error
+func (e *RateLimitError) Error() string {
+	return "mcz: telegram rate limited retry after:" + e.RetryAfter.String()
+}
+
+// SendText sends text message to the chat
+func (c *client) SendText(ctx context.Context, chatID int64, text string) error {
+	return c.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id": chatID,
+		"text":    truncate(text, MaxTextLength),
+	})
+}
+
+// SendPhoto sends a photo with caption to the chat
+func (c *client) SendPhoto(ctx context.Context, chatID int64, photoURL, caption string) error {
+	params := map[string]interface{}{
+		"chat_id": chatID,
+		"photo":   photoURL,
+	}
+	if caption != "" {
+		params["caption"] = truncate(caption, MaxCaptionLength)
+	}
+	return c.call(ctx, "sendPhoto", params)
+}
+
+// Send sends the message to the chat
+// the photos are sent as albums of up to 10 photos by sendMediaGroup, a single photo is sent by sendPhoto
+// the photos are sent from given offset, and RateLimitError has the offset to resume the albums that have not been sent
+func (c *client) Send(ctx context.Context, chatID int64, msg Message, offset int) error {
+	if len(msg.ImageURLs) == 0 {
+		return c.SendText(ctx, chatID, msg.Text)
+	}
+
+	caption := msg.Text
+	if offset > 0 {
+		caption = "" // the caption has been sent with the first album
+	}
+	for i := offset; i < len(msg.ImageURLs); i += MaxMediaGroupNum {
+		last := i + MaxMediaGroupNum
+		if last > len(msg.ImageURLs) {
+			last = len(msg.ImageURLs)
+		}
+		if err := c.sendAlbum(ctx, chatID, msg.ImageURLs[i:last], caption); err != nil {
+			if e, ok := err.(*RateLimitError); ok {
+				e.Offset = i
+			}
+			return err
+		}
+		caption = ""
+	}
+	return nil
+}
+
+// sendAlbum sends the photos as an album with caption
+func (c *client) sendAlbum(ctx context.Context, chatID int64, urls []string, caption string) error {
+	if len(urls) == 1 {
+		return c.SendPhoto(ctx, chatID, urls[0], caption)
+	}
+
+	media := make([]inputMedia, len(urls))
+	for j, u := range urls {
+		media[j] = inputMedia{Type: "photo", Media: u}
+	}
+	media[0].Caption = truncate(caption, MaxCaptionLength)
+	return c.call(ctx, "sendMediaGroup", map[string]interface{}{
+		"chat_id": chatID,
+		"media":   media,
+	})
+}
+
+func (c *client) call(ctx context.Context, method string, params interface{}) error {
+	errTag := fmt.Sprintf("telegram.%s failed", method)
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/"+method, bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient(ctx).Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var res response
+	if err := json.Unmarshal(body, &res); err != nil {
+		return errors.Errorf("%v: status:%v body:%s", errTag, resp.StatusCode, body)
+	}
+	if res.OK {
+		return nil
+	}
+
+	switch {
+	case res.ErrorCode == http.StatusForbidden:
+		return ErrForbidden
+	case res.ErrorCode == http.StatusBadRequest && res.Description == "Bad Request: chat not found":
+		return ErrForbidden
+	case res.ErrorCode == http.StatusTooManyRequests:
+		retryAfter := time.Duration(res.Parameters.RetryAfter) * time.Second
+		if retryAfter <= 0 {
+			retryAfter = 5 * time.Second
+		}
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	return errors.Errorf("%v: error_code:%v description:%v", errTag, res.ErrorCode, res.Description)
+}
+
+// truncate truncates the text to given length of characters
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
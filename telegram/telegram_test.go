@@ -0,0 +1,190 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/testutil"
+)
+
+type apiCall struct {
+	Method string
+	Params map[string]interface{}
+}
+
+func newTestServer(t *testing.T, res string, calls *[]apiCall) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Fatal(err)
+		}
+		*calls = append(*calls, apiCall{Method: strings.TrimPrefix(r.URL.Path, "/bottoken/"), Params: params})
+		w.Write([]byte(res))
+	}))
+}
+
+func TestClient_Send(t *testing.T) {
+	var calls []apiCall
+	s := newTestServer(t, `{"ok":true,"result":{}}`, &calls)
+	defer s.Close()
+	c := NewWithHTTPClient(s.URL+"/bottoken", func(context.Context) *http.Client { return http.DefaultClient })
+
+	tests := []struct {
+		imageNum int
+		methods  []string
+	}{
+		{0, []string{"sendMessage"}},
+		{1, []string{"sendPhoto"}},
+		{10, []string{"sendMediaGroup"}},
+		{11, []string{"sendMediaGroup", "sendPhoto"}},
+		{13, []string{"sendMediaGroup", "sendMediaGroup"}},
+	}
+	for _, test := range tests {
+		calls = nil
+		msg := Message{Text: "entry title"}
+		for i := 0; i < test.imageNum; i++ {
+			msg.ImageURLs = append(msg.ImageURLs, "http://localhost/img.jpg")
+		}
+		if err := c.Send(context.Background(), 100, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(calls) != len(test.methods) {
+			t.Fatalf("Expected calls %v given %v images, got %v", test.methods, test.imageNum, calls)
+		}
+		for i, call := range calls {
+			if call.Method != test.methods[i] {
+				t.Errorf("Expected method %v, got %v", test.methods[i], call.Method)
+			}
+			if call.Params["chat_id"] != float64(100) {
+				t.Errorf("Expected chat_id 100, got %v", call.Params["chat_id"])
+			}
+		}
+		if test.imageNum >= 10 {
+			media := calls[0].Params["media"].([]interface{})
+			if len(media) != 10 || media[0].(map[string]interface{})["caption"] != "entry title" {
+				t.Errorf("Expected album of 10 photos with caption, got %v", media)
+			}
+		}
+		if test.imageNum == 11 {
+			if v, ok := calls[1].Params["caption"]; ok {
+				t.Errorf("Expected no caption in the following photos, got %v", v)
+			}
+		}
+	}
+}
+
+func TestClient_SendOffset(t *testing.T) {
+	var calls []apiCall
+	limited := true
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Fatal(err)
+		}
+		calls = append(calls, apiCall{Method: strings.TrimPrefix(r.URL.Path, "/bottoken/"), Params: params})
+		if len(calls) == 2 && limited {
+			w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":3}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer s.Close()
+	c := NewWithHTTPClient(s.URL+"/bottoken", func(context.Context) *http.Client { return http.DefaultClient })
+
+	msg := Message{Text: "entry title"}
+	for i := 0; i < 25; i++ {
+		msg.ImageURLs = append(msg.ImageURLs, "http://localhost/img.jpg")
+	}
+
+	// the second album is rate limited after the first album is sent
+	err := c.Send(context.Background(), 100, msg, 0)
+	e, ok := err.(*RateLimitError)
+	if !ok {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	if e.Offset != 10 {
+		t.Errorf("Expected offset 10, got %v", e.Offset)
+	}
+
+	// resumes from the second album without caption
+	calls = nil
+	limited = false
+	if err := c.Send(context.Background(), 100, msg, e.Offset); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 albums, got %v", calls)
+	}
+	for i, n := range []int{10, 5} {
+		media := calls[i].Params["media"].([]interface{})
+		if len(media) != n {
+			t.Errorf("Expected album of %v photos, got %v", n, media)
+		}
+		if v, ok := media[0].(map[string]interface{})["caption"]; ok {
+			t.Errorf("Expected no caption in the resumed albums, got %v", v)
+		}
+	}
+}
+
+func TestClient_SendError(t *testing.T) {
+	tests := []struct {
+		res        string
+		retryAfter time.Duration
+		forbidden  bool
+	}{
+		{`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`, 0, true},
+		{`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`, 0, true},
+		{`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":7}}`, 7 * time.Second, false},
+		{`{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier"}`, 0, false},
+	}
+
+	for _, test := range tests {
+		var calls []apiCall
+		s := newTestServer(t, test.res, &calls)
+		c := NewWithHTTPClient(s.URL+"/bottoken", func(context.Context) *http.Client { return http.DefaultClient })
+
+		err := c.SendText(context.Background(), 100, "hello")
+		s.Close()
+		if err == nil {
+			t.Fatalf("Expected error given %v", test.res)
+		}
+		if (err == ErrForbidden) != test.forbidden {
+			t.Errorf("Expected forbidden %v, got %v", test.forbidden, err)
+		}
+		if e, ok := err.(*RateLimitError); ok != (test.retryAfter > 0) || (ok && e.RetryAfter != test.retryAfter) {
+			t.Errorf("Expected retry after %v, got %v", test.retryAfter, err)
+		}
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	testutil.MustConfigLoad()
+
+	body := `{"update_id":1,"message":{"message_id":2,"chat":{"id":-100,"type":"group"},"text":"/start@momoclo_bot"}}`
+	req := httptest.NewRequest(http.MethodPost, "/telegram/webhook", strings.NewReader(body))
+	if _, err := ParseRequest(req); err != ErrInvalidSecretToken {
+		t.Errorf("Expected ErrInvalidSecretToken, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/telegram/webhook", strings.NewReader(body))
+	req.Header.Set("X-Telegram-Bot-Api-Secret-Token", "telegram-secret")
+	u, err := ParseRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Message == nil || u.Message.Chat.ID != -100 {
+		t.Fatalf("Unexpected update %v", u)
+	}
+	if cmd := Command(u.Message.Text); cmd != CommandStart {
+		t.Errorf("Expected command %v, got %v", CommandStart, cmd)
+	}
+	if cmd := Command("かなこ"); cmd != "" {
+		t.Errorf("Expected no command, got %v", cmd)
+	}
+}
@@ -7,18 +7,59 @@ import (
 	"github.com/utahta/momoclo-channel/config"
 )
 
+const baseConfig = `
+[LineNotify]
+  TokenKey = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+  SigningKey = "iiiiiiiiiiiiiiiiiiiiiiiiiiiiiiii"
+
+[Discord]
+  TokenKey = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+
+[Slack]
+  TokenKey = "cccccccccccccccccccccccccccccccc"
+
+[Bluesky]
+  Identifier = "momoclo.bsky.social"
+  TokenKey = "dddddddddddddddddddddddddddddddd"
+
+[Mail]
+  From = "momoclo@example.com"
+  SigningKey = "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"
+
+[Webhook]
+  TokenKey = "ffffffffffffffffffffffffffffffff"
+
+[WebSub]
+  TokenKey = "gggggggggggggggggggggggggggggggg"
+
+[WebPush]
+  Subject = "mailto:admin@localhost"
+  TokenKey = "hhhhhhhhhhhhhhhhhhhhhhhhhhhhhhhh"
+
+[Telegram]
+  SecretToken = "telegram-secret"
+
+[Crawler]
+  CatchUpLimit = 10
+  DetectEdits = true
+  ImageAddedNotice = "写真が追加されました"
+  VideoAddedNotice = "動画が追加されました"
+`
+
 // MustConfigLoad loads config file for test
 func MustConfigLoad() {
+	MustConfigLoadWith("")
+}
+
+// MustConfigLoadWith loads config file for test that is appended given settings (e.g. [[Feeds]])
+func MustConfigLoadWith(settings string) {
 	tmpfile, err := ioutil.TempFile("", "test")
 	if err != nil {
 		panic(err)
 	}
 	defer os.Remove(tmpfile.Name())
 
-	_, err = tmpfile.WriteString(`
-[LineNotify]
-  TokenKey = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
-`)
+	_, err = tmpfile.WriteString(baseConfig + settings)
 	if err != nil {
 		panic(err)
 	}
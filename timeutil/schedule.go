@@ -0,0 +1,214 @@
+package timeutil
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// Schedule represents a crawl schedule that is evaluated per minute in JST
+	Schedule interface {
+		Match(time.Time) bool
+	}
+
+	// Window represents an active time window in a day in JST (e.g. 17:00-18:00)
+	Window struct {
+		Begin int // minutes from 00:00
+		End   int // minutes from 00:00, exclusive
+	}
+
+	// cronSchedule represents standard cron expression (minute hour day-of-month month day-of-week)
+	cronSchedule struct {
+		minute  fieldSet
+		hour    fieldSet
+		dom     fieldSet
+		month   fieldSet
+		dow     fieldSet
+		domStar bool
+		dowStar bool
+	}
+
+	// intervalSchedule represents a schedule that matches every interval from 00:00
+	// the interval divides 24h, so that it is the same every day
+	intervalSchedule struct {
+		interval int // minutes
+	}
+
+	fieldSet map[int]bool
+)
+
+var scheduleDescriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseSchedule parses a cron expression (e.g. "*/5 * * * *") or an interval (e.g. "@every 10m")
+// an empty spec matches every minute
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return &intervalSchedule{interval: 1}, nil
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule:%v", spec)
+		}
+		if d < time.Minute || d%time.Minute != 0 {
+			return nil, errors.Errorf("invalid schedule:%v interval must be a multiple of minute", spec)
+		}
+		if (24*time.Hour)%d != 0 {
+			return nil, errors.Errorf("invalid schedule:%v interval must divide 24h", spec)
+		}
+		return &intervalSchedule{interval: int(d / time.Minute)}, nil
+	}
+
+	if v, ok := scheduleDescriptors[spec]; ok {
+		spec = v
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("invalid schedule:%v expected 5 fields", spec)
+	}
+
+	var (
+		s   = &cronSchedule{}
+		err error
+	)
+	if s.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, errors.Wrapf(err, "invalid schedule:%v", spec)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, errors.Wrapf(err, "invalid schedule:%v", spec)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, errors.Wrapf(err, "invalid schedule:%v", spec)
+	}
+	if s.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, errors.Wrapf(err, "invalid schedule:%v", spec)
+	}
+	if s.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, errors.Wrapf(err, "invalid schedule:%v", spec)
+	}
+	if s.dow[7] {
+		s.dow[0] = true // 7 is also Sunday
+	}
+	s.domStar = fields[2] == "*"
+	s.dowStar = fields[4] == "*"
+	return s, nil
+}
+
+// Match returns true if given time matches the cron expression
+func (s *cronSchedule) Match(t time.Time) bool {
+	t = t.In(JST())
+	if !s.minute[t.Minute()] || !s.hour[t.Hour()] || !s.month[int(t.Month())] {
+		return false
+	}
+
+	// same as cron(8), if both day fields are restricted, either field matches
+	dom, dow := s.dom[t.Day()], s.dow[int(t.Weekday())]
+	if !s.domStar && !s.dowStar {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Match returns true if given time is on the interval from 00:00
+func (s *intervalSchedule) Match(t time.Time) bool {
+	t = t.In(JST())
+	return (t.Hour()*60+t.Minute())%s.interval == 0
+}
+
+// parseField parses a cron field that consists of list, range and step
+func parseField(field string, min, max int) (fieldSet, error) {
+	set := fieldSet{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			v, err := strconv.Atoi(part[i+1:])
+			if err != nil || v <= 0 {
+				return nil, errors.Errorf("invalid step:%v", part)
+			}
+			step = v
+			part = part[:i]
+		}
+
+		begin, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			r := strings.SplitN(part, "-", 2)
+			var err error
+			if begin, err = strconv.Atoi(r[0]); err != nil {
+				return nil, errors.Errorf("invalid range:%v", part)
+			}
+			if end, err = strconv.Atoi(r[1]); err != nil {
+				return nil, errors.Errorf("invalid range:%v", part)
+			}
+		default:
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, errors.Errorf("invalid value:%v", part)
+			}
+			begin = v
+			if step == 1 {
+				end = v
+			}
+		}
+
+		if begin < min || end > max || begin > end {
+			return nil, errors.Errorf("out of range:%v", part)
+		}
+		for v := begin; v <= end; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// ParseWindow parses active window (e.g. "17:00-18:00")
+// the window that ends before it begins is across midnight (e.g. "23:00-02:00")
+func ParseWindow(s string) (Window, error) {
+	r := strings.SplitN(strings.TrimSpace(s), "-", 2)
+	if len(r) != 2 {
+		return Window{}, errors.Errorf("invalid window:%v", s)
+	}
+
+	begin, err := parseClock(r[0])
+	if err != nil {
+		return Window{}, errors.Wrapf(err, "invalid window:%v", s)
+	}
+	end, err := parseClock(r[1])
+	if err != nil {
+		return Window{}, errors.Wrapf(err, "invalid window:%v", s)
+	}
+	return Window{Begin: begin, End: end}, nil
+}
+
+// Contains returns true if given time is in the window
+func (w Window) Contains(t time.Time) bool {
+	t = t.In(JST())
+	m := t.Hour()*60 + t.Minute()
+	if w.Begin <= w.End {
+		return w.Begin <= m && m < w.End
+	}
+	return w.Begin <= m || m < w.End
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		if strings.TrimSpace(s) == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,100 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, JST())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		spec     string
+		t        time.Time
+		expected bool
+	}{
+		{"", at("2008-05-17 12:34"), true},
+		{"* 17 * * 0", at("2008-05-18 17:00"), true},
+		{"* 17 * * 0", at("2008-05-18 17:59"), true},
+		{"* 17 * * 7", at("2008-05-18 17:30"), true},
+		{"* 17 * * 0", at("2008-05-18 18:00"), false},
+		{"* 17 * * 0", at("2008-05-17 17:00"), false},
+		{"*/15 * * * *", at("2008-05-17 12:45"), true},
+		{"*/15 * * * *", at("2008-05-17 12:46"), false},
+		{"0,30 9-18 * * 1-5", at("2008-05-19 09:30"), true},
+		{"0,30 9-18 * * 1-5", at("2008-05-17 09:30"), false},
+		{"0 0 17 5 *", at("2008-05-17 00:00"), true},
+		{"0 0 1 * 6", at("2008-05-17 00:00"), true}, // either day field matches
+		{"@daily", at("2008-05-17 00:00"), true},
+		{"@daily", at("2008-05-17 00:01"), false},
+		{"@every 10m", at("2008-05-17 12:40"), true},
+		{"@every 10m", at("2008-05-17 12:41"), false},
+		{"@every 1h", at("2008-05-17 13:00"), true},
+		{"@every 90m", at("2008-05-17 01:30"), true},
+		{"* 17 * * 0", at("2008-05-18 17:00").UTC(), true}, // evaluated in JST
+	}
+
+	for _, test := range tests {
+		s, err := ParseSchedule(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Match(test.t) != test.expected {
+			t.Errorf("Expected %v, got %v. spec:%v t:%v", test.expected, !test.expected, test.spec, test.t)
+		}
+	}
+
+	invalids := []string{"* * * *", "60 * * * *", "* * * * 8", "*/0 * * * *", "a * * * *", "@every 30s", "@every 90s", "@every 7m", "@every 25h", "@every x"}
+	for _, spec := range invalids {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("Expected error, got nil. spec:%v", spec)
+		}
+	}
+}
+
+func TestWindow_Contains(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("15:04", s, JST())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		window   string
+		t        time.Time
+		expected bool
+	}{
+		{"17:00-18:00", at("17:00"), true},
+		{"17:00-18:00", at("17:59"), true},
+		{"17:00-18:00", at("18:00"), false},
+		{"17:00-18:00", at("16:59"), false},
+		{"23:00-02:00", at("23:30"), true},
+		{"23:00-02:00", at("01:59"), true},
+		{"23:00-02:00", at("02:00"), false},
+		{"07:00-24:00", at("23:59"), true},
+	}
+
+	for _, test := range tests {
+		w, err := ParseWindow(test.window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.Contains(test.t) != test.expected {
+			t.Errorf("Expected %v, got %v. window:%v t:%v", test.expected, !test.expected, test.window, test.t)
+		}
+	}
+
+	for _, s := range []string{"17:00", "17-18", "25:00-26:00"} {
+		if _, err := ParseWindow(s); err == nil {
+			t.Errorf("Expected error, got nil. window:%v", s)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// AddDiscordWebhook use case
+	AddDiscordWebhook struct {
+		log  log.Logger
+		repo entity.DiscordWebhookRepository
+	}
+
+	// AddDiscordWebhookParams input parameters
+	AddDiscordWebhookParams struct {
+		URL string `validate:"required,url"`
+	}
+)
+
+// discordWebhookPrefixes are the url prefixes of Discord webhooks
+var discordWebhookPrefixes = []string{
+	"https://discord.com/api/webhooks/",
+	"https://discordapp.com/api/webhooks/",
+}
+
+// NewAddDiscordWebhook returns AddDiscordWebhook use case
+func NewAddDiscordWebhook(log log.Logger, repo entity.DiscordWebhookRepository) *AddDiscordWebhook {
+	return &AddDiscordWebhook{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do stores the webhook url encrypted
+func (use *AddDiscordWebhook) Do(ctx context.Context, params AddDiscordWebhookParams) error {
+	const errTag = "AddDiscordWebhook.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	var valid bool
+	for _, prefix := range discordWebhookPrefixes {
+		if strings.HasPrefix(params.URL, prefix) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return errors.Errorf("%v: invalid webhook url", errTag)
+	}
+
+	w, err := entity.NewDiscordWebhook(config.C().Discord.TokenKey, params.URL)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if err := use.repo.Save(ctx, w); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "add discord webhook id:%v", w.ID)
+
+	return nil
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/config"
 	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/linebot"
 	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
 )
@@ -13,30 +16,38 @@ import (
 type (
 	// AddLineNotification use case
 	AddLineNotification struct {
-		log   log.Logger
-		token linenotify.Token
-		repo  entity.LineNotificationRepository
+		log       log.Logger
+		taskQueue event.TaskQueue
+		token     linenotify.Token
+		repo      entity.LineNotificationRepository
 	}
 
 	// AddLineNotificationParams use case params
 	AddLineNotificationParams struct {
 		Code string
+
+		// UserHash and UserToken are the signed LINE bot user who requested the connection, they may be empty
+		UserHash  string
+		UserToken string
 	}
 )
 
 // NewAddLineNotification returns AddLineNotification use case
 func NewAddLineNotification(
 	logger log.Logger,
+	taskQueue event.TaskQueue,
 	token linenotify.Token,
 	repo entity.LineNotificationRepository) *AddLineNotification {
 	return &AddLineNotification{
-		log:   logger,
-		token: token,
-		repo:  repo,
+		log:       logger,
+		taskQueue: taskQueue,
+		token:     token,
+		repo:      repo,
 	}
 }
 
 // Do add line notification entity
+// the link of preferences page is notified to the token
 func (use *AddLineNotification) Do(ctx context.Context, params AddLineNotificationParams) error {
 	const errTag = "AddLineNotification.Do failed"
 
@@ -49,11 +60,27 @@ func (use *AddLineNotification) Do(ctx context.Context, params AddLineNotificati
 	if err != nil {
 		return errors.Wrap(err, errTag)
 	}
+	if params.UserHash != "" {
+		if entity.VerifyLineUserToken(config.C().LineNotify.SigningKey, params.UserHash, params.UserToken) {
+			ln.UserHash = params.UserHash
+		} else {
+			use.log.Warningf(ctx, "%v: invalid user token id:%v", errTag, ln.ID)
+		}
+	}
 
 	if err := use.repo.Save(ctx, ln); err != nil {
 		return errors.Wrap(err, errTag)
 	}
-
 	use.log.Infof(ctx, "added LineNotification. id:%v", ln.ID)
+
+	preferencesURL := linenotify.PreferencesURL(ln.ID, ln.PreferencesToken(config.C().LineNotify.SigningKey))
+	task := eventtask.NewLine(linenotify.Request{
+		ID:          ln.ID,
+		AccessToken: token,
+		Messages:    []linenotify.Message{{Text: "\n" + linebot.ConnectedMessage(preferencesURL)}},
+	})
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
 	return nil
 }
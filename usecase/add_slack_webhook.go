@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// AddSlackWebhook use case
+	AddSlackWebhook struct {
+		log  log.Logger
+		repo entity.SlackWebhookRepository
+	}
+
+	// AddSlackWebhookParams input parameters
+	AddSlackWebhookParams struct {
+		URL string `validate:"required,url"`
+
+		// Codes are feed codes or notification kinds (e.g. "momota-sd", "reminder") to deliver, all if empty
+		Codes []string `validate:"dive,required"`
+	}
+)
+
+// slackWebhookPrefix is the url prefix of Slack incoming webhooks
+const slackWebhookPrefix = "https://hooks.slack.com/services/"
+
+// NewAddSlackWebhook returns AddSlackWebhook use case
+func NewAddSlackWebhook(log log.Logger, repo entity.SlackWebhookRepository) *AddSlackWebhook {
+	return &AddSlackWebhook{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do stores the webhook url encrypted with the filter
+// the webhook that is already stored is overwritten
+func (use *AddSlackWebhook) Do(ctx context.Context, params AddSlackWebhookParams) error {
+	const errTag = "AddSlackWebhook.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if !strings.HasPrefix(params.URL, slackWebhookPrefix) {
+		return errors.Errorf("%v: invalid webhook url", errTag)
+	}
+
+	w, err := entity.NewSlackWebhook(config.C().Slack.TokenKey, params.URL, params.Codes)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if err := use.repo.Save(ctx, w); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "add slack webhook id:%v codes:%v", w.ID, w.Codes)
+
+	return nil
+}
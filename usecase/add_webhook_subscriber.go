@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// AddWebhookSubscriber use case
+	AddWebhookSubscriber struct {
+		log  log.Logger
+		repo entity.WebhookSubscriberRepository
+	}
+
+	// AddWebhookSubscriberParams input parameters
+	AddWebhookSubscriberParams struct {
+		URL string `validate:"required,url"`
+	}
+)
+
+// NewAddWebhookSubscriber returns AddWebhookSubscriber use case
+func NewAddWebhookSubscriber(log log.Logger, repo entity.WebhookSubscriberRepository) *AddWebhookSubscriber {
+	return &AddWebhookSubscriber{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do stores the subscriber with a new secret and returns it
+// the subscriber that is already stored is overwritten, so it is enabled again with the new secret
+func (use *AddWebhookSubscriber) Do(ctx context.Context, params AddWebhookSubscriberParams) (*entity.WebhookSubscriber, error) {
+	const errTag = "AddWebhookSubscriber.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return nil, errors.Wrap(err, errTag)
+	}
+
+	s, err := entity.NewWebhookSubscriber(config.C().Webhook.TokenKey, params.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, errTag)
+	}
+	if err := use.repo.Save(ctx, s); err != nil {
+		return nil, errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "add webhook subscriber id:%v", s.ID)
+
+	return s, nil
+}
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+// defaultBackfillMaxPages is used when the max pages is not given by config
+const defaultBackfillMaxPages = 100
+
+type (
+	// BackfillFeed use case
+	BackfillFeed struct {
+		log             log.Logger
+		archive         crawler.ArchiveFetcher
+		taskQueue       event.TaskQueue
+		transactor      dao.Transactor
+		latestRepo      entity.LatestEntryRepository
+		tweetItemRepo   entity.TweetItemRepository
+		lineItemRepo    entity.LineItemRepository
+		channelItemRepo entity.ChannelItemRepository
+	}
+
+	// BackfillFeedParams input parameters
+	BackfillFeedParams struct {
+		Request crawler.ArchiveRequest
+	}
+)
+
+// NewBackfillFeed returns BackfillFeed use case
+func NewBackfillFeed(
+	log log.Logger,
+	archive crawler.ArchiveFetcher,
+	taskQueue event.TaskQueue,
+	transactor dao.Transactor,
+	latestRepo entity.LatestEntryRepository,
+	tweetItemRepo entity.TweetItemRepository,
+	lineItemRepo entity.LineItemRepository,
+	channelItemRepo entity.ChannelItemRepository) *BackfillFeed {
+	return &BackfillFeed{
+		log:             log,
+		archive:         archive,
+		taskQueue:       taskQueue,
+		transactor:      transactor,
+		latestRepo:      latestRepo,
+		tweetItemRepo:   tweetItemRepo,
+		lineItemRepo:    lineItemRepo,
+		channelItemRepo: channelItemRepo,
+	}
+}
+
+// Do records a page of the feed archive as already delivered without notifying
+// it enqueues itself with the cursor of the next page until the archive ends
+func (use *BackfillFeed) Do(ctx context.Context, params BackfillFeedParams) error {
+	const errTag = "BackfillFeed.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	req := params.Request
+
+	page, err := use.archive.FetchArchive(ctx, req.Code, req.Cursor)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	var (
+		latest   *crawler.FeedItem
+		recorded int
+	)
+	for i, item := range page.Items {
+		if err := validator.Validate(item); err != nil {
+			use.log.Warningf(ctx, "%v: skip invalid item:%v err:%v", errTag, item, err)
+			continue
+		}
+
+		ok, err := use.record(ctx, item)
+		if err != nil {
+			return errors.Wrapf(err, "%v: url:%v", errTag, item.EntryURL)
+		}
+		if ok {
+			recorded++
+		}
+		if latest == nil || item.PublishedAt.After(latest.PublishedAt) {
+			latest = &page.Items[i]
+		}
+	}
+
+	if latest != nil {
+		if err := use.updateLatestEntry(ctx, req.Code, *latest); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+	}
+	use.log.Infof(ctx, "backfill feed code:%v page:%v items:%v recorded:%v", req.Code, req.Page, len(page.Items), recorded)
+
+	maxPages := crawlerSettings().BackfillMaxPages
+	if maxPages <= 0 {
+		maxPages = defaultBackfillMaxPages
+	}
+	if page.NextCursor == "" || req.Page+1 >= maxPages {
+		use.log.Infof(ctx, "backfill feed code:%v completed pages:%v", req.Code, req.Page+1)
+		return nil
+	}
+
+	task := eventtask.NewBackfillFeed(crawler.ArchiveRequest{
+		Code:   req.Code,
+		Cursor: page.NextCursor,
+		Page:   req.Page + 1,
+	})
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	return nil
+}
+
+// record saves line, tweet and channel items of given feed item if not exist
+// it returns true if the item is new to the line or tweet history, history is not recorded in dry-run mode
+func (use *BackfillFeed) record(ctx context.Context, item crawler.FeedItem) (bool, error) {
+	dryRun := dryrun.Enabled(ctx)
+	var recorded bool
+
+	lineItem := entity.NewLineItem(item.UniqueURL(), item.EntryTitle, item.EntryURL, item.PublishedAt, item.ImageURLs, item.VideoURLs)
+	err := use.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if _, err := use.lineItemRepo.Find(ctx, lineItem.ID); err != dao.ErrNoSuchEntity {
+			return err
+		}
+		recorded = true
+		if dryRun {
+			return nil
+		}
+		return use.lineItemRepo.Save(ctx, lineItem)
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	tweetItem := entity.NewTweetItem(item.UniqueURL(), item.EntryTitle, item.EntryURL, item.PublishedAt, item.ImageURLs, item.VideoURLs)
+	err = use.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		if _, err := use.tweetItemRepo.Find(ctx, tweetItem.ID); err != dao.ErrNoSuchEntity {
+			return err
+		}
+		recorded = true
+		if dryRun {
+			return nil
+		}
+		return use.tweetItemRepo.Save(ctx, tweetItem)
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	// the channels added later may not have the history of the item that is already in the line history
+	for _, channel := range historyChannels {
+		if _, err := recordChannelItem(ctx, use.transactor, use.channelItemRepo, channel, item); err != nil {
+			return false, err
+		}
+	}
+
+	return recorded, nil
+}
+
+// updateLatestEntry updates the latest entry if given item is newer
+// the latest entry is not updated in dry-run mode
+func (use *BackfillFeed) updateLatestEntry(ctx context.Context, code crawler.FeedCode, item crawler.FeedItem) error {
+	if dryrun.Enabled(ctx) {
+		return nil
+	}
+
+	l, err := use.latestRepo.FindOrNewByURL(ctx, code.String(), item.EntryURL)
+	if err != nil {
+		return err
+	}
+	if !l.PublishedAt.IsZero() && !item.PublishedAt.After(l.PublishedAt) {
+		return nil
+	}
+
+	l.URL = item.EntryURL
+	l.PublishedAt = item.PublishedAt
+	l.Fingerprint = entity.Fingerprint(item.EntryTitle, item.ImageURLs, item.VideoURLs)
+	return use.latestRepo.Save(ctx, l)
+}
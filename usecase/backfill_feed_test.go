@@ -0,0 +1,166 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/crawler/crawlertest"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestBackfillFeed_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoadWith(`
+[[Feeds]]
+  Code = "test-archive"
+  Source = "syndication"
+  URL = "http://archive.example.com/feed.xml"
+  URLPattern = "http://archive.example.com/"
+  Enabled = true
+`)
+	defer testutil.MustConfigLoad()
+
+	h := dao.NewDatastoreHandler()
+	taskQueue := eventtest.NewTaskQueue()
+	latestRepo := entity.NewLatestEntryRepository(h)
+	lineItemRepo := entity.NewLineItemRepository(h)
+	channelItemRepo := entity.NewChannelItemRepository(h)
+	u := usecase.NewBackfillFeed(
+		log.NewAELogger(),
+		crawlertest.NewArchiveFetcher("testdata/crawl"),
+		taskQueue,
+		dao.NewDatastoreTransactor(),
+		latestRepo,
+		entity.NewTweetItemRepository(h),
+		lineItemRepo,
+		channelItemRepo,
+	)
+
+	if err := u.Do(ctx, usecase.BackfillFeedParams{}); err == nil {
+		t.Error("Expected validation error, got nil")
+	}
+
+	// first page enqueues the next page
+	params := usecase.BackfillFeedParams{Request: crawler.ArchiveRequest{Code: crawler.FeedCode("test-archive")}}
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+	if taskQueue.Tasks[0].Path != "/backfill/feed" {
+		t.Errorf("Expected path /backfill/feed, got %v", taskQueue.Tasks[0].Path)
+	}
+	next := taskQueue.Tasks[0].Object.(crawler.ArchiveRequest)
+	if next.Cursor != "http://archive.example.com/feed.xml?page=2" || next.Page != 1 {
+		t.Errorf("Expected the cursor of the second page, got %v", next)
+	}
+	if latestURL := latestRepo.GetURL(ctx, "test-archive"); latestURL != "http://archive.example.com/entry/4" {
+		t.Errorf("Expected latest url entry/4, got %v", latestURL)
+	}
+
+	// last page
+	if err := u.Do(ctx, usecase.BackfillFeedParams{Request: next}); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 1 {
+		t.Errorf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+	if latestURL := latestRepo.GetURL(ctx, "test-archive"); latestURL != "http://archive.example.com/entry/4" {
+		t.Errorf("Expected latest url is not changed, got %v", latestURL)
+	}
+
+	// backfilled entries are regarded as delivered
+	enqueueLines := usecase.NewEnqueueLines(log.NewAELogger(), taskQueue, dao.NewDatastoreTransactor(), lineItemRepo)
+	page, err := crawlertest.NewArchiveFetcher("testdata/crawl").FetchArchive(ctx, "test-archive", next.Cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range page.Items {
+		if err := enqueueLines.Do(ctx, usecase.EnqueueLinesParams{FeedItem: item}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(taskQueue.Tasks) != 1 {
+		t.Errorf("Expected no line tasks, got %v", len(taskQueue.Tasks)-1)
+	}
+
+	// as well as the channels that have ChannelItem history
+	enqueueMastodon := usecase.NewEnqueueMastodon(log.NewAELogger(), taskQueue, dao.NewDatastoreTransactor(), channelItemRepo)
+	for _, item := range page.Items {
+		if err := enqueueMastodon.Notify(ctx, notifier.NewFeedNotification(item)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(taskQueue.Tasks) != 1 {
+		t.Errorf("Expected no mastodon tasks, got %v", len(taskQueue.Tasks)-1)
+	}
+}
+
+func TestBackfillFeed_DoDryRun(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+	ctx = dryrun.WithContext(ctx)
+
+	testutil.MustConfigLoadWith(`
+[[Feeds]]
+  Code = "test-archive"
+  Source = "syndication"
+  URL = "http://archive.example.com/feed.xml"
+  URLPattern = "http://archive.example.com/"
+  Enabled = true
+`)
+	defer testutil.MustConfigLoad()
+
+	h := dao.NewDatastoreHandler()
+	latestRepo := entity.NewLatestEntryRepository(h)
+	lineItemRepo := entity.NewLineItemRepository(h)
+	tweetItemRepo := entity.NewTweetItemRepository(h)
+	u := usecase.NewBackfillFeed(
+		log.NewAELogger(),
+		crawlertest.NewArchiveFetcher("testdata/crawl"),
+		eventtest.NewTaskQueue(),
+		dao.NewDatastoreTransactor(),
+		latestRepo,
+		tweetItemRepo,
+		lineItemRepo,
+		entity.NewChannelItemRepository(h),
+	)
+
+	// nothing is recorded in dry-run mode
+	params := usecase.BackfillFeedParams{Request: crawler.ArchiveRequest{Code: crawler.FeedCode("test-archive")}}
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if latestURL := latestRepo.GetURL(ctx, "test-archive"); latestURL != "" {
+		t.Errorf("Expected latest url is empty, got %v", latestURL)
+	}
+	page, err := crawlertest.NewArchiveFetcher("testdata/crawl").FetchArchive(ctx, "test-archive", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range page.Items {
+		if lineItemRepo.Exists(ctx, item.UniqueURL()) {
+			t.Errorf("Expected line item %v is not recorded, but found", item.UniqueURL())
+		}
+		if tweetItemRepo.Exists(ctx, item.UniqueURL()) {
+			t.Errorf("Expected tweet item %v is not recorded, but found", item.UniqueURL())
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/syndication"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// BuildFeed use case
+	BuildFeed struct {
+		log          log.Logger
+		lineItemRepo entity.LineItemRepository
+	}
+
+	// BuildFeedParams input parameters
+	BuildFeedParams struct {
+		Code    string // feed code to filter, all feeds if empty
+		SiteURL string `validate:"required,url"`
+		FeedURL string `validate:"required,url"`
+		HubURL  string `validate:"omitempty,url"` // WebSub hub is not advertised if empty
+	}
+)
+
+const (
+	// feedItemNum is the max number of items in the feed
+	feedItemNum = 50
+
+	// feedScanNum is the number of recent history that is scanned to filter by code
+	feedScanNum = 500
+)
+
+// NewBuildFeed returns BuildFeed use case
+func NewBuildFeed(log log.Logger, lineItemRepo entity.LineItemRepository) *BuildFeed {
+	return &BuildFeed{
+		log:          log,
+		lineItemRepo: lineItemRepo,
+	}
+}
+
+// Do builds the aggregated timeline from LineItem history
+// the code of the items is resolved from the entry url, so the items stored before feed codes are also filtered
+func (use *BuildFeed) Do(ctx context.Context, params BuildFeedParams) (syndication.Feed, error) {
+	const errTag = "BuildFeed.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return syndication.Feed{}, errors.Wrap(err, errTag)
+	}
+
+	title := "ももクロちゃんねる"
+	limit := feedItemNum
+	if params.Code != "" {
+		if _, ok := crawler.FindFeed(crawler.FeedCode(params.Code)); !ok {
+			return syndication.Feed{}, errors.Errorf("%v: unknown code:%v", errTag, params.Code)
+		}
+		title = fmt.Sprintf("%s - %s", title, params.Code)
+		limit = feedScanNum
+	}
+
+	lineItems, err := use.lineItemRepo.FindRecent(ctx, limit)
+	if err != nil {
+		return syndication.Feed{}, errors.Wrap(err, errTag)
+	}
+
+	feed := syndication.Feed{Title: title, Link: params.SiteURL, FeedURL: params.FeedURL, HubURL: params.HubURL}
+	for _, item := range lineItems {
+		var code string
+		if f, ok := crawler.FindFeedByURL(item.URL); ok {
+			code = f.Code.String()
+		}
+		if params.Code != "" && code != params.Code {
+			continue
+		}
+
+		if feed.Updated.IsZero() {
+			feed.Updated = item.CreatedAt
+		}
+		feed.Items = append(feed.Items, syndication.Item{
+			ID:        item.ID,
+			Title:     item.Title,
+			URL:       item.URL,
+			Category:  code,
+			Published: item.PublishedAt,
+			ImageURLs: item.SplitImageURLs(),
+			VideoURLs: item.SplitVideoURLs(),
+		})
+		if len(feed.Items) == feedItemNum {
+			break
+		}
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = timeutil.Now()
+	}
+
+	return feed, nil
+}
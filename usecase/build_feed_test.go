@@ -0,0 +1,69 @@
+package usecase_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestBuildFeed_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	repo := entity.NewLineItemRepository(dao.NewDatastoreHandler())
+	now := time.Now()
+	urls := []string{
+		"https://ameblo.jp/momota-sd/entry-1.html",
+		"https://ameblo.jp/tamai-sd/entry-2.html",
+		"https://ameblo.jp/momota-sd/entry-3.html",
+	}
+	for i, u := range urls {
+		item := entity.NewLineItem(fmt.Sprintf("%s&t=%d", u, i), fmt.Sprintf("entry %d", i+1), u, now, []string{fmt.Sprintf("http://localhost/%d.jpg", i)}, nil)
+		item.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		if err := repo.Save(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u := usecase.NewBuildFeed(log.NewAELogger(), repo)
+	params := usecase.BuildFeedParams{SiteURL: "http://localhost/", FeedURL: "http://localhost/feed.atom"}
+	feed, err := u.Do(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Items) != 3 || feed.Items[0].Title != "entry 3" || feed.Items[0].Category != "momota-sd" {
+		t.Fatalf("Expected the timeline in reverse order, got %v", feed.Items)
+	}
+	if !feed.Updated.After(now.Add(time.Minute)) {
+		t.Errorf("Expected updated of the latest item, got %v", feed.Updated)
+	}
+	if len(feed.Items[0].ImageURLs) != 1 {
+		t.Errorf("Expected image urls, got %v", feed.Items[0].ImageURLs)
+	}
+
+	params.Code = "tamai-sd"
+	feed, err = u.Do(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].Title != "entry 2" {
+		t.Errorf("Expected the items of tamai-sd, got %v", feed.Items)
+	}
+
+	params.Code = "unknown"
+	if _, err := u.Do(ctx, params); err == nil {
+		t.Error("Expected unknown code error, got nil")
+	}
+}
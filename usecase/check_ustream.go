@@ -1,43 +1,38 @@
 package usecase
 
 import (
-	"fmt"
-
 	"context"
 
 	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/dao"
 	"github.com/utahta/momoclo-channel/entity"
-	"github.com/utahta/momoclo-channel/event"
-	"github.com/utahta/momoclo-channel/event/eventtask"
-	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
 	"github.com/utahta/momoclo-channel/timeutil"
-	"github.com/utahta/momoclo-channel/twitter"
 	"github.com/utahta/momoclo-channel/ustream"
 )
 
 type (
 	// CheckUstream use case
 	CheckUstream struct {
-		log       log.Logger
-		taskQueue event.TaskQueue
-		checker   ustream.StatusChecker
-		repo      entity.UstreamStatusRepository
+		log     log.Logger
+		notify  *Notify
+		checker ustream.StatusChecker
+		repo    entity.UstreamStatusRepository
 	}
 )
 
 // NewCheckUstream returns CheckUstream use case
 func NewCheckUstream(
 	logger log.Logger,
-	taskQueue event.TaskQueue,
+	notify *Notify,
 	checker ustream.StatusChecker,
 	repo entity.UstreamStatusRepository) *CheckUstream {
 	return &CheckUstream{
-		log:       logger,
-		taskQueue: taskQueue,
-		checker:   checker,
-		repo:      repo,
+		log:     logger,
+		notify:  notify,
+		checker: checker,
+		repo:    repo,
 	}
 }
 
@@ -64,13 +59,15 @@ func (u *CheckUstream) Do(ctx context.Context) error {
 	}
 
 	if isLive {
-		t := timeutil.Now()
-		u.taskQueue.PushMulti(ctx, []event.Task{
-			eventtask.NewTweet(
-				twitter.TweetRequest{Text: fmt.Sprintf("momocloTV が配信を開始しました\n%s\nhttp://www.ustream.tv/channel/momoclotv", t.Format("from 2006/01/02 15:04:05"))},
-			),
-			eventtask.NewLineBroadcast(linenotify.Message{Text: "\nmomocloTV が配信を開始しました\nhttp://www.ustream.tv/channel/momoclotv"}),
-		})
+		n := notifier.NewMessageNotification(
+			notifier.KindUstream,
+			"momocloTV が配信を開始しました",
+			"http://www.ustream.tv/channel/momoclotv",
+			timeutil.Now(),
+		)
+		if err := u.notify.Do(ctx, NotifyParams{Notifications: []notifier.Notification{n}}); err != nil {
+			return errors.Wrap(err, errTag)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// ConfirmEmail use case
+	ConfirmEmail struct {
+		log  log.Logger
+		repo entity.EmailSubscriberRepository
+	}
+
+	// ConfirmEmailParams input parameters
+	ConfirmEmailParams struct {
+		ID    string `validate:"required"`
+		Token string `validate:"required"`
+	}
+)
+
+// NewConfirmEmail returns ConfirmEmail use case
+func NewConfirmEmail(log log.Logger, repo entity.EmailSubscriberRepository) *ConfirmEmail {
+	return &ConfirmEmail{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do confirms the subscriber given signed link
+func (use *ConfirmEmail) Do(ctx context.Context, params ConfirmEmailParams) error {
+	const errTag = "ConfirmEmail.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	s, err := use.repo.Find(ctx, params.ID)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if !s.VerifyToken(config.C().Mail.SigningKey, entity.EmailActionConfirm, params.Token) {
+		return errors.Errorf("%v: invalid token id:%v", errTag, params.ID)
+	}
+	if s.Confirmed {
+		return nil
+	}
+
+	s.Confirmed = true
+	s.DigestedAt = timeutil.Now() // the first digest does not include entries before subscription
+	if err := use.repo.Save(ctx, s); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "confirm email subscriber id:%v frequency:%v", s.ID, s.Frequency)
+
+	return nil
+}
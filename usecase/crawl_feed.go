@@ -2,23 +2,30 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
 	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dryrun"
 	"github.com/utahta/momoclo-channel/entity"
-	"github.com/utahta/momoclo-channel/event"
-	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/timeutil"
 	"github.com/utahta/momoclo-channel/validator"
 )
 
 type (
 	// CrawlFeed use case
 	CrawlFeed struct {
-		log       log.Logger
-		feed      crawler.FeedFetcher
-		taskQueue event.TaskQueue
-		repo      entity.LatestEntryRepository
+		log          log.Logger
+		feed         crawler.FeedFetcher
+		notify       *Notify
+		repo         entity.LatestEntryRepository
+		statusRepo   entity.CrawlStatusRepository
+		notifyAdmins *LineNotifyAdmins
 	}
 
 	// CrawlFeedParams input parameters
@@ -31,61 +38,218 @@ type (
 func NewCrawlFeed(
 	log log.Logger,
 	feed crawler.FeedFetcher,
-	taskQueue event.TaskQueue,
-	repo entity.LatestEntryRepository) *CrawlFeed {
+	notify *Notify,
+	repo entity.LatestEntryRepository,
+	statusRepo entity.CrawlStatusRepository,
+	notifyAdmins *LineNotifyAdmins) *CrawlFeed {
 	return &CrawlFeed{
-		log:       log,
-		feed:      feed,
-		taskQueue: taskQueue,
-		repo:      repo,
+		log:          log,
+		feed:         feed,
+		notify:       notify,
+		repo:         repo,
+		statusRepo:   statusRepo,
+		notifyAdmins: notifyAdmins,
 	}
 }
 
-// Do crawls a site and invokes tweet and line event
+// Do crawls a site and notifies new entries
 func (use *CrawlFeed) Do(ctx context.Context, params CrawlFeedParams) error {
 	const errTag = "CrawlFeed.Do failed"
 
-	items, err := use.feed.Fetch(ctx, params.Code, 1, use.repo.GetURL(ctx, params.Code.String()))
+	lastItemAt, err := use.crawl(ctx, params)
 	if err != nil {
-		return errors.Wrap(err, errTag)
+		err = errors.Wrap(err, errTag)
+	}
+	if dryrun.Enabled(ctx) {
+		return err // crawl status is not tracked in dry-run mode
+	}
+	if serr := use.track(ctx, params.Code, lastItemAt, err); serr != nil {
+		use.log.Errorf(ctx, "%v: track crawl status err:%v", errTag, serr)
+	}
+	return err
+}
+
+// crawl crawls a site and returns the published time of the latest item
+// it returns zero time if no items are fetched
+func (use *CrawlFeed) crawl(ctx context.Context, params CrawlFeedParams) (time.Time, error) {
+	const errTag = "CrawlFeed.crawl failed"
+
+	// walk back until the latest entry if catch-up enabled
+	settings := crawlerSettings()
+	latestURL := use.repo.GetURL(ctx, params.Code.String())
+	maxItemNum := 1
+	if latestURL != "" && settings.CatchUpLimit > 1 {
+		maxItemNum = settings.CatchUpLimit
+	}
+
+	items, err := use.feed.Fetch(ctx, params.Code, maxItemNum, latestURL)
+	if err == crawler.ErrNotModified {
+		return time.Time{}, nil // neither new entries nor edits
+	} else if err != nil {
+		return time.Time{}, errors.Wrap(err, errTag)
+	}
+	if len(items) == 0 && latestURL != "" && settings.DetectEdits {
+		// the feed has been modified without new entries, so re-crawl the latest entry to detect edits
+		// the fetch without the latest url is sent without condition
+		items, err = use.feed.Fetch(ctx, params.Code, 1, "")
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, errTag)
+		}
 	}
 	if len(items) == 0 {
-		return nil
+		return time.Time{}, nil
 	}
 	for i := range items {
 		if err := validator.Validate(items[i]); err != nil {
 			use.log.Errorf(ctx, "%v: validate error i:%v items:%v err:%v", errTag, i, items, err)
-			return errors.Wrap(err, errTag)
+			return time.Time{}, errors.Wrap(err, errTag)
 		}
 	}
 
 	// update latest entry
 	item := items[0] // first item is the latest entry
+	fingerprint := entity.Fingerprint(item.EntryTitle, item.ImageURLs, item.VideoURLs)
 	l, err := use.repo.FindOrNewByURL(ctx, item.FeedCode().String(), item.EntryURL)
 	if err != nil {
-		return errors.Wrapf(err, "%v: url:%v", errTag, item.EntryURL)
+		return time.Time{}, errors.Wrapf(err, "%v: url:%v", errTag, item.EntryURL)
 	}
-	if l.URL == item.EntryURL && l.PublishedAt.Equal(item.PublishedAt) {
-		return nil // already get feeds. nothing to do
+	if crawler.CanonicalURL(l.URL) == crawler.CanonicalURL(item.EntryURL) && l.PublishedAt.Equal(item.PublishedAt) {
+		if l.Fingerprint == fingerprint {
+			return item.PublishedAt, nil // already get feeds. nothing to do
+		}
+		return item.PublishedAt, use.doEdited(ctx, l, item, fingerprint)
 	}
 	l.URL = item.EntryURL
 	l.PublishedAt = item.PublishedAt
-	if err := use.repo.Save(ctx, l); err != nil {
-		return errors.Wrapf(err, errTag)
+	l.Fingerprint = fingerprint
+	if err := use.saveLatestEntry(ctx, l); err != nil {
+		return time.Time{}, errors.Wrap(err, errTag)
 	}
 
-	// push events
-	var tasks []event.Task
-	for _, item := range items {
-		tasks = append(tasks,
-			eventtask.NewEnqueueTweets(item),
-			eventtask.NewEnqueueLines(item),
-		)
+	// notify oldest-first
+	notifications := make([]notifier.Notification, 0, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		notifications = append(notifications, notifier.NewFeedNotification(items[i]))
 	}
-	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
-		return errors.Wrap(err, errTag)
+	if err := use.notify.Do(ctx, NotifyParams{Notifications: notifications}); err != nil {
+		return time.Time{}, errors.Wrap(err, errTag)
 	}
 	use.log.Infof(ctx, "crawl feed items:%v", items)
 
+	if recovered := len(items) - 1; recovered > 0 {
+		use.log.Infof(ctx, "catch up feed code:%v recovered:%v", params.Code, recovered)
+		if len(items) >= maxItemNum {
+			use.log.Warningf(ctx, "catch up feed code:%v did not reach the latest entry within limit:%v", params.Code, maxItemNum)
+		}
+	}
+
+	return item.PublishedAt, nil
+}
+
+// doEdited notifies the latest entry again that is edited
+// only the latest entry is compared, because LatestEntry has the fingerprint of it
+// the edit is delivered to editChannels only, the other channels have already recorded the entry
+func (use *CrawlFeed) doEdited(ctx context.Context, l *entity.LatestEntry, item crawler.FeedItem, fingerprint string) error {
+	const errTag = "CrawlFeed.doEdited failed"
+
+	legacy := l.Fingerprint == ""
+	l.Fingerprint = fingerprint
+	if err := use.saveLatestEntry(ctx, l); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if legacy {
+		return nil // the fingerprint of legacy entity is just recorded
+	}
+
+	notifications := []notifier.Notification{notifier.NewFeedNotification(item)}
+	if err := use.notify.Do(ctx, NotifyParams{Notifications: notifications, Channels: editChannels}); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "crawl edited feed item:%v", item)
+
+	return nil
+}
+
+// saveLatestEntry saves the latest entry unless dry-run mode
+func (use *CrawlFeed) saveLatestEntry(ctx context.Context, l *entity.LatestEntry) error {
+	if dryrun.Enabled(ctx) {
+		use.log.Infof(ctx, "dry-run: skip saving latest entry:%v", l)
+		return nil
+	}
+	return use.repo.Save(ctx, l)
+}
+
+// track records crawl status and alerts admins when the feed crosses failure or staleness threshold
+func (use *CrawlFeed) track(ctx context.Context, code crawler.FeedCode, lastItemAt time.Time, crawlErr error) error {
+	const errTag = "CrawlFeed.track failed"
+
+	s, err := use.statusRepo.FindOrNew(ctx, code.String())
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	now := timeutil.Now()
+	var messages []linenotify.Message
+	if crawlErr != nil {
+		s.Fail(now, crawlErr)
+
+		threshold := crawlerSettings().FailureThreshold
+		if threshold > 0 && s.ConsecutiveFailures >= threshold && !s.FailureAlerted {
+			s.FailureAlerted = true
+			messages = append(messages, linenotify.Message{
+				Text: fmt.Sprintf("\n[alert] フィードの取得に%v回連続で失敗しました\ncode:%v\nerr:%v", s.ConsecutiveFailures, code, crawlErr),
+			})
+		}
+	} else {
+		s.Succeed(now, lastItemAt)
+
+		if s.FailureAlerted {
+			s.FailureAlerted = false
+			messages = append(messages, linenotify.Message{
+				Text: fmt.Sprintf("\n[recovered] フィードの取得が復旧しました\ncode:%v", code),
+			})
+		}
+
+		var staleAfter time.Duration
+		if feed, ok := crawler.FindFeed(code); ok {
+			if staleAfter, err = feed.StaleDuration(); err != nil {
+				use.log.Errorf(ctx, "%v: err:%v", errTag, err)
+			}
+		}
+		stale := s.IsStale(now, staleAfter)
+		switch {
+		case stale && !s.StaleAlerted:
+			s.StaleAlerted = true
+			messages = append(messages, linenotify.Message{
+				Text: fmt.Sprintf("\n[alert] フィードが更新されていません\ncode:%v\nlast item:%v", code, s.LastItemAt.In(timeutil.JST()).Format("2006/01/02 15:04")),
+			})
+		case !stale && s.StaleAlerted:
+			s.StaleAlerted = false
+			messages = append(messages, linenotify.Message{
+				Text: fmt.Sprintf("\n[recovered] フィードが更新されました\ncode:%v", code),
+			})
+		}
+	}
+
+	if err := use.statusRepo.Save(ctx, s); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	use.log.Warningf(ctx, "crawl status changed code:%v messages:%v", code, messages)
+	if err := use.notifyAdmins.Do(ctx, LineNotifyAdminsParams{Messages: messages}); err != nil {
+		return errors.Wrap(err, errTag)
+	}
 	return nil
 }
+
+// crawlerSettings returns the crawler settings
+// the zero value is returned if config is not loaded, that disables catch-up, edit detection and alerts
+func crawlerSettings() config.Crawler {
+	if config.C() == nil {
+		return config.Crawler{}
+	}
+	return config.C().Crawler
+}
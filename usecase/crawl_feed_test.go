@@ -0,0 +1,439 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/crawler/crawlertest"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+const testFeedConfig = `
+[[Feeds]]
+  Code = "test-feed"
+  Source = "syndication"
+  URL = "http://feed.example.com/feed.xml"
+  URLPattern = "http://feed.example.com/"
+  Enabled = true
+`
+
+func newTestCrawlFeed(taskQueue *eventtest.TaskQueue) (*usecase.CrawlFeed, entity.CrawlStatusRepository) {
+	h := dao.NewDatastoreHandler()
+	statusRepo := entity.NewCrawlStatusRepository(h)
+	return usecase.NewCrawlFeed(
+		log.NewAELogger(),
+		crawlertest.NewFeedFetcher("testdata/crawl"),
+		newTestNotify(taskQueue),
+		entity.NewLatestEntryRepository(h),
+		statusRepo,
+		usecase.NewLineNotifyAdmins(log.NewAELogger(), taskQueue, entity.NewLineNotificationRepository(h)),
+	), statusRepo
+}
+
+func TestCrawlFeed_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoadWith(testFeedConfig)
+	defer testutil.MustConfigLoad()
+
+	taskQueue := eventtest.NewTaskQueue()
+	u, statusRepo := newTestCrawlFeed(taskQueue)
+	params := usecase.CrawlFeedParams{Code: crawler.FeedCode("test-feed")}
+
+	// first crawl gets the latest entry only
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 2 {
+		t.Fatalf("Expected taskqueue length 2, got %v", len(taskQueue.Tasks))
+	}
+	paths := []string{"/enqueue/twitter", "/enqueue/line"}
+	for i, path := range paths {
+		if taskQueue.Tasks[i].Path != path {
+			t.Errorf("Expected path %v, got %v", path, taskQueue.Tasks[i].Path)
+		}
+		item := *taskQueue.Tasks[i].Object.(notifier.Notification).FeedItem
+		if item.EntryURL != "http://feed.example.com/entry/3" {
+			t.Errorf("Expected entry url entry/3, got %v", item.EntryURL)
+		}
+		if item.FeedCode() != params.Code {
+			t.Errorf("Expected feed code %v, got %v", params.Code, item.FeedCode())
+		}
+	}
+
+	// second crawl catches up new entries oldest-first
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 6 {
+		t.Fatalf("Expected taskqueue length 6, got %v", len(taskQueue.Tasks))
+	}
+	tests := []struct {
+		entryURL string
+		delay    time.Duration
+	}{
+		{"http://feed.example.com/entry/4", 0},
+		{"http://feed.example.com/entry/4", 0},
+		{"http://feed.example.com/entry/5", time.Second},
+		{"http://feed.example.com/entry/5", time.Second},
+	}
+	for i, test := range tests {
+		task := taskQueue.Tasks[i+2]
+		if item := task.Object.(notifier.Notification).FeedItem; item.EntryURL != test.entryURL {
+			t.Errorf("Expected entry url %v, got %v", test.entryURL, item.EntryURL)
+		}
+		if task.Delay != test.delay {
+			t.Errorf("Expected delay %v, got %v", test.delay, task.Delay)
+		}
+	}
+
+	// nothing new
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 6 {
+		t.Errorf("Expected taskqueue length 6, got %v", len(taskQueue.Tasks))
+	}
+
+	s, err := statusRepo.FindOrNew(ctx, params.Code.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.LastSucceededAt.IsZero() || s.ConsecutiveFailures != 0 {
+		t.Errorf("Expected succeeded crawl status, got %v", s)
+	}
+	if expected := time.Date(2017, 11, 1, 5, 0, 0, 0, time.FixedZone("", 9*60*60)); !s.LastItemAt.Equal(expected) {
+		t.Errorf("Expected last item at %v, got %v", expected, s.LastItemAt)
+	}
+
+	// unregistered feed fails
+	params = usecase.CrawlFeedParams{Code: crawler.FeedCode("unknown")}
+	if err := u.Do(ctx, params); err == nil {
+		t.Error("Expected got error, but nil")
+	}
+	s, err = statusRepo.FindOrNew(ctx, params.Code.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ConsecutiveFailures != 1 || s.LastError == "" {
+		t.Errorf("Expected failed crawl status, got %v", s)
+	}
+}
+
+func TestCrawlFeed_DoCatchUpLimit(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoadWith(testFeedConfig)
+	defer testutil.MustConfigLoad()
+	config.C().Crawler.CatchUpLimit = 1
+
+	taskQueue := eventtest.NewTaskQueue()
+	u, _ := newTestCrawlFeed(taskQueue)
+	params := usecase.CrawlFeedParams{Code: crawler.FeedCode("test-feed")}
+
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+
+	// the second crawl gets only the latest entry within the limit, entry/4 is skipped
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 4 {
+		t.Fatalf("Expected taskqueue length 4, got %v", len(taskQueue.Tasks))
+	}
+	for _, task := range taskQueue.Tasks[2:] {
+		if item := task.Object.(notifier.Notification).FeedItem; item.EntryURL != "http://feed.example.com/entry/5" {
+			t.Errorf("Expected entry url entry/5, got %v", item.EntryURL)
+		}
+	}
+
+	repo := entity.NewLatestEntryRepository(dao.NewDatastoreHandler())
+	if v := repo.GetURL(ctx, params.Code.String()); v != "http://feed.example.com/entry/5" {
+		t.Errorf("Expected latest entry entry/5, got %v", v)
+	}
+}
+
+func TestCrawlFeed_DoEditedWithETag(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoadWith(`
+[[Feeds]]
+  Code = "test-etag"
+  Source = "syndication"
+  URL = "http://etag.example.com/feed.xml"
+  URLPattern = "http://etag.example.com/"
+  Enabled = true
+`)
+	defer testutil.MustConfigLoad()
+	config.C().Crawler.DetectEdits = true
+
+	// the origin returns ETag, the feed is not modified at the second fetch and edited at the third fetch
+	h := dao.NewDatastoreHandler()
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewCrawlFeed(
+		log.NewAELogger(),
+		crawlertest.NewFeedFetcherWithCache("testdata/crawl", log.NewAELogger(), entity.NewFetchCacheRepository(h)),
+		newTestNotify(taskQueue),
+		entity.NewLatestEntryRepository(h),
+		entity.NewCrawlStatusRepository(h),
+		usecase.NewLineNotifyAdmins(log.NewAELogger(), taskQueue, entity.NewLineNotificationRepository(h)),
+	)
+	params := usecase.CrawlFeedParams{Code: crawler.FeedCode("test-etag")}
+
+	tests := []struct {
+		taskLen    int
+		entryTitle string
+	}{
+		{2, "entry 1"},        // the first crawl
+		{2, ""},               // not modified, the edit is not re-crawled
+		{4, "entry 1 edited"}, // modified without new entries, the edit is detected by the re-crawl
+		{4, ""},               // not modified
+	}
+	for i, test := range tests {
+		if err := u.Do(ctx, params); err != nil {
+			t.Fatal(err)
+		}
+		if len(taskQueue.Tasks) != test.taskLen {
+			t.Fatalf("Expected taskqueue length %v at %v, got %v", test.taskLen, i, len(taskQueue.Tasks))
+		}
+		if test.entryTitle == "" {
+			continue
+		}
+		item := taskQueue.Tasks[test.taskLen-1].Object.(notifier.Notification).FeedItem
+		if item.EntryTitle != test.entryTitle {
+			t.Errorf("Expected entry title %v at %v, got %v", test.entryTitle, i, item.EntryTitle)
+		}
+	}
+}
+
+func TestCrawlFeed_DoEdited(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoadWith(`
+[[Feeds]]
+  Code = "test-edit"
+  Source = "syndication"
+  URL = "http://edit.example.com/feed.xml"
+  URLPattern = "http://edit.example.com/"
+  Enabled = true
+`)
+	defer testutil.MustConfigLoad()
+	config.C().Crawler.DetectEdits = true
+
+	h := dao.NewDatastoreHandler()
+	transactor := dao.NewDatastoreTransactor()
+	taskQueue := eventtest.NewTaskQueue()
+	registry := notifier.NewRegistry()
+	registry.Register(notifier.ChannelTwitter, usecase.NewEnqueueTweets(log.NewAELogger(), taskQueue, transactor, entity.NewTweetItemRepository(h)))
+	registry.Register(notifier.ChannelLine, usecase.NewEnqueueLines(log.NewAELogger(), taskQueue, transactor, entity.NewLineItemRepository(h)))
+	registry.Register(notifier.ChannelDiscord, usecase.NewEnqueueDiscord(
+		log.NewAELogger(),
+		taskQueue,
+		transactor,
+		entity.NewChannelItemRepository(h),
+		entity.NewDiscordWebhookRepository(h),
+		entity.NewPreviewRepository(h),
+	))
+	u := usecase.NewCrawlFeed(
+		log.NewAELogger(),
+		crawlertest.NewFeedFetcher("testdata/crawl"),
+		usecase.NewNotify(log.NewAELogger(), taskQueue, registry),
+		entity.NewLatestEntryRepository(h),
+		entity.NewCrawlStatusRepository(h),
+		usecase.NewLineNotifyAdmins(log.NewAELogger(), taskQueue, entity.NewLineNotificationRepository(h)),
+	)
+	params := usecase.CrawlFeedParams{Code: crawler.FeedCode("test-edit")}
+
+	// the new entry is delivered to all channels
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 3 {
+		t.Fatalf("Expected taskqueue length 3, got %v", len(taskQueue.Tasks))
+	}
+
+	// the edit is delivered to the channels that announce edits only
+	taskQueue.Tasks = nil
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{"/enqueue/twitter", "/enqueue/line"}
+	if len(taskQueue.Tasks) != len(paths) {
+		t.Fatalf("Expected taskqueue length %v, got %v", len(paths), len(taskQueue.Tasks))
+	}
+	for i, path := range paths {
+		if taskQueue.Tasks[i].Path != path {
+			t.Errorf("Expected path %v, got %v", path, taskQueue.Tasks[i].Path)
+		}
+		if item := taskQueue.Tasks[i].Object.(notifier.Notification).FeedItem; item.EntryTitle != "entry 1 edited" {
+			t.Errorf("Expected the edited entry, got %v", item.EntryTitle)
+		}
+	}
+}
+
+func TestCrawlFeeds_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	// the feed that has no fixture fails
+	testutil.MustConfigLoadWith(testFeedConfig + `
+[[Feeds]]
+  Code = "broken-feed"
+  Source = "syndication"
+  URL = "http://broken.example.com/feed.xml"
+  URLPattern = "http://broken.example.com/"
+  Enabled = true
+`)
+	defer testutil.MustConfigLoad()
+
+	taskQueue := eventtest.NewTaskQueue()
+	crawl, statusRepo := newTestCrawlFeed(taskQueue)
+	u := usecase.NewCrawlFeeds(log.NewAELogger(), crawl)
+	if err := u.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(taskQueue.Tasks) != 2 {
+		t.Errorf("Expected taskqueue length 2, got %v", len(taskQueue.Tasks))
+	}
+	s, err := statusRepo.FindOrNew(ctx, "broken-feed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ConsecutiveFailures != 1 {
+		t.Errorf("Expected consecutive failures 1, got %v", s.ConsecutiveFailures)
+	}
+}
+
+func TestCrawlFeed_DoDryRun(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoadWith(testFeedConfig)
+	defer testutil.MustConfigLoad()
+
+	ctx = dryrun.WithContext(ctx)
+	taskQueue := eventtest.NewTaskQueue()
+	u, statusRepo := newTestCrawlFeed(taskQueue)
+	params := usecase.CrawlFeedParams{Code: crawler.FeedCode("test-feed")}
+
+	// latest entry is not saved, so every crawl gets the latest entry again
+	for i := 1; i <= 2; i++ {
+		if err := u.Do(ctx, params); err != nil {
+			t.Fatal(err)
+		}
+		if len(taskQueue.Tasks) != 2*i {
+			t.Fatalf("Expected taskqueue length %v, got %v", 2*i, len(taskQueue.Tasks))
+		}
+	}
+
+	repo := entity.NewLatestEntryRepository(dao.NewDatastoreHandler())
+	if v := repo.GetURL(ctx, params.Code.String()); v != "" {
+		t.Errorf("Expected latest entry not saved, got %v", v)
+	}
+	s, err := statusRepo.FindOrNew(ctx, params.Code.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.LastSucceededAt.IsZero() {
+		t.Errorf("Expected crawl status not tracked, got %v", s)
+	}
+}
+
+func TestCrawlFeeds_DoSchedule(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoadWith(`
+[[Feeds]]
+  Code = "test-feed"
+  Source = "syndication"
+  URL = "http://feed.example.com/feed.xml"
+  URLPattern = "http://feed.example.com/"
+  Enabled = true
+  Schedule = "@every 10m"
+`)
+	defer testutil.MustConfigLoad()
+
+	tmp := timeutil.Now
+	timeutil.Now = func() time.Time {
+		return time.Date(2008, 5, 17, 12, 41, 0, 0, timeutil.JST())
+	}
+	defer func() {
+		timeutil.Now = tmp
+	}()
+
+	taskQueue := eventtest.NewTaskQueue()
+	crawl, statusRepo := newTestCrawlFeed(taskQueue)
+	u := usecase.NewCrawlFeeds(log.NewAELogger(), crawl)
+
+	// the feed that has never been crawled is due on the slot only
+	if err := u.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 0 {
+		t.Fatalf("Expected taskqueue length 0, got %v", len(taskQueue.Tasks))
+	}
+
+	// the slot of 12:40 is crawled at the delayed tick
+	s, err := statusRepo.FindOrNew(ctx, "test-feed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.LastSucceededAt = time.Date(2008, 5, 17, 12, 30, 0, 0, timeutil.JST())
+	if err := statusRepo.Save(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 2 {
+		t.Errorf("Expected taskqueue length 2, got %v", len(taskQueue.Tasks))
+	}
+
+	// the slot is not crawled twice
+	taskQueue.Tasks = nil
+	if err := u.Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 0 {
+		t.Errorf("Expected taskqueue length 0, got %v", len(taskQueue.Tasks))
+	}
+}
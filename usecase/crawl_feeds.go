@@ -1,17 +1,18 @@
 package usecase
 
 import (
-	"time"
-
 	"context"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/crawler"
 	"github.com/utahta/momoclo-channel/log"
 	"github.com/utahta/momoclo-channel/timeutil"
-	"golang.org/x/sync/errgroup"
 )
 
+// defaultCrawlConcurrency is used when the concurrency is not given by config
+const defaultCrawlConcurrency = 4
+
 type (
 	// CrawlFeeds use case
 	CrawlFeeds struct {
@@ -28,34 +29,65 @@ func NewCrawlFeeds(logger log.Logger, crawl *CrawlFeed) *CrawlFeeds {
 	}
 }
 
-// Do crawls all known sites
+// Do crawls all enabled feeds that have a scheduled minute since the last crawl
+// a failure of each feed is reported on its own, it returns error only if all feeds failed
 func (c *CrawlFeeds) Do(ctx context.Context) error {
 	const errTag = "CrawlFeeds.Do failed"
 
 	now := timeutil.Now()
-	codes := []crawler.FeedCode{
-		crawler.FeedCodeMomota,
-		crawler.FeedCodeTamai,
-		crawler.FeedCodeSasaki,
-		crawler.FeedCodeTakagi,
-		crawler.FeedCodeAeNews,
-		crawler.FeedCodeYoutube,
+	var codes []crawler.FeedCode
+	for _, feed := range crawler.EnabledFeeds() {
+		s, err := c.crawl.statusRepo.FindOrNew(ctx, feed.Code.String())
+		if err != nil {
+			c.log.Errorf(ctx, "%v: find crawl status code:%v err:%v", errTag, feed.Code, err)
+			continue
+		}
+		due, err := feed.Due(s.LastCrawledAt(), now)
+		if err != nil {
+			c.log.Errorf(ctx, "%v: invalid schedule err:%v", errTag, err)
+			continue
+		}
+		if due {
+			codes = append(codes, feed.Code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
 	}
-	if now.Weekday() == time.Sunday && now.Hour() == 17 {
-		codes = append(codes, crawler.FeedCodeHappyclo)
+
+	concurrency := crawlerSettings().Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultCrawlConcurrency
 	}
+	// the error of each feed is collected instead of cancelling the others,
+	// so that a broken feed never stops crawling the rest
+	sem := make(chan struct{}, concurrency)
+	errs := make([]error, len(codes))
+
+	var wg sync.WaitGroup
+	for i, code := range codes {
+		wg.Add(1)
+		go func(i int, code crawler.FeedCode) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
-	eg := &errgroup.Group{}
-	for _, code := range codes {
-		code := code
+			errs[i] = c.crawl.Do(ctx, CrawlFeedParams{code})
+		}(i, code)
+	}
+	wg.Wait()
 
-		eg.Go(func() error {
-			return c.crawl.Do(ctx, CrawlFeedParams{code})
-		})
+	var failed int
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			c.log.Errorf(ctx, "%v: code:%v err:%v", errTag, codes[i], err)
+		}
 	}
+	c.log.Infof(ctx, "crawl feeds succeeded:%v failed:%v", len(codes)-failed, failed)
 
-	if err := eg.Wait(); err != nil {
-		return errors.Wrap(err, errTag)
+	if failed == len(codes) {
+		return errors.Errorf("%v: all feeds failed. last err:%v", errTag, errs[len(errs)-1])
 	}
 	return nil
 }
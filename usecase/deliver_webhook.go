@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+	"github.com/utahta/momoclo-channel/webhook"
+)
+
+type (
+	// DeliverWebhook use case
+	DeliverWebhook struct {
+		log            log.Logger
+		taskQueue      event.TaskQueue
+		client         webhook.Client
+		subscriberRepo entity.WebhookSubscriberRepository
+		deliveryRepo   entity.WebhookDeliveryRepository
+	}
+
+	// DeliverWebhookParams input parameters
+	DeliverWebhookParams struct {
+		DeliveryID string `validate:"required"`
+	}
+)
+
+const (
+	// webhookMaxAttempts is the max number of attempts of a delivery
+	webhookMaxAttempts = 5
+
+	// webhookMaxFailures is the number of consecutive failed deliveries that disables the subscriber
+	webhookMaxFailures = 3
+
+	// webhookBackoff is the delay before the second attempt, it is doubled for each attempt
+	webhookBackoff = 30 * time.Second
+)
+
+// NewDeliverWebhook returns DeliverWebhook use case
+func NewDeliverWebhook(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	client webhook.Client,
+	subscriberRepo entity.WebhookSubscriberRepository,
+	deliveryRepo entity.WebhookDeliveryRepository) *DeliverWebhook {
+	return &DeliverWebhook{
+		log:            log,
+		taskQueue:      taskQueue,
+		client:         client,
+		subscriberRepo: subscriberRepo,
+		deliveryRepo:   deliveryRepo,
+	}
+}
+
+// Do posts the signed payload of the delivery to the subscriber
+// the failed delivery is retried with exponential backoff instead of the retry of task queue
+func (use *DeliverWebhook) Do(ctx context.Context, params DeliverWebhookParams) error {
+	const errTag = "DeliverWebhook.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	d, err := use.deliveryRepo.Find(ctx, params.DeliveryID)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if d.Status != entity.WebhookDeliveryPending {
+		return nil // already delivered
+	}
+
+	s, err := use.subscriberRepo.Find(ctx, d.SubscriberID)
+	if err != nil && err != dao.ErrNoSuchEntity {
+		return errors.Wrap(err, errTag)
+	}
+	if err == dao.ErrNoSuchEntity || s.Disabled {
+		d.Status = entity.WebhookDeliveryFailed
+		d.Error = "subscriber is disabled"
+		return errors.Wrap(use.deliveryRepo.Save(ctx, d), errTag)
+	}
+
+	secret, err := s.Secret(config.C().Webhook.TokenKey)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	d.Attempts++
+	err = use.client.Post(ctx, webhook.Request{
+		URL:        s.URL,
+		Secret:     secret,
+		Event:      d.Event,
+		DeliveryID: d.ID,
+		Body:       []byte(d.Payload),
+	})
+	d.StatusCode, d.Error = 0, ""
+	if e, ok := err.(*webhook.StatusError); ok {
+		d.StatusCode = e.StatusCode
+	}
+
+	if err == nil {
+		d.Status = entity.WebhookDeliverySucceeded
+		if err := use.deliveryRepo.Save(ctx, d); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "deliver webhook id:%v subscriber:%v", d.ID, s.ID)
+
+		if s.Failures > 0 {
+			s.Failures = 0
+			return errors.Wrap(use.subscriberRepo.Save(ctx, s), errTag)
+		}
+		return nil
+	}
+	d.Error = err.Error()
+
+	if d.Attempts < webhookMaxAttempts {
+		task := eventtask.NewWebhookDelivery(d.ID)
+		task.Delay = webhookBackoff << uint(d.Attempts-1)
+		if err := use.deliveryRepo.Save(ctx, d); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		if err := use.taskQueue.Push(ctx, task); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Warningf(ctx, "webhook delivery failed id:%v attempts:%v retry after:%v err:%v", d.ID, d.Attempts, task.Delay, err)
+		return nil
+	}
+
+	d.Status = entity.WebhookDeliveryFailed
+	if err := use.deliveryRepo.Save(ctx, d); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	s.Failures++
+	if s.Failures >= webhookMaxFailures {
+		s.Disabled = true
+		use.log.Warningf(ctx, "disable webhook subscriber id:%v failures:%v", s.ID, s.Failures)
+	}
+	if err := use.subscriberRepo.Save(ctx, s); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Errorf(ctx, "webhook delivery gave up id:%v err:%v", d.ID, d.Error)
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"github.com/utahta/momoclo-channel/webhook"
+	"google.golang.org/appengine/aetest"
+)
+
+// webhookClientFunc is a webhook.Client that returns the result of the function
+type webhookClientFunc func(webhook.Request) error
+
+func (f webhookClientFunc) Post(_ context.Context, req webhook.Request) error {
+	return f(req)
+}
+
+func TestDeliverWebhook_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	h := dao.NewDatastoreHandler()
+	subscriberRepo := entity.NewWebhookSubscriberRepository(h)
+	deliveryRepo := entity.NewWebhookDeliveryRepository(h)
+	s, err := entity.NewWebhookSubscriber(config.C().Webhook.TokenKey, "http://localhost/hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := subscriberRepo.Save(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := s.Secret(config.C().Webhook.TokenKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		status   int
+		requests []webhook.Request
+	)
+	client := webhookClientFunc(func(req webhook.Request) error {
+		requests = append(requests, req)
+		if status != http.StatusOK {
+			return &webhook.StatusError{StatusCode: status}
+		}
+		return nil
+	})
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewDeliverWebhook(log.NewAELogger(), taskQueue, client, subscriberRepo, deliveryRepo)
+
+	newDelivery := func(id string) {
+		if err := deliveryRepo.Save(ctx, entity.NewWebhookDelivery(id, s.ID, "feed", `{"id":"`+id+`"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// succeeded
+	status = http.StatusOK
+	newDelivery("d1")
+	if err := u.Do(ctx, usecase.DeliverWebhookParams{DeliveryID: "d1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 || requests[0].Secret != secret || requests[0].URL != "http://localhost/hook" || string(requests[0].Body) != `{"id":"d1"}` {
+		t.Errorf("Expected signed request to the subscriber, got %v", requests)
+	}
+	if d, _ := deliveryRepo.Find(ctx, "d1"); d.Status != entity.WebhookDeliverySucceeded || d.Attempts != 1 {
+		t.Errorf("Expected succeeded delivery, got %v", d)
+	}
+
+	// the delivered event is not posted twice
+	if err := u.Do(ctx, usecase.DeliverWebhookParams{DeliveryID: "d1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("Expected requests length 1, got %v", len(requests))
+	}
+
+	// retried with backoff until gave up, and the subscriber is disabled after repeated failures
+	status = http.StatusInternalServerError
+	for i, id := range []string{"d2", "d3", "d4"} {
+		newDelivery(id)
+		for attempt := 1; attempt <= 5; attempt++ {
+			taskQueue.Tasks = nil
+			if err := u.Do(ctx, usecase.DeliverWebhookParams{DeliveryID: id}); err != nil {
+				t.Fatal(err)
+			}
+			if attempt < 5 {
+				if len(taskQueue.Tasks) != 1 {
+					t.Fatalf("Expected retry task, got %v", taskQueue.Tasks)
+				}
+				if expected := time.Duration(30<<uint(attempt-1)) * time.Second; taskQueue.Tasks[0].Delay != expected {
+					t.Errorf("Expected delay %v, got %v", expected, taskQueue.Tasks[0].Delay)
+				}
+			} else if len(taskQueue.Tasks) != 0 {
+				t.Errorf("Expected no more retry, got %v", taskQueue.Tasks)
+			}
+		}
+
+		d, err := deliveryRepo.Find(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Status != entity.WebhookDeliveryFailed || d.Attempts != 5 || d.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected failed delivery, got %v", d)
+		}
+		v, err := subscriberRepo.Find(ctx, s.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Failures != i+1 || v.Disabled != (i == 2) {
+			t.Errorf("Expected failures %v, got %v disabled:%v", i+1, v.Failures, v.Disabled)
+		}
+	}
+
+	// the disabled subscriber does not receive events
+	n := len(requests)
+	newDelivery("d5")
+	if err := u.Do(ctx, usecase.DeliverWebhookParams{DeliveryID: "d5"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != n {
+		t.Errorf("Expected no requests to the disabled subscriber, got %v", len(requests)-n)
+	}
+	if d, _ := deliveryRepo.Find(ctx, "d5"); d.Status != entity.WebhookDeliveryFailed {
+		t.Errorf("Expected failed delivery, got %v", d)
+	}
+}
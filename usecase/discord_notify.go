@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/discord"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// DiscordNotify use case
+	DiscordNotify struct {
+		log       log.Logger
+		taskQueue event.TaskQueue
+		client    discord.Client
+		repo      entity.DiscordWebhookRepository
+	}
+
+	// DiscordNotifyParams input parameters
+	DiscordNotifyParams struct {
+		Request discord.Request
+	}
+)
+
+// NewDiscordNotify returns DiscordNotify use case
+func NewDiscordNotify(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	client discord.Client,
+	repo entity.DiscordWebhookRepository) *DiscordNotify {
+	return &DiscordNotify{
+		log:       log,
+		taskQueue: taskQueue,
+		client:    client,
+		repo:      repo,
+	}
+}
+
+// Do posts message to discord webhook
+func (use *DiscordNotify) Do(ctx context.Context, params DiscordNotifyParams) error {
+	const errTag = "DiscordNotify.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	request := params.Request
+	err := use.client.Post(ctx, request.WebhookURL, request.Message)
+	if err != nil {
+		if err == discord.ErrInvalidWebhook {
+			err = use.repo.Delete(ctx, request.ID)
+			use.log.Infof(ctx, "delete id:%v err:%v", request.ID, err)
+			return errors.Wrap(err, errTag)
+		}
+		if e, ok := err.(*discord.RateLimitError); ok {
+			if err := pushAfterRateLimit(ctx, use.taskQueue, eventtask.NewDiscord(request), e.RetryAfter); err != nil {
+				return errors.Wrap(err, errTag)
+			}
+			use.log.Warningf(ctx, "discord rate limited id:%v retry after:%v", request.ID, e.RetryAfter)
+			return nil
+		}
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "discord notify id:%v", request.ID)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/discord"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+// discordClientFunc is a discord.Client that returns the result of the function
+type discordClientFunc func(string) error
+
+func (f discordClientFunc) Post(_ context.Context, webhookURL string, _ discord.Message) error {
+	return f(webhookURL)
+}
+
+func TestDiscordNotify_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	repo := entity.NewDiscordWebhookRepository(dao.NewDatastoreHandler())
+	for _, v := range []string{"https://discord.com/api/webhooks/1/a", "https://discord.com/api/webhooks/2/b"} {
+		w, err := entity.NewDiscordWebhook(config.C().Discord.TokenKey, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Save(ctx, w); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	client := discordClientFunc(func(webhookURL string) error {
+		switch webhookURL {
+		case "https://discord.com/api/webhooks/1/a":
+			return &discord.RateLimitError{RetryAfter: 3 * time.Second}
+		case "https://discord.com/api/webhooks/2/b":
+			return discord.ErrInvalidWebhook
+		}
+		return nil
+	})
+	u := usecase.NewDiscordNotify(log.NewAELogger(), taskQueue, client, repo)
+
+	webhooks, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range webhooks {
+		webhookURL, err := w.URL(config.C().Discord.TokenKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		params := usecase.DiscordNotifyParams{Request: discord.Request{
+			ID:         w.ID,
+			WebhookURL: webhookURL,
+			Message:    discord.Message{Embeds: []discord.Embed{{Title: "title"}}},
+		}}
+		if err := u.Do(ctx, params); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// rate limited request is enqueued again after retry-after
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+	if taskQueue.Tasks[0].Delay != 3*time.Second {
+		t.Errorf("Expected delay 3s, got %v", taskQueue.Tasks[0].Delay)
+	}
+
+	// invalid webhook is deleted
+	webhooks, err = repo.FindAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 1 {
+		t.Errorf("Expected webhooks length 1, got %v", len(webhooks))
+	}
+}
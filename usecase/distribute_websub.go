@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/syndication"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/validator"
+	"github.com/utahta/momoclo-channel/websub"
+)
+
+type (
+	// DistributeWebSub use case
+	DistributeWebSub struct {
+		log          log.Logger
+		client       websub.Client
+		repo         entity.WebSubSubscriptionRepository
+		lineItemRepo entity.LineItemRepository
+	}
+
+	// DistributeWebSubParams input parameters
+	DistributeWebSubParams struct {
+		SubscriptionID string `validate:"required"`
+	}
+)
+
+// NewDistributeWebSub returns DistributeWebSub use case
+func NewDistributeWebSub(
+	log log.Logger,
+	client websub.Client,
+	repo entity.WebSubSubscriptionRepository,
+	lineItemRepo entity.LineItemRepository) *DistributeWebSub {
+	return &DistributeWebSub{
+		log:          log,
+		client:       client,
+		repo:         repo,
+		lineItemRepo: lineItemRepo,
+	}
+}
+
+// Do posts the current content of the topic to the subscriber
+// the subscription is deleted if the subscriber responds 410 Gone
+func (use *DistributeWebSub) Do(ctx context.Context, params DistributeWebSubParams) error {
+	const errTag = "DistributeWebSub.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	s, err := use.repo.Find(ctx, params.SubscriptionID)
+	if err == dao.ErrNoSuchEntity {
+		return nil // already unsubscribed
+	}
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if s.Expired(timeutil.Now()) {
+		return nil
+	}
+
+	ext, code, err := parseWebSubTopic(s.Topic)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	contentType, encode, _ := syndication.Encoding(ext)
+
+	buildFeed := NewBuildFeed(use.log, use.lineItemRepo)
+	feed, err := buildFeed.Do(ctx, BuildFeedParams{
+		Code:    code,
+		SiteURL: config.C().App.BaseURL + "/",
+		FeedURL: s.Topic,
+		HubURL:  websub.HubURL(),
+	})
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	body, err := encode(feed)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	secret, err := s.Secret(config.C().WebSub.TokenKey)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	err = use.client.Distribute(ctx, websub.Content{
+		Callback:    s.Callback,
+		Topic:       s.Topic,
+		HubURL:      websub.HubURL(),
+		Secret:      secret,
+		ContentType: contentType,
+		Body:        body,
+	})
+	if err == websub.ErrGone {
+		use.log.Warningf(ctx, "websub subscriber is gone topic:%v callback:%v", s.Topic, s.Callback)
+		return errors.Wrap(use.repo.Delete(ctx, s.ID), errTag)
+	}
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "distribute websub content topic:%v callback:%v", s.Topic, s.Callback)
+
+	return nil
+}
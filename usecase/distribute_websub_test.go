@@ -0,0 +1,89 @@
+package usecase_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/syndication"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"github.com/utahta/momoclo-channel/websub"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestDistributeWebSub_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	baseURL := config.C().App.BaseURL
+	config.C().App.BaseURL = "http://localhost"
+	defer func() { config.C().App.BaseURL = baseURL }()
+
+	h := dao.NewDatastoreHandler()
+	repo := entity.NewWebSubSubscriptionRepository(h)
+	lineItemRepo := entity.NewLineItemRepository(h)
+	item := entity.NewLineItem("http://localhost/entry-1", "entry title", "http://localhost/entry-1", time.Now(), nil, nil)
+	if err := lineItemRepo.Save(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := entity.NewWebSubSubscription(config.C().WebSub.TokenKey, "http://localhost/feed.rss", "http://localhost/callback", "secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := entity.NewWebSubSubscription(config.C().WebSub.TokenKey, "http://localhost/feed.atom", "http://localhost/callback", "", -time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*entity.WebSubSubscription{active, expired} {
+		if err := repo.Save(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	if err := usecase.NewPublishWebSub(log.NewAELogger(), taskQueue, repo).Do(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 1 || taskQueue.Tasks[0].Object.(string) != active.ID {
+		t.Fatalf("Expected a distribution task of active subscription, got %v", taskQueue.Tasks)
+	}
+	if _, err := repo.Find(ctx, expired.ID); err != dao.ErrNoSuchEntity {
+		t.Errorf("Expected expired subscription is deleted, got %v", err)
+	}
+
+	client := &websubClient{}
+	u := usecase.NewDistributeWebSub(log.NewAELogger(), client, repo, lineItemRepo)
+	if err := u.Do(ctx, usecase.DistributeWebSubParams{SubscriptionID: active.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.contents) != 1 {
+		t.Fatalf("Expected content length 1, got %v", len(client.contents))
+	}
+	content := client.contents[0]
+	if content.Secret != "secret" || content.ContentType != syndication.ContentTypeRSS || content.HubURL != "http://localhost/websub/hub" {
+		t.Errorf("Unexpected content %v", content)
+	}
+	if !strings.Contains(string(content.Body), "entry title") {
+		t.Errorf("Expected the entry in the content, got %s", content.Body)
+	}
+
+	client.err = websub.ErrGone
+	if err := u.Do(ctx, usecase.DistributeWebSubParams{SubscriptionID: active.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Find(ctx, active.ID); err != dao.ErrNoSuchEntity {
+		t.Errorf("Expected gone subscription is deleted, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/bluesky"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+)
+
+type (
+	// EnqueueBluesky use case
+	EnqueueBluesky struct {
+		log        log.Logger
+		taskQueue  event.TaskQueue
+		transactor dao.Transactor
+		itemRepo   entity.ChannelItemRepository
+	}
+)
+
+// NewEnqueueBluesky returns EnqueueBluesky use case
+func NewEnqueueBluesky(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	transactor dao.Transactor,
+	itemRepo entity.ChannelItemRepository) *EnqueueBluesky {
+	return &EnqueueBluesky{
+		log:        log,
+		taskQueue:  taskQueue,
+		transactor: transactor,
+		itemRepo:   itemRepo,
+	}
+}
+
+// Notify implements notifier.Notifier
+// it converts the notification to a thread of posts and enqueues it
+func (use *EnqueueBluesky) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueBluesky.Notify failed"
+
+	var requests []bluesky.PostRequest
+	if n.Kind == notifier.KindFeed {
+		recorded, err := recordFeedNotification(ctx, use.transactor, use.itemRepo, notifier.ChannelBluesky, n)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		if !recorded {
+			return nil // already enqueued
+		}
+		requests = blueskyFeedRequests(*n.FeedItem)
+	} else {
+		requests = []bluesky.PostRequest{{Text: tweetText(n)}} // same format as tweets
+	}
+
+	task := eventtask.NewBlueskyPosts(requests)
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue bluesky requests:%#v", requests)
+
+	return nil
+}
+
+// blueskyFeedRequests formats the feed item to a thread of posts
+// the overflowing images are posted as replies of the first post, and videos are posted as links
+func blueskyFeedRequests(item crawler.FeedItem) []bluesky.PostRequest {
+	var requests []bluesky.PostRequest
+
+	imagesURLs := chunkURLs(item.ImageURLs, bluesky.MaxImageNum)
+
+	first := bluesky.PostRequest{Text: blueskyFeedText(item)}
+	if len(imagesURLs) > 0 {
+		first.ImageURLs = imagesURLs[0]
+		imagesURLs = imagesURLs[1:]
+	}
+	requests = append(requests, first)
+
+	for _, imageURLs := range imagesURLs {
+		requests = append(requests, bluesky.PostRequest{ImageURLs: imageURLs})
+	}
+	for _, videoURL := range item.VideoURLs {
+		requests = append(requests, bluesky.PostRequest{Text: videoURL})
+	}
+	return requests
+}
+
+// blueskyFeedText returns the text of the first post that fits bluesky.MaxTextLength
+func blueskyFeedText(item crawler.FeedItem) string {
+	suffix := fmt.Sprintf("\n%s\n#momoclo #ももクロ", item.EntryURL)
+	maxCharCount := bluesky.MaxTextLength - len([]rune(suffix))
+
+	runes := []rune(fmt.Sprintf("%s %s", item.Title, item.EntryTitle))
+	if maxCharCount > 3 && len(runes) > maxCharCount {
+		runes = append(runes[0:maxCharCount-3], []rune("...")...)
+	}
+	return string(runes) + suffix
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/discord"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+)
+
+type (
+	// EnqueueDiscord use case
+	EnqueueDiscord struct {
+		log         log.Logger
+		taskQueue   event.TaskQueue
+		transactor  dao.Transactor
+		itemRepo    entity.ChannelItemRepository
+		webhookRepo entity.DiscordWebhookRepository
+		preview     entity.PreviewRepository
+	}
+)
+
+// NewEnqueueDiscord returns EnqueueDiscord use case
+func NewEnqueueDiscord(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	transactor dao.Transactor,
+	itemRepo entity.ChannelItemRepository,
+	webhookRepo entity.DiscordWebhookRepository,
+	preview entity.PreviewRepository) *EnqueueDiscord {
+	return &EnqueueDiscord{
+		log:         log,
+		taskQueue:   taskQueue,
+		transactor:  transactor,
+		itemRepo:    itemRepo,
+		webhookRepo: webhookRepo,
+		preview:     preview,
+	}
+}
+
+// Notify implements notifier.Notifier
+// it converts the notification to a rich embed and enqueues it for each webhook
+func (use *EnqueueDiscord) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueDiscord.Notify failed"
+
+	var msg discord.Message
+	if n.Kind == notifier.KindFeed {
+		recorded, err := recordFeedNotification(ctx, use.transactor, use.itemRepo, notifier.ChannelDiscord, n)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		if !recorded {
+			return nil // already enqueued
+		}
+		msg = discordFeedMessage(*n.FeedItem)
+	} else {
+		msg = discordMessage(n)
+	}
+
+	webhooks, err := use.webhookRepo.FindAll(ctx)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	if ok, err := savePreview(ctx, use.log, use.preview, notifier.ChannelDiscord, len(webhooks), msg); ok || err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	tasks := make([]event.Task, 0, len(webhooks))
+	for _, w := range webhooks {
+		webhookURL, err := w.URL(config.C().Discord.TokenKey)
+		if err != nil {
+			use.log.Errorf(ctx, "%v: get webhook url err:%v", errTag, err)
+			continue
+		}
+		tasks = append(tasks, eventtask.NewDiscord(discord.Request{
+			ID:         w.ID,
+			WebhookURL: webhookURL,
+			Message:    msg,
+		}))
+	}
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue discord tasks len:%v", len(tasks))
+
+	return nil
+}
+
+// discordMessage formats the message notification to discord message
+func discordMessage(n notifier.Notification) discord.Message {
+	embed := discord.Embed{Title: n.Text, URL: n.URL}
+	if !n.CreatedAt.IsZero() {
+		embed.Timestamp = n.CreatedAt.Format(time.RFC3339)
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// discordFeedMessage formats the feed item to discord message that has a rich embed
+func discordFeedMessage(item crawler.FeedItem) discord.Message {
+	embed := discord.Embed{
+		Title:       item.EntryTitle,
+		URL:         item.EntryURL,
+		Author:      &discord.EmbedName{Name: item.Title},
+		Description: item.Summary(crawlerSettings().SummaryLength),
+		Timestamp:   item.PublishedAt.Format(time.RFC3339),
+	}
+	if f, ok := crawler.FindFeedByURL(item.EntryURL); ok {
+		embed.Color = f.ColorValue()
+	}
+	if len(item.ImageURLs) > 0 {
+		embed.Image = &discord.EmbedImage{URL: item.ImageURLs[0]}
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
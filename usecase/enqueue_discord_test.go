@@ -0,0 +1,161 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/discord"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestEnqueueDiscord_Notify(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	h := dao.NewDatastoreHandler()
+	webhookRepo := entity.NewDiscordWebhookRepository(h)
+	w, err := entity.NewDiscordWebhook(config.C().Discord.TokenKey, "https://discord.com/api/webhooks/1/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := webhookRepo.Save(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewEnqueueDiscord(
+		log.NewAELogger(),
+		taskQueue,
+		dao.NewDatastoreTransactor(),
+		entity.NewChannelItemRepository(h),
+		webhookRepo,
+		entity.NewPreviewRepository(h),
+	)
+
+	n := notifier.NewFeedNotification(crawler.FeedItem{
+		Title:       "title",
+		URL:         "http://localhost",
+		EntryTitle:  "entry title",
+		EntryURL:    "http://localhost/entry",
+		ImageURLs:   []string{"http://localhost/img_1"},
+		PublishedAt: time.Now(),
+	})
+	for i := 0; i < 2; i++ {
+		if err := u.Notify(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the same entry is enqueued only once
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+	request := taskQueue.Tasks[0].Object.(discord.Request)
+	if request.ID != w.ID || request.WebhookURL != "https://discord.com/api/webhooks/1/a" {
+		t.Errorf("Expected request of the webhook, got %v", request)
+	}
+	if embed := request.Message.Embeds[0]; embed.Title != "entry title" || embed.Image.URL != "http://localhost/img_1" {
+		t.Errorf("Expected embed of the entry, got %v", embed)
+	}
+}
+
+func TestEnqueueDiscord_NotifyFeedMessage(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	h := dao.NewDatastoreHandler()
+	webhookRepo := entity.NewDiscordWebhookRepository(h)
+	w, err := entity.NewDiscordWebhook(config.C().Discord.TokenKey, "https://discord.com/api/webhooks/1/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := webhookRepo.Save(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewEnqueueDiscord(
+		log.NewAELogger(),
+		taskQueue,
+		dao.NewDatastoreTransactor(),
+		entity.NewChannelItemRepository(h),
+		webhookRepo,
+		entity.NewPreviewRepository(h),
+	)
+
+	tests := []struct {
+		item          crawler.FeedItem
+		expectedColor int
+		expectedImage string
+	}{
+		{
+			crawler.FeedItem{
+				Title:       "title",
+				URL:         "https://ameblo.jp/momota-sd/",
+				EntryTitle:  "entry title",
+				EntryURL:    "https://ameblo.jp/momota-sd/entry-1.html",
+				ImageURLs:   []string{"https://localhost/1.jpg", "https://localhost/2.jpg"},
+				PublishedAt: time.Date(2017, 11, 1, 20, 0, 0, 0, time.UTC),
+			},
+			0xff0000, "https://localhost/1.jpg", // member colour and the first image
+		},
+		{
+			crawler.FeedItem{
+				Title:       "title",
+				URL:         "http://localhost",
+				EntryTitle:  "entry title",
+				EntryURL:    "http://localhost/entry-1.html",
+				PublishedAt: time.Date(2017, 11, 1, 20, 0, 0, 0, time.UTC),
+			},
+			0, "",
+		},
+	}
+
+	for _, test := range tests {
+		taskQueue.Tasks = nil
+		if err := u.Notify(ctx, notifier.NewFeedNotification(test.item)); err != nil {
+			t.Fatal(err)
+		}
+		if len(taskQueue.Tasks) != 1 {
+			t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+		}
+
+		msg := taskQueue.Tasks[0].Object.(discord.Request).Message
+		if len(msg.Embeds) != 1 {
+			t.Fatalf("Expected embeds length 1, got %v", len(msg.Embeds))
+		}
+		embed := msg.Embeds[0]
+		if embed.Title != test.item.EntryTitle || embed.URL != test.item.EntryURL {
+			t.Errorf("Expected %v %v, got %v %v", test.item.EntryTitle, test.item.EntryURL, embed.Title, embed.URL)
+		}
+		if embed.Author == nil || embed.Author.Name != test.item.Title {
+			t.Errorf("Expected author %v, got %v", test.item.Title, embed.Author)
+		}
+		if embed.Color != test.expectedColor {
+			t.Errorf("Expected colour %x, got %x", test.expectedColor, embed.Color)
+		}
+		if (test.expectedImage == "" && embed.Image != nil) || (test.expectedImage != "" && (embed.Image == nil || embed.Image.URL != test.expectedImage)) {
+			t.Errorf("Expected image %q, got %v", test.expectedImage, embed.Image)
+		}
+		if embed.Timestamp != "2017-11-01T20:00:00Z" {
+			t.Errorf("Expected timestamp 2017-11-01T20:00:00Z, got %v", embed.Timestamp)
+		}
+	}
+}
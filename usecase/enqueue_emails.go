@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mailer"
+	"github.com/utahta/momoclo-channel/notifier"
+)
+
+type (
+	// EnqueueEmails use case
+	EnqueueEmails struct {
+		log            log.Logger
+		taskQueue      event.TaskQueue
+		transactor     dao.Transactor
+		itemRepo       entity.ChannelItemRepository
+		subscriberRepo entity.EmailSubscriberRepository
+		preview        entity.PreviewRepository
+	}
+)
+
+// NewEnqueueEmails returns EnqueueEmails use case
+func NewEnqueueEmails(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	transactor dao.Transactor,
+	itemRepo entity.ChannelItemRepository,
+	subscriberRepo entity.EmailSubscriberRepository,
+	preview entity.PreviewRepository) *EnqueueEmails {
+	return &EnqueueEmails{
+		log:            log,
+		taskQueue:      taskQueue,
+		transactor:     transactor,
+		itemRepo:       itemRepo,
+		subscriberRepo: subscriberRepo,
+		preview:        preview,
+	}
+}
+
+// Notify implements notifier.Notifier
+// it enqueues a mail for each subscriber who receives mails immediately
+// the subscribers of digests receive the entries by SendEmailDigests
+func (use *EnqueueEmails) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueEmails.Notify failed"
+
+	var subject, body string
+	if n.Kind == notifier.KindFeed {
+		recorded, err := recordFeedNotification(ctx, use.transactor, use.itemRepo, notifier.ChannelEmail, n)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		if !recorded {
+			return nil // already enqueued
+		}
+		item := n.FeedItem
+		subject = fmt.Sprintf("【%s】%s", item.Title, item.EntryTitle)
+		body = fmt.Sprintf("%s\n%s\n%s\n", item.Title, item.EntryTitle, item.EntryURL)
+	} else {
+		subject = strings.SplitN(n.Text, "\n", 2)[0]
+		body = fmt.Sprintf("%s\n%s\n", n.Text, n.URL)
+	}
+
+	subscribers, err := use.subscriberRepo.FindConfirmed(ctx, entity.EmailFrequencyImmediate)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	mails := make([]mailer.Mail, len(subscribers))
+	for i, s := range subscribers {
+		mails[i] = mailer.Mail{
+			To:             s.Email,
+			Subject:        subject,
+			Body:           body + emailFooter(s),
+			UnsubscribeURL: emailLinkURL(s, entity.EmailActionUnsubscribe, nil),
+		}
+	}
+
+	preview := struct{ Subject, Body string }{subject, body}
+	if ok, err := savePreview(ctx, use.log, use.preview, notifier.ChannelEmail, len(mails), preview); ok || err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	tasks := make([]event.Task, len(mails))
+	for i, mail := range mails {
+		tasks[i] = eventtask.NewEmail(mail)
+	}
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue email tasks len:%v", len(tasks))
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package usecase_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mailer"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestEnqueueEmails_Notify(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	h := dao.NewDatastoreHandler()
+	subscriberRepo := entity.NewEmailSubscriberRepository(h)
+	for _, v := range []string{entity.EmailFrequencyImmediate, entity.EmailFrequencyDaily} {
+		s := entity.NewEmailSubscriber(v+"@example.com", v)
+		s.Confirmed = true
+		if err := subscriberRepo.Save(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewEnqueueEmails(
+		log.NewAELogger(),
+		taskQueue,
+		dao.NewDatastoreTransactor(),
+		entity.NewChannelItemRepository(h),
+		subscriberRepo,
+		entity.NewPreviewRepository(h),
+	)
+
+	n := notifier.NewFeedNotification(crawler.FeedItem{
+		Title:       "title",
+		URL:         "http://localhost",
+		EntryTitle:  "entry title",
+		EntryURL:    "http://localhost/entry",
+		PublishedAt: time.Now(),
+	})
+	for i := 0; i < 2; i++ {
+		if err := u.Notify(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// only the immediate subscriber receives the entry once
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+	mail := taskQueue.Tasks[0].Object.(mailer.Mail)
+	if mail.To != "immediate@example.com" || mail.Subject != "【title】entry title" {
+		t.Errorf("Expected mail of the entry, got %v", mail)
+	}
+	if !strings.Contains(mail.Body, "http://localhost/entry") || !strings.Contains(mail.Body, mail.UnsubscribeURL) {
+		t.Errorf("Expected entry url and unsubscribe link, got %q", mail.Body)
+	}
+}
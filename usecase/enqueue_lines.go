@@ -6,10 +6,14 @@ import (
 	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/crawler"
 	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
 	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/event"
 	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/linebot"
+	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
 	"github.com/utahta/momoclo-channel/validator"
 )
 
@@ -20,6 +24,7 @@ type (
 		taskQueue  event.TaskQueue
 		transactor dao.Transactor
 		repo       entity.LineItemRepository
+		history    *feedHistory
 	}
 
 	// EnqueueLinesParams input parameters
@@ -39,6 +44,7 @@ func NewEnqueueLines(
 		taskQueue:  taskQueue,
 		transactor: transactor,
 		repo:       repo,
+		history:    newLineHistory(log, transactor, repo),
 	}
 }
 
@@ -58,14 +64,17 @@ func (use *EnqueueLines) Do(ctx context.Context, params EnqueueLinesParams) erro
 		params.FeedItem.ImageURLs,
 		params.FeedItem.VideoURLs,
 	)
-	if use.repo.Exists(ctx, item.ID) {
-		return nil // already enqueued
+	if prev, err := use.history.findPrev(ctx, item.ID, params.FeedItem.LegacyUniqueURLs()); err == nil {
+		return use.doEdited(ctx, prev, item, params.FeedItem)
 	}
 
 	err := use.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
 		if _, err := use.repo.Find(ctx, item.ID); err != dao.ErrNoSuchEntity {
 			return err
 		}
+		if dryrun.Enabled(ctx) {
+			return nil // history is not recorded in dry-run mode
+		}
 		return use.repo.Save(ctx, item)
 	}, nil)
 	if err != nil {
@@ -79,7 +88,37 @@ func (use *EnqueueLines) Do(ctx context.Context, params EnqueueLinesParams) erro
 		return errors.Errorf("%v: invalid enqueue line messages", errTag)
 	}
 
-	task := eventtask.NewLinesBroadcast(messages)
+	task := eventtask.NewLinesBroadcast(lineFeedBroadcast(params.FeedItem, messages))
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue line messages:%#v", messages)
+
+	if dryrun.Enabled(ctx) {
+		return nil // the new entry is not committed in dry-run mode
+	}
+	// the history is the content of aggregated feeds, so the subscribers of hub are notified
+	if err := use.taskQueue.Push(ctx, eventtask.NewWebSubPublish()); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	return nil
+}
+
+// Notify implements notifier.Notifier
+// feed notifications are deduplicated by line history
+func (use *EnqueueLines) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueLines.Notify failed"
+
+	if n.Kind == notifier.KindFeed {
+		if n.FeedItem == nil {
+			return errors.Errorf("%v: feed item is empty", errTag)
+		}
+		return use.Do(ctx, EnqueueLinesParams{FeedItem: *n.FeedItem})
+	}
+
+	messages := []linenotify.Message{{Text: lineText(n)}}
+	task := eventtask.NewLinesBroadcast(linenotify.Broadcast{Messages: messages, Kind: string(n.Kind)})
 	if err := use.taskQueue.Push(ctx, task); err != nil {
 		return errors.Wrap(err, errTag)
 	}
@@ -87,3 +126,54 @@ func (use *EnqueueLines) Do(ctx context.Context, params EnqueueLinesParams) erro
 
 	return nil
 }
+
+// lineText formats the message notification to LINE message
+func lineText(n notifier.Notification) string {
+	text := "\n" + n.Text
+	if n.URL != "" {
+		text += "\n" + n.URL
+	}
+	return text
+}
+
+// lineFeedBroadcast returns the broadcast of given messages that is filtered by the feed code and the members of the entry
+// the entry relates to the member of the blog and the members whose full names appear in the title
+func lineFeedBroadcast(item crawler.FeedItem, messages []linenotify.Message) linenotify.Broadcast {
+	b := linenotify.Broadcast{Messages: messages, Kind: string(notifier.KindFeed)}
+	var member string
+	if f, ok := crawler.FindFeedByURL(item.EntryURL); ok {
+		b.Code = f.Code.String()
+		member = f.Member
+	}
+	if member != "" {
+		b.Members = append(b.Members, member)
+	}
+	for _, m := range linebot.FindFullMemberNames(item.EntryTitle) {
+		if m != member { // the names found in the title are unique
+			b.Members = append(b.Members, m)
+		}
+	}
+	return b
+}
+
+// doEdited announces the edit of already enqueued entry
+func (use *EnqueueLines) doEdited(ctx context.Context, prev feedHistoryItem, item *entity.LineItem, feedItem crawler.FeedItem) error {
+	const errTag = "EnqueueLines.doEdited failed"
+
+	edited, ok, err := use.history.edit(ctx, prev, item, feedItem)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if !ok {
+		return nil
+	}
+
+	messages := edited.ToLineNotifyMessages()
+	task := eventtask.NewLinesBroadcast(lineFeedBroadcast(feedItem, messages))
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue edited line messages:%#v", messages)
+
+	return nil
+}
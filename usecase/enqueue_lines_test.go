@@ -1,6 +1,7 @@
 package usecase_test
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/utahta/momoclo-channel/dao"
 	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
 	"github.com/utahta/momoclo-channel/testutil"
 	"github.com/utahta/momoclo-channel/usecase"
@@ -61,8 +63,8 @@ func TestEnqueueLines_Do(t *testing.T) {
 		t.Errorf("Expected line item exists, but not found. feedItem:%v", feedItem)
 	}
 
-	if len(taskQueue.Tasks) != 1 {
-		t.Errorf("Expected task length 1, got %v", len(taskQueue.Tasks))
+	if len(taskQueue.Tasks) != 2 {
+		t.Fatalf("Expected task length 2, got %v", len(taskQueue.Tasks))
 	}
 	if taskQueue.Tasks[0].QueueName != "queue-line" {
 		t.Errorf("Expected queue name queue-line, got %v", taskQueue.Tasks[0].QueueName)
@@ -70,4 +72,164 @@ func TestEnqueueLines_Do(t *testing.T) {
 	if taskQueue.Tasks[0].Path != "/line/notify/broadcast" {
 		t.Errorf("Expected queue path /queue/line/broadcast, got %v", taskQueue.Tasks[0].Path)
 	}
+	if taskQueue.Tasks[1].Path != "/websub/publish" {
+		t.Errorf("Expected queue path /websub/publish, got %v", taskQueue.Tasks[1].Path)
+	}
+}
+
+func TestEnqueueLines_DoMembers(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewEnqueueLines(log.NewAELogger(), taskQueue, dao.NewDatastoreTransactor(), entity.NewLineItemRepository(dao.NewDatastoreHandler()))
+	publishedAt, _ := time.Parse("2006-01-02 15:04:05", "2008-05-17 00:00:00")
+
+	tests := []struct {
+		entryTitle string
+		entryURL   string
+		members    []string
+	}{
+		{"これについて", "http://localhost/entry", nil},
+		{"これについて", "https://ameblo.jp/takagi-sd/entry-1.html", []string{"高城れに"}},
+		{"高城れにです", "https://ameblo.jp/takagi-sd/entry-2.html", []string{"高城れに"}},
+		{"百田夏菜子と高城れに", "https://ameblo.jp/takagi-sd/entry-3.html", []string{"高城れに", "百田夏菜子"}},
+	}
+
+	for _, test := range tests {
+		taskQueue.Tasks = nil
+		feedItem := crawler.FeedItem{EntryTitle: test.entryTitle, EntryURL: test.entryURL, PublishedAt: publishedAt}
+		if err := u.Do(ctx, usecase.EnqueueLinesParams{FeedItem: feedItem}); err != nil {
+			t.Fatal(err)
+		}
+		if len(taskQueue.Tasks) == 0 {
+			t.Fatalf("Expected broadcast task, got nothing")
+		}
+
+		b := taskQueue.Tasks[0].Object.(linenotify.Broadcast)
+		if !reflect.DeepEqual(b.Members, test.members) {
+			t.Errorf("Expected members %v, got %v. title:%v", test.members, b.Members, test.entryTitle)
+		}
+	}
+}
+
+func TestEnqueueLines_DoEdited(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	taskQueue := eventtest.NewTaskQueue()
+	repo := entity.NewLineItemRepository(dao.NewDatastoreHandler())
+	u := usecase.NewEnqueueLines(log.NewAELogger(), taskQueue, dao.NewDatastoreTransactor(), repo)
+	publishedAt, _ := time.Parse("2006-01-02 15:04:05", "2008-05-17 00:00:00")
+	feedItem := crawler.FeedItem{
+		Title:       "title",
+		URL:         "http://localhost",
+		EntryTitle:  "entry_title",
+		EntryURL:    "http://localhost/entry",
+		ImageURLs:   []string{"http://localhost/img_1"},
+		PublishedAt: publishedAt,
+	}
+
+	if err := u.Do(ctx, usecase.EnqueueLinesParams{FeedItem: feedItem}); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Do(ctx, usecase.EnqueueLinesParams{FeedItem: feedItem}); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 2 {
+		t.Fatalf("Expected task length 2, got %v", len(taskQueue.Tasks))
+	}
+
+	feedItem.ImageURLs = append(feedItem.ImageURLs, "http://localhost/img_2")
+	if err := u.Do(ctx, usecase.EnqueueLinesParams{FeedItem: feedItem}); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 3 {
+		t.Fatalf("Expected task length 3, got %v", len(taskQueue.Tasks))
+	}
+
+	b, ok := taskQueue.Tasks[2].Object.(linenotify.Broadcast)
+	if !ok || len(b.Messages) != 1 {
+		t.Fatalf("Expected an edited message, got %v", taskQueue.Tasks[2].Object)
+	}
+	messages := b.Messages
+	if messages[0].ImageURL != "http://localhost/img_2" {
+		t.Errorf("Expected added image only, got %v", messages[0].ImageURL)
+	}
+
+	item, err := repo.Find(ctx, feedItem.UniqueURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ImageURLs != "http://localhost/img_1,http://localhost/img_2" {
+		t.Errorf("Expected updated image urls, got %v", item.ImageURLs)
+	}
+}
+
+func TestEnqueueLines_DoLegacyID(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	publishedAt, _ := time.Parse("2006-01-02 15:04:05", "2008-05-17 00:00:00")
+	tests := []struct {
+		entryURL   string // the entry url at the time of storing
+		fetchedURL string
+	}{
+		{"http://s.ameblo.jp/momota-sd/entry-1.html?frm=theme", "http://s.ameblo.jp/momota-sd/entry-1.html?frm=theme"},
+		{"http://ameblo.jp/momota-sd/entry-2.html", "https://ameblo.jp/momota-sd/entry-2.html"},
+		{"https://s.ameblo.jp/momota-sd/entry-3.html", "https://ameblo.jp/momota-sd/entry-3.html"},
+	}
+
+	for _, test := range tests {
+		taskQueue := eventtest.NewTaskQueue()
+		repo := entity.NewLineItemRepository(dao.NewDatastoreHandler())
+		u := usecase.NewEnqueueLines(log.NewAELogger(), taskQueue, dao.NewDatastoreTransactor(), repo)
+
+		// stored before canonicalization
+		legacyItem := crawler.FeedItem{EntryURL: test.entryURL, PublishedAt: publishedAt}
+		legacy := entity.NewLineItem(legacyItem.LegacyUniqueURL(), "entry_title", test.entryURL, publishedAt, nil, nil)
+		if err := repo.Save(ctx, legacy); err != nil {
+			t.Fatal(err)
+		}
+
+		feedItem := crawler.FeedItem{
+			Title:       "title",
+			URL:         "https://ameblo.jp/momota-sd",
+			EntryTitle:  "entry_title",
+			EntryURL:    test.fetchedURL,
+			PublishedAt: publishedAt,
+		}
+		if err := u.Do(ctx, usecase.EnqueueLinesParams{FeedItem: feedItem}); err != nil {
+			t.Fatal(err)
+		}
+		if len(taskQueue.Tasks) != 0 {
+			t.Errorf("Expected task length 0, got %v. url:%v", len(taskQueue.Tasks), test.entryURL)
+		}
+		if !repo.Exists(ctx, feedItem.UniqueURL()) {
+			t.Errorf("Expected line item is migrated to %v, but not found", feedItem.UniqueURL())
+		}
+		if repo.Exists(ctx, legacy.ID) {
+			t.Errorf("Expected legacy line item %v is deleted, but found", legacy.ID)
+		}
+	}
+
+	// the history has no duplicates
+	items, err := entity.NewLineItemRepository(dao.NewDatastoreHandler()).FindRecent(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != len(tests) {
+		t.Errorf("Expected line items length %v, got %v", len(tests), len(items))
+	}
 }
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mastodon"
+	"github.com/utahta/momoclo-channel/notifier"
+)
+
+type (
+	// EnqueueMastodon use case
+	EnqueueMastodon struct {
+		log        log.Logger
+		taskQueue  event.TaskQueue
+		transactor dao.Transactor
+		itemRepo   entity.ChannelItemRepository
+	}
+)
+
+// NewEnqueueMastodon returns EnqueueMastodon use case
+func NewEnqueueMastodon(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	transactor dao.Transactor,
+	itemRepo entity.ChannelItemRepository) *EnqueueMastodon {
+	return &EnqueueMastodon{
+		log:        log,
+		taskQueue:  taskQueue,
+		transactor: transactor,
+		itemRepo:   itemRepo,
+	}
+}
+
+// Notify implements notifier.Notifier
+// it converts the notification to a thread of statuses and enqueues it
+func (use *EnqueueMastodon) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueMastodon.Notify failed"
+
+	var requests []mastodon.StatusRequest
+	if n.Kind == notifier.KindFeed {
+		recorded, err := recordFeedNotification(ctx, use.transactor, use.itemRepo, notifier.ChannelMastodon, n)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		if !recorded {
+			return nil // already enqueued
+		}
+		requests = mastodonFeedRequests(*n.FeedItem)
+	} else {
+		requests = []mastodon.StatusRequest{{Text: tweetText(n)}} // same format as tweets
+	}
+
+	task := eventtask.NewMastodonStatuses(requests)
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue mastodon requests:%#v", requests)
+
+	return nil
+}
+
+// mastodonFeedRequests formats the feed item to a thread of statuses
+// the overflowing media are posted as replies of the first status
+func mastodonFeedRequests(item crawler.FeedItem) []mastodon.StatusRequest {
+	var requests []mastodon.StatusRequest
+
+	imagesURLs := chunkURLs(item.ImageURLs, mastodon.MaxMediaNum)
+	videoURLs := item.VideoURLs
+
+	first := mastodon.StatusRequest{Text: fmt.Sprintf("%s %s\n%s\n#momoclo #ももクロ", item.Title, item.EntryTitle, item.EntryURL)}
+	if len(imagesURLs) > 0 {
+		first.ImageURLs = imagesURLs[0]
+		imagesURLs = imagesURLs[1:]
+	} else if len(videoURLs) > 0 {
+		first.VideoURL = videoURLs[0]
+		videoURLs = videoURLs[1:]
+	}
+	requests = append(requests, first)
+
+	for _, imageURLs := range imagesURLs {
+		requests = append(requests, mastodon.StatusRequest{ImageURLs: imageURLs})
+	}
+	for _, videoURL := range videoURLs {
+		requests = append(requests, mastodon.StatusRequest{VideoURL: videoURL})
+	}
+	return requests
+}
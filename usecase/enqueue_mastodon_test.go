@@ -0,0 +1,64 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mastodon"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestEnqueueMastodon_Notify(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	h := dao.NewDatastoreHandler()
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewEnqueueMastodon(log.NewAELogger(), taskQueue, dao.NewDatastoreTransactor(), entity.NewChannelItemRepository(h))
+
+	n := notifier.NewFeedNotification(crawler.FeedItem{
+		Title:      "title",
+		URL:        "http://localhost",
+		EntryTitle: "entry title",
+		EntryURL:   "http://localhost/entry-1.html",
+		ImageURLs: []string{
+			"http://localhost/1.jpg", "http://localhost/2.jpg", "http://localhost/3.jpg",
+			"http://localhost/4.jpg", "http://localhost/5.jpg",
+		},
+		VideoURLs: []string{"http://localhost/1.mp4"},
+	})
+	for i := 0; i < 2; i++ {
+		if err := u.Notify(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the same entry is enqueued only once
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+	requests := taskQueue.Tasks[0].Object.([]mastodon.StatusRequest)
+	if len(requests) != 3 {
+		t.Fatalf("Expected requests length 3, got %v", len(requests))
+	}
+	if expected := "title entry title\nhttp://localhost/entry-1.html\n#momoclo #ももクロ"; requests[0].Text != expected {
+		t.Errorf("Expected text %q, got %q", expected, requests[0].Text)
+	}
+	if len(requests[0].ImageURLs) != 4 || len(requests[1].ImageURLs) != 1 || requests[1].Text != "" {
+		t.Errorf("Expected overflow images in a reply, got %v", requests)
+	}
+	if requests[2].VideoURL != "http://localhost/1.mp4" {
+		t.Errorf("Expected video in the last reply, got %v", requests[2])
+	}
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/slack"
+	"github.com/utahta/momoclo-channel/timeutil"
+)
+
+type (
+	// EnqueueSlack use case
+	EnqueueSlack struct {
+		log         log.Logger
+		taskQueue   event.TaskQueue
+		transactor  dao.Transactor
+		itemRepo    entity.ChannelItemRepository
+		webhookRepo entity.SlackWebhookRepository
+		preview     entity.PreviewRepository
+	}
+)
+
+// NewEnqueueSlack returns EnqueueSlack use case
+func NewEnqueueSlack(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	transactor dao.Transactor,
+	itemRepo entity.ChannelItemRepository,
+	webhookRepo entity.SlackWebhookRepository,
+	preview entity.PreviewRepository) *EnqueueSlack {
+	return &EnqueueSlack{
+		log:         log,
+		taskQueue:   taskQueue,
+		transactor:  transactor,
+		itemRepo:    itemRepo,
+		webhookRepo: webhookRepo,
+		preview:     preview,
+	}
+}
+
+// Notify implements notifier.Notifier
+// it converts the notification to Block Kit message and enqueues it for each webhook that accepts it
+func (use *EnqueueSlack) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueSlack.Notify failed"
+
+	var (
+		msg  slack.Message
+		code = string(n.Kind)
+	)
+	if n.Kind == notifier.KindFeed {
+		recorded, err := recordFeedNotification(ctx, use.transactor, use.itemRepo, notifier.ChannelSlack, n)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		if !recorded {
+			return nil // already enqueued
+		}
+		msg = slackFeedMessage(*n.FeedItem)
+		code = n.FeedItem.FeedCode().String()
+	} else {
+		msg = slackMessage(n)
+	}
+
+	webhooks, err := use.webhookRepo.FindAll(ctx)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	accepted := webhooks[:0]
+	for _, w := range webhooks {
+		if w.Accepts(code) {
+			accepted = append(accepted, w)
+		}
+	}
+
+	if ok, err := savePreview(ctx, use.log, use.preview, notifier.ChannelSlack, len(accepted), msg); ok || err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	tasks := make([]event.Task, 0, len(accepted))
+	for _, w := range accepted {
+		webhookURL, err := w.URL(config.C().Slack.TokenKey)
+		if err != nil {
+			use.log.Errorf(ctx, "%v: get webhook url err:%v", errTag, err)
+			continue
+		}
+		tasks = append(tasks, eventtask.NewSlack(slack.Request{
+			ID:         w.ID,
+			WebhookURL: webhookURL,
+			Message:    msg,
+		}))
+	}
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue slack tasks code:%v len:%v", code, len(tasks))
+
+	return nil
+}
+
+// slackMessage formats the message notification to slack message
+func slackMessage(n notifier.Notification) slack.Message {
+	text := "*" + slack.Escape(n.Text) + "*"
+	if n.URL != "" {
+		text += "\n" + slack.Link(n.URL, "")
+	}
+	blocks := []slack.Block{{Type: slack.BlockTypeSection, Text: slack.NewMarkdown(text)}}
+	if n.Kind == notifier.KindUstream && !n.CreatedAt.IsZero() {
+		blocks = append(blocks, slack.Block{
+			Type:     slack.BlockTypeContext,
+			Elements: []slack.Text{*slack.NewMarkdown(n.CreatedAt.In(timeutil.JST()).Format("from 2006/01/02 15:04"))},
+		})
+	}
+	return slack.Message{Text: n.Text, Blocks: blocks}
+}
+
+// slackFeedMessage formats the feed item to slack message that is composed of Block Kit blocks
+func slackFeedMessage(item crawler.FeedItem) slack.Message {
+	text := "*" + slack.Link(item.EntryURL, item.EntryTitle) + "*"
+	if summary := item.Summary(crawlerSettings().SummaryLength); summary != "" {
+		text += "\n" + slack.Escape(summary)
+	}
+
+	section := slack.Block{Type: slack.BlockTypeSection, Text: slack.NewMarkdown(text)}
+	if len(item.ImageURLs) > 0 {
+		section.Accessory = &slack.Element{Type: slack.BlockTypeImage, ImageURL: item.ImageURLs[0], AltText: item.EntryTitle}
+	}
+	meta := slack.Block{
+		Type:     slack.BlockTypeContext,
+		Elements: []slack.Text{*slack.NewMarkdown(slack.Escape(item.Title) + " | " + item.PublishedAt.In(timeutil.JST()).Format("2006/01/02 15:04"))},
+	}
+
+	return slack.Message{
+		Text:   fmt.Sprintf("%s %s %s", item.Title, item.EntryTitle, item.EntryURL),
+		Blocks: []slack.Block{section, meta},
+	}
+}
@@ -0,0 +1,173 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/slack"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestEnqueueSlack_Notify(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	h := dao.NewDatastoreHandler()
+	webhookRepo := entity.NewSlackWebhookRepository(h)
+	webhooks := []struct {
+		url   string
+		codes []string
+	}{
+		{"https://hooks.slack.com/services/all", nil},
+		{"https://hooks.slack.com/services/momota", []string{"momota-sd"}},
+		{"https://hooks.slack.com/services/reminder", []string{"reminder"}},
+	}
+	for _, v := range webhooks {
+		w, err := entity.NewSlackWebhook(config.C().Slack.TokenKey, v.url, v.codes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := webhookRepo.Save(ctx, w); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewEnqueueSlack(
+		log.NewAELogger(),
+		taskQueue,
+		dao.NewDatastoreTransactor(),
+		entity.NewChannelItemRepository(h),
+		webhookRepo,
+		entity.NewPreviewRepository(h),
+	)
+
+	tests := []struct {
+		notification notifier.Notification
+		expected     []string
+	}{
+		{
+			notifier.NewFeedNotification(crawler.FeedItem{
+				Title:       "title",
+				URL:         "https://ameblo.jp/momota-sd/",
+				EntryTitle:  "entry title",
+				EntryURL:    "https://ameblo.jp/momota-sd/entry-1.html",
+				PublishedAt: time.Now(),
+			}),
+			[]string{"https://hooks.slack.com/services/all", "https://hooks.slack.com/services/momota"},
+		},
+		{
+			notifier.NewMessageNotification(notifier.KindReminder, "reminder", "", time.Now()),
+			[]string{"https://hooks.slack.com/services/all", "https://hooks.slack.com/services/reminder"},
+		},
+		{
+			notifier.NewMessageNotification(notifier.KindUstream, "live", "http://localhost/live", time.Now()),
+			[]string{"https://hooks.slack.com/services/all"},
+		},
+	}
+
+	for _, test := range tests {
+		taskQueue.Tasks = nil
+		if err := u.Notify(ctx, test.notification); err != nil {
+			t.Fatal(err)
+		}
+
+		received := map[string]bool{}
+		for _, task := range taskQueue.Tasks {
+			received[task.Object.(slack.Request).WebhookURL] = true
+		}
+		if len(received) != len(test.expected) {
+			t.Errorf("Expected webhooks %v, got %v. kind:%v", test.expected, received, test.notification.Kind)
+		}
+		for _, v := range test.expected {
+			if !received[v] {
+				t.Errorf("Expected webhook %v received. kind:%v", v, test.notification.Kind)
+			}
+		}
+	}
+
+	// the same entry is enqueued only once
+	taskQueue.Tasks = nil
+	if err := u.Notify(ctx, tests[0].notification); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 0 {
+		t.Errorf("Expected taskqueue length 0, got %v", len(taskQueue.Tasks))
+	}
+}
+
+func TestEnqueueSlack_NotifyFeedMessage(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	h := dao.NewDatastoreHandler()
+	webhookRepo := entity.NewSlackWebhookRepository(h)
+	w, err := entity.NewSlackWebhook(config.C().Slack.TokenKey, "https://hooks.slack.com/services/all", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := webhookRepo.Save(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewEnqueueSlack(
+		log.NewAELogger(),
+		taskQueue,
+		dao.NewDatastoreTransactor(),
+		entity.NewChannelItemRepository(h),
+		webhookRepo,
+		entity.NewPreviewRepository(h),
+	)
+
+	n := notifier.NewFeedNotification(crawler.FeedItem{
+		Title:       "title",
+		URL:         "http://localhost",
+		EntryTitle:  "entry <1> & 2",
+		EntryURL:    "http://localhost/entry-1.html",
+		ImageURLs:   []string{"http://localhost/1.jpg"},
+		PublishedAt: time.Date(2017, 11, 1, 11, 0, 0, 0, time.UTC),
+	})
+	if err := u.Notify(ctx, n); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+
+	msg := taskQueue.Tasks[0].Object.(slack.Request).Message
+	if expected := "title entry <1> & 2 http://localhost/entry-1.html"; msg.Text != expected {
+		t.Errorf("Expected fallback text %q, got %q", expected, msg.Text)
+	}
+	if len(msg.Blocks) != 2 {
+		t.Fatalf("Expected blocks length 2, got %v", len(msg.Blocks))
+	}
+
+	section := msg.Blocks[0]
+	if expected := "*<http://localhost/entry-1.html|entry &lt;1&gt; &amp; 2>*"; section.Text.Text != expected {
+		t.Errorf("Expected section text %q, got %q", expected, section.Text.Text)
+	}
+	if section.Accessory == nil || section.Accessory.ImageURL != "http://localhost/1.jpg" {
+		t.Errorf("Expected image accessory, got %v", section.Accessory)
+	}
+	if expected := "title | 2017/11/01 20:00"; msg.Blocks[1].Elements[0].Text != expected {
+		t.Errorf("Expected context %q, got %q", expected, msg.Blocks[1].Elements[0].Text)
+	}
+}
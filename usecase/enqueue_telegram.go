@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/telegram"
+)
+
+type (
+	// EnqueueTelegram use case
+	EnqueueTelegram struct {
+		log            log.Logger
+		taskQueue      event.TaskQueue
+		transactor     dao.Transactor
+		itemRepo       entity.ChannelItemRepository
+		subscriberRepo entity.TelegramSubscriberRepository
+		preview        entity.PreviewRepository
+	}
+)
+
+// NewEnqueueTelegram returns EnqueueTelegram use case
+func NewEnqueueTelegram(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	transactor dao.Transactor,
+	itemRepo entity.ChannelItemRepository,
+	subscriberRepo entity.TelegramSubscriberRepository,
+	preview entity.PreviewRepository) *EnqueueTelegram {
+	return &EnqueueTelegram{
+		log:            log,
+		taskQueue:      taskQueue,
+		transactor:     transactor,
+		itemRepo:       itemRepo,
+		subscriberRepo: subscriberRepo,
+		preview:        preview,
+	}
+}
+
+// Notify implements notifier.Notifier
+// it enqueues the message for each subscribed chat
+func (use *EnqueueTelegram) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueTelegram.Notify failed"
+
+	var msg telegram.Message
+	if n.Kind == notifier.KindFeed {
+		recorded, err := recordFeedNotification(ctx, use.transactor, use.itemRepo, notifier.ChannelTelegram, n)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		if !recorded {
+			return nil // already enqueued
+		}
+		msg = telegramFeedMessage(*n.FeedItem)
+	} else {
+		msg = telegramMessage(n)
+	}
+
+	subscribers, err := use.subscriberRepo.FindAll(ctx)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	if ok, err := savePreview(ctx, use.log, use.preview, notifier.ChannelTelegram, len(subscribers), msg); ok || err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	tasks := make([]event.Task, len(subscribers))
+	for i, s := range subscribers {
+		tasks[i] = eventtask.NewTelegram(telegram.Request{ID: s.ID, ChatID: s.ChatID, Message: msg})
+	}
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue telegram tasks len:%v", len(tasks))
+
+	return nil
+}
+
+// telegramMessage formats the message notification to telegram message
+func telegramMessage(n notifier.Notification) telegram.Message {
+	text := n.Text
+	if n.URL != "" {
+		text += "\n" + n.URL
+	}
+	return telegram.Message{Text: text}
+}
+
+// telegramFeedMessage formats the feed item to telegram message
+// the images are sent as albums, and the videos are linked in the text
+func telegramFeedMessage(item crawler.FeedItem) telegram.Message {
+	lines := []string{item.Title, item.EntryTitle, item.EntryURL}
+	lines = append(lines, item.VideoURLs...)
+	return telegram.Message{
+		Text:      strings.Join(lines, "\n"),
+		ImageURLs: item.ImageURLs,
+	}
+}
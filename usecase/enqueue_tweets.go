@@ -2,14 +2,19 @@ package usecase
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/crawler"
 	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
 	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/event"
 	"github.com/utahta/momoclo-channel/event/eventtask"
 	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/twitter"
 	"github.com/utahta/momoclo-channel/validator"
 )
 
@@ -20,6 +25,7 @@ type (
 		taskQueue  event.TaskQueue
 		transactor dao.Transactor
 		repo       entity.TweetItemRepository
+		history    *feedHistory
 	}
 
 	// EnqueueTweetsParams input parameters
@@ -39,6 +45,7 @@ func NewEnqueueTweets(
 		taskQueue:  taskQueue,
 		transactor: transactor,
 		repo:       repo,
+		history:    newTweetHistory(log, transactor, repo),
 	}
 }
 
@@ -58,14 +65,17 @@ func (use *EnqueueTweets) Do(ctx context.Context, params EnqueueTweetsParams) er
 		params.FeedItem.ImageURLs,
 		params.FeedItem.VideoURLs,
 	)
-	if use.repo.Exists(ctx, item.ID) {
-		return nil // already enqueued
+	if prev, err := use.history.findPrev(ctx, item.ID, params.FeedItem.LegacyUniqueURLs()); err == nil {
+		return use.doEdited(ctx, prev, item, params.FeedItem)
 	}
 
 	err := use.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
 		if _, err := use.repo.Find(ctx, item.ID); err != dao.ErrNoSuchEntity {
 			return err
 		}
+		if dryrun.Enabled(ctx) {
+			return nil // history is not recorded in dry-run mode
+		}
 		return use.repo.Save(ctx, item)
 	}, nil)
 	if err != nil {
@@ -87,3 +97,59 @@ func (use *EnqueueTweets) Do(ctx context.Context, params EnqueueTweetsParams) er
 
 	return nil
 }
+
+// Notify implements notifier.Notifier
+// feed notifications are deduplicated by tweet history
+func (use *EnqueueTweets) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueTweets.Notify failed"
+
+	if n.Kind == notifier.KindFeed {
+		if n.FeedItem == nil {
+			return errors.Errorf("%v: feed item is empty", errTag)
+		}
+		return use.Do(ctx, EnqueueTweetsParams{FeedItem: *n.FeedItem})
+	}
+
+	requests := []twitter.TweetRequest{{Text: tweetText(n)}}
+	task := eventtask.NewTweets(requests)
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue tweet requests:%#v", requests)
+
+	return nil
+}
+
+// tweetText formats the message notification to tweet
+func tweetText(n notifier.Notification) string {
+	lines := []string{n.Text}
+	if n.Kind == notifier.KindUstream {
+		lines = append(lines, n.CreatedAt.In(timeutil.JST()).Format("from 2006/01/02 15:04:05"))
+	}
+	if n.URL != "" {
+		lines = append(lines, n.URL)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// doEdited announces the edit of already enqueued entry
+func (use *EnqueueTweets) doEdited(ctx context.Context, prev feedHistoryItem, item *entity.TweetItem, feedItem crawler.FeedItem) error {
+	const errTag = "EnqueueTweets.doEdited failed"
+
+	edited, ok, err := use.history.edit(ctx, prev, item, feedItem)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if !ok {
+		return nil
+	}
+
+	requests := edited.ToTweetRequests()
+	task := eventtask.NewTweets(requests)
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue edited tweet requests:%#v", requests)
+
+	return nil
+}
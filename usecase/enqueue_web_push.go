@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/webpush"
+)
+
+type (
+	// EnqueueWebPush use case
+	EnqueueWebPush struct {
+		log        log.Logger
+		taskQueue  event.TaskQueue
+		transactor dao.Transactor
+		itemRepo   entity.ChannelItemRepository
+	}
+)
+
+// NewEnqueueWebPush returns EnqueueWebPush use case
+func NewEnqueueWebPush(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	transactor dao.Transactor,
+	itemRepo entity.ChannelItemRepository) *EnqueueWebPush {
+	return &EnqueueWebPush{
+		log:        log,
+		taskQueue:  taskQueue,
+		transactor: transactor,
+		itemRepo:   itemRepo,
+	}
+}
+
+// Notify implements notifier.Notifier
+// it enqueues a broadcast that is fanned out to the subscribers by WebPushBroadcast
+func (use *EnqueueWebPush) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueWebPush.Notify failed"
+
+	var message webpush.Message
+	if n.Kind == notifier.KindFeed {
+		recorded, err := recordFeedNotification(ctx, use.transactor, use.itemRepo, notifier.ChannelWebPush, n)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		if !recorded {
+			return nil // already enqueued
+		}
+		message = webPushFeedMessage(*n.FeedItem)
+	} else {
+		message = webpush.Message{Title: "ももクロちゃんねる", Body: n.Text, URL: n.URL}
+	}
+
+	if err := use.taskQueue.Push(ctx, eventtask.NewWebPushBroadcast(message)); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue web push message:%#v", message)
+
+	return nil
+}
+
+// webPushFeedMessage formats the feed item to web push message
+// the first image is shown in the notification if any
+func webPushFeedMessage(item crawler.FeedItem) webpush.Message {
+	m := webpush.Message{
+		Title: item.Title,
+		Body:  item.EntryTitle,
+		URL:   item.EntryURL,
+	}
+	if len(item.ImageURLs) > 0 {
+		m.ImageURL = item.ImageURLs[0]
+	}
+	return m
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/webhook"
+)
+
+type (
+	// EnqueueWebhooks use case
+	EnqueueWebhooks struct {
+		log            log.Logger
+		taskQueue      event.TaskQueue
+		transactor     dao.Transactor
+		itemRepo       entity.ChannelItemRepository
+		subscriberRepo entity.WebhookSubscriberRepository
+		deliveryRepo   entity.WebhookDeliveryRepository
+		preview        entity.PreviewRepository
+	}
+)
+
+// NewEnqueueWebhooks returns EnqueueWebhooks use case
+func NewEnqueueWebhooks(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	transactor dao.Transactor,
+	itemRepo entity.ChannelItemRepository,
+	subscriberRepo entity.WebhookSubscriberRepository,
+	deliveryRepo entity.WebhookDeliveryRepository,
+	preview entity.PreviewRepository) *EnqueueWebhooks {
+	return &EnqueueWebhooks{
+		log:            log,
+		taskQueue:      taskQueue,
+		transactor:     transactor,
+		itemRepo:       itemRepo,
+		subscriberRepo: subscriberRepo,
+		deliveryRepo:   deliveryRepo,
+		preview:        preview,
+	}
+}
+
+// Notify implements notifier.Notifier
+// it records a pending delivery of the event and enqueues it for each enabled subscriber
+func (use *EnqueueWebhooks) Notify(ctx context.Context, n notifier.Notification) error {
+	const errTag = "EnqueueWebhooks.Notify failed"
+
+	payload := webhook.Payload{Event: string(n.Kind), CreatedAt: n.CreatedAt, Text: n.Text, URL: n.URL}
+	if n.Kind == notifier.KindFeed {
+		recorded, err := recordFeedNotification(ctx, use.transactor, use.itemRepo, notifier.ChannelWebhook, n)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		if !recorded {
+			return nil // already enqueued
+		}
+		item := webhookFeedItem(*n.FeedItem)
+		payload.Item = &item
+	}
+	if payload.CreatedAt.IsZero() {
+		payload.CreatedAt = timeutil.Now()
+	}
+
+	subscribers, err := use.subscriberRepo.FindEnabled(ctx)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	if ok, err := savePreview(ctx, use.log, use.preview, notifier.ChannelWebhook, len(subscribers), payload); ok || err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	deliveries := make([]*entity.WebhookDelivery, len(subscribers))
+	tasks := make([]event.Task, len(subscribers))
+	for i, s := range subscribers {
+		id, err := entity.NewWebhookDeliveryID()
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		payload.ID = id
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		deliveries[i] = entity.NewWebhookDelivery(id, s.ID, payload.Event, string(b))
+		tasks[i] = eventtask.NewWebhookDelivery(id)
+	}
+	if err := use.deliveryRepo.SaveMulti(ctx, deliveries); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue webhook tasks len:%v", len(tasks))
+
+	return nil
+}
+
+// webhookFeedItem formats the feed item to the item of the webhook payload
+func webhookFeedItem(item crawler.FeedItem) webhook.Item {
+	return webhook.Item{
+		Code:        item.FeedCode().String(),
+		Title:       item.Title,
+		URL:         item.URL,
+		EntryTitle:  item.EntryTitle,
+		EntryURL:    item.EntryURL,
+		ImageURLs:   item.ImageURLs,
+		VideoURLs:   item.VideoURLs,
+		PublishedAt: item.PublishedAt,
+	}
+}
@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"github.com/utahta/momoclo-channel/webhook"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestEnqueueWebhooks_Notify(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	h := dao.NewDatastoreHandler()
+	subscriberRepo := entity.NewWebhookSubscriberRepository(h)
+	for _, v := range []string{"http://localhost/a", "http://localhost/b"} {
+		s, err := entity.NewWebhookSubscriber(config.C().Webhook.TokenKey, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Disabled = v == "http://localhost/b"
+		if err := subscriberRepo.Save(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	deliveryRepo := entity.NewWebhookDeliveryRepository(h)
+	u := usecase.NewEnqueueWebhooks(
+		log.NewAELogger(),
+		taskQueue,
+		dao.NewDatastoreTransactor(),
+		entity.NewChannelItemRepository(h),
+		subscriberRepo,
+		deliveryRepo,
+		entity.NewPreviewRepository(h),
+	)
+
+	n := notifier.NewFeedNotification(crawler.FeedItem{
+		Title:       "title",
+		URL:         "http://localhost",
+		EntryTitle:  "entry title",
+		EntryURL:    "http://localhost/entry",
+		PublishedAt: time.Now(),
+	})
+	for i := 0; i < 2; i++ {
+		if err := u.Notify(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := u.Notify(ctx, notifier.NewMessageNotification(notifier.KindReminder, "reminder", "", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	// the enabled subscriber receives the entry once and the reminder
+	if len(taskQueue.Tasks) != 2 {
+		t.Fatalf("Expected taskqueue length 2, got %v", len(taskQueue.Tasks))
+	}
+	d, err := deliveryRepo.Find(ctx, taskQueue.Tasks[0].Object.(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Status != entity.WebhookDeliveryPending || d.Event != "feed" {
+		t.Errorf("Expected pending feed delivery, got %v", d)
+	}
+
+	var payload webhook.Payload
+	if err := json.Unmarshal([]byte(d.Payload), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.ID != d.ID || payload.Item == nil || payload.Item.EntryURL != "http://localhost/entry" {
+		t.Errorf("Expected payload of the entry, got %v", payload)
+	}
+
+	deliveries, err := deliveryRepo.FindRecent(ctx, d.SubscriberID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 || deliveries[0].Event != "reminder" {
+		t.Errorf("Expected recent deliveries, got %v", deliveries)
+	}
+}
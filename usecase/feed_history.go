@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+)
+
+type (
+	// feedHistoryItem is the history of the enqueued feed item that has the fingerprint of its contents
+	feedHistoryItem interface {
+		SetID(string)
+		GetID() string
+		GetTitle() string
+		GetFingerprint() string
+		SplitImageURLs() []string
+		SplitVideoURLs() []string
+		SetCreatedAt(time.Time)
+		GetCreatedAt() time.Time
+	}
+
+	// feedHistoryRepository operates the history items of a channel
+	feedHistoryRepository interface {
+		find(context.Context, string) (feedHistoryItem, error)
+		findMulti(context.Context, []string) ([]feedHistoryItem, error)
+		save(context.Context, feedHistoryItem) error
+		delete(context.Context, string) error
+	}
+
+	// feedHistory finds the already enqueued feed items and detects their edits
+	feedHistory struct {
+		log        log.Logger
+		transactor dao.Transactor
+		repo       feedHistoryRepository
+		name       string
+	}
+
+	lineHistoryRepository struct {
+		repo entity.LineItemRepository
+	}
+
+	tweetHistoryRepository struct {
+		repo entity.TweetItemRepository
+	}
+)
+
+// newLineHistory returns feedHistory of line items
+func newLineHistory(log log.Logger, transactor dao.Transactor, repo entity.LineItemRepository) *feedHistory {
+	return &feedHistory{log: log, transactor: transactor, repo: lineHistoryRepository{repo}, name: "line"}
+}
+
+// newTweetHistory returns feedHistory of tweet items
+func newTweetHistory(log log.Logger, transactor dao.Transactor, repo entity.TweetItemRepository) *feedHistory {
+	return &feedHistory{log: log, transactor: transactor, repo: tweetHistoryRepository{repo}, name: "tweet"}
+}
+
+// findPrev finds the already enqueued item
+// the item that is stored with any of legacy ids is migrated to given id, the legacy ids are looked up at once
+func (h *feedHistory) findPrev(ctx context.Context, id string, legacyIDs []string) (feedHistoryItem, error) {
+	prev, err := h.repo.find(ctx, id)
+	if err != dao.ErrNoSuchEntity {
+		return prev, err
+	}
+	if len(legacyIDs) == 0 {
+		return nil, dao.ErrNoSuchEntity
+	}
+
+	items, err := h.repo.findMulti(ctx, legacyIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, dao.ErrNoSuchEntity
+	}
+
+	prev, err = h.migrate(ctx, id, items[0].GetID())
+	if err == dao.ErrNoSuchEntity {
+		return h.repo.find(ctx, id) // migrated by another request
+	}
+	return prev, err
+}
+
+// migrate moves the item stored with legacy id to given id
+// the legacy item is deleted in the same transaction, so that the history has no duplicates
+func (h *feedHistory) migrate(ctx context.Context, id, legacyID string) (feedHistoryItem, error) {
+	var prev feedHistoryItem
+	err := h.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		v, err := h.repo.find(ctx, legacyID)
+		if err != nil {
+			return err
+		}
+		prev = v
+		if dryrun.Enabled(ctx) {
+			return nil
+		}
+
+		if err := h.repo.delete(ctx, legacyID); err != nil {
+			return err
+		}
+		prev.SetID(id)
+		return h.repo.save(ctx, prev)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.log.Infof(ctx, "migrate %v item id:%v to id:%v", h.name, legacyID, id)
+
+	return prev, nil
+}
+
+// edit records the new contents of already enqueued item
+// it returns the edited parts of given feed item to announce, and false if there is nothing to announce
+func (h *feedHistory) edit(ctx context.Context, prev, item feedHistoryItem, feedItem crawler.FeedItem) (crawler.EditedFeedItem, bool, error) {
+	if prev.GetFingerprint() == item.GetFingerprint() {
+		return crawler.EditedFeedItem{}, false, nil // already enqueued
+	}
+
+	var updated bool
+	err := h.transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		updated = false
+
+		v, err := h.repo.find(ctx, item.GetID())
+		if err != nil {
+			return err
+		}
+		if v.GetFingerprint() != prev.GetFingerprint() {
+			return nil // already updated
+		}
+		item.SetCreatedAt(v.GetCreatedAt())
+		if !dryrun.Enabled(ctx) {
+			if err := h.repo.save(ctx, item); err != nil {
+				return err
+			}
+		}
+		updated = true
+		return nil
+	}, nil)
+	if err != nil {
+		return crawler.EditedFeedItem{}, false, err
+	}
+	if !updated || prev.GetFingerprint() == "" {
+		return crawler.EditedFeedItem{}, false, nil // the fingerprint of legacy entity is just recorded
+	}
+
+	prevItem := crawler.FeedItem{
+		EntryTitle: prev.GetTitle(),
+		ImageURLs:  prev.SplitImageURLs(),
+		VideoURLs:  prev.SplitVideoURLs(),
+	}
+	edited, ok := crawler.DiffFeedItem(prevItem, feedItem)
+	return edited, ok, nil
+}
+
+func (r lineHistoryRepository) find(ctx context.Context, id string) (feedHistoryItem, error) {
+	v, err := r.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (r lineHistoryRepository) findMulti(ctx context.Context, ids []string) ([]feedHistoryItem, error) {
+	items, err := r.repo.FindMulti(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]feedHistoryItem, len(items))
+	for i, item := range items {
+		dst[i] = item
+	}
+	return dst, nil
+}
+
+func (r lineHistoryRepository) save(ctx context.Context, item feedHistoryItem) error {
+	return r.repo.Save(ctx, item.(*entity.LineItem))
+}
+
+func (r lineHistoryRepository) delete(ctx context.Context, id string) error {
+	return r.repo.Delete(ctx, id)
+}
+
+func (r tweetHistoryRepository) find(ctx context.Context, id string) (feedHistoryItem, error) {
+	v, err := r.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (r tweetHistoryRepository) findMulti(ctx context.Context, ids []string) ([]feedHistoryItem, error) {
+	items, err := r.repo.FindMulti(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]feedHistoryItem, len(items))
+	for i, item := range items {
+		dst[i] = item
+	}
+	return dst, nil
+}
+
+func (r tweetHistoryRepository) save(ctx context.Context, item feedHistoryItem) error {
+	return r.repo.Save(ctx, item.(*entity.TweetItem))
+}
+
+func (r tweetHistoryRepository) delete(ctx context.Context, id string) error {
+	return r.repo.Delete(ctx, id)
+}
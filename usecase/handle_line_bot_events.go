@@ -2,9 +2,14 @@ package usecase
 
 import (
 	"context"
+	"regexp"
 
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
 	"github.com/utahta/momoclo-channel/customsearch"
+	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/linebot"
+	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
 )
 
@@ -14,6 +19,7 @@ type (
 		log           log.Logger
 		lineBot       linebot.Client
 		imageSearcher customsearch.ImageSearcher
+		repo          entity.LineNotificationRepository
 	}
 
 	// HandleLineBotEventsParams use case params
@@ -26,11 +32,13 @@ type (
 func NewHandleLineBotEvents(
 	logger log.Logger,
 	lineBot linebot.Client,
-	imageSearcher customsearch.ImageSearcher) *HandleLineBotEvents {
+	imageSearcher customsearch.ImageSearcher,
+	repo entity.LineNotificationRepository) *HandleLineBotEvents {
 	return &HandleLineBotEvents{
 		log:           logger,
 		lineBot:       lineBot,
 		imageSearcher: imageSearcher,
+		repo:          repo,
 	}
 }
 
@@ -46,11 +54,16 @@ func (use *HandleLineBotEvents) Do(ctx context.Context, params HandleLineBotEven
 			switch event.MessageType {
 			case linebot.MessageTypeText:
 				if linebot.MatchOn(event.TextMessage.Text) {
-					use.lineBot.ReplyText(ctx, event.ReplyToken, linebot.OnMessage())
+					use.lineBot.ReplyText(ctx, event.ReplyToken, linebot.OnMessage(lineOnURL(event.UserID)))
 					continue
 				} else if linebot.MatchOff(event.TextMessage.Text) {
 					use.lineBot.ReplyText(ctx, event.ReplyToken, linebot.OffMessage())
 					continue
+				} else if name, arg, ok := linebot.MatchCommand(event.TextMessage.Text); ok {
+					if err := use.doCommand(ctx, event, name, arg); err != nil {
+						use.log.Errorf(ctx, "%v: command:%v err:%v", errTag, name, err)
+					}
+					continue
 				}
 
 				memberName := linebot.FindMemberName(event.TextMessage.Text)
@@ -72,7 +85,7 @@ func (use *HandleLineBotEvents) Do(ctx context.Context, params HandleLineBotEven
 			}
 		case linebot.EventTypeFollow:
 			use.log.Info(ctx, "follow event")
-			use.lineBot.ReplyText(ctx, event.ReplyToken, linebot.FollowMessage())
+			use.lineBot.ReplyText(ctx, event.ReplyToken, linebot.FollowMessage(lineOnURL(event.UserID)))
 		case linebot.EventTypeUnfollow:
 			use.log.Info(ctx, "unfollow event")
 		default:
@@ -81,3 +94,89 @@ func (use *HandleLineBotEvents) Do(ctx context.Context, params HandleLineBotEven
 	}
 	return nil
 }
+
+// reSplitCodes matches separators of feed codes in the command (e.g. "フィード momota-sd、aenews")
+var reSplitCodes = regexp.MustCompile(`[\s　,、]+`)
+
+// doCommand updates the preferences of LINE Notify tokens that the user connected, and replies them
+func (use *HandleLineBotEvents) doCommand(ctx context.Context, event linebot.Event, name, arg string) error {
+	const errTag = "HandleLineBotEvents.doCommand failed"
+
+	var ns []*entity.LineNotification
+	if event.UserID != "" {
+		var err error
+		ns, err = use.repo.FindByUserHash(ctx, entity.LineUserHash(event.UserID))
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+	}
+	if len(ns) == 0 {
+		return use.lineBot.ReplyText(ctx, event.ReplyToken, linebot.NotConnectedMessage(lineOnURL(event.UserID)))
+	}
+
+	var (
+		update  func(*LineNotifyPreferences)
+		invalid = linebot.InvalidPreferencesMessage(lineFeedCodes())
+	)
+	switch {
+	case name == linebot.CommandSettings || arg == "":
+		// just replies the preferences
+	case name == linebot.CommandMembers:
+		var members []string
+		if !linebot.MatchAll(arg) {
+			if members = linebot.FindMemberNames(arg); len(members) == 0 {
+				return use.lineBot.ReplyText(ctx, event.ReplyToken, invalid)
+			}
+		}
+		update = func(p *LineNotifyPreferences) { p.Members = members }
+	case name == linebot.CommandFeeds:
+		var codes []string
+		if !linebot.MatchAll(arg) {
+			codes = reSplitCodes.Split(arg, -1)
+		}
+		update = func(p *LineNotifyPreferences) { p.Codes = codes }
+	case name == linebot.CommandUstream:
+		update = func(p *LineNotifyPreferences) { p.Ustream = linebot.MatchOn(arg) }
+	case name == linebot.CommandReminder:
+		update = func(p *LineNotifyPreferences) { p.Reminder = linebot.MatchOn(arg) }
+	}
+
+	if update != nil {
+		// validates the changed preference only
+		var v LineNotifyPreferences
+		update(&v)
+		if err := v.validate(); err != nil {
+			use.log.Infof(ctx, "invalid preferences command arg:%v err:%v", arg, err)
+			return use.lineBot.ReplyText(ctx, event.ReplyToken, invalid)
+		}
+	}
+
+	var text string
+	for _, l := range ns {
+		p := newLineNotifyPreferences(l)
+		if update != nil {
+			update(&p)
+			p.apply(l)
+			if err := use.repo.Save(ctx, l); err != nil {
+				return errors.Wrap(err, errTag)
+			}
+			use.log.Infof(ctx, "update line notify preferences id:%v preferences:%v", l.ID, p)
+		}
+
+		if text != "" {
+			text += "\n\n"
+		}
+		preferencesURL := linenotify.PreferencesURL(l.ID, l.PreferencesToken(config.C().LineNotify.SigningKey))
+		text += linebot.PreferencesMessage(p.Codes, p.Members, p.Ustream, p.Reminder, preferencesURL)
+	}
+	return use.lineBot.ReplyText(ctx, event.ReplyToken, text)
+}
+
+// lineOnURL returns the url to connect LINE Notify that is signed for given LINE bot user
+func lineOnURL(userID string) string {
+	if userID == "" {
+		return linenotify.OnURL("", "")
+	}
+	userHash := entity.LineUserHash(userID)
+	return linenotify.OnURL(userHash, entity.LineUserToken(config.C().LineNotify.SigningKey, userHash))
+}
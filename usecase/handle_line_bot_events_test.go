@@ -0,0 +1,114 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/customsearch"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/linebot"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+// lineBotClient is a linebot.Client that records the replied texts
+type lineBotClient struct {
+	texts []string
+}
+
+func (c *lineBotClient) ReplyText(_ context.Context, _, text string) error {
+	c.texts = append(c.texts, text)
+	return nil
+}
+
+func (c *lineBotClient) ReplyImage(context.Context, string, string, string) error {
+	return nil
+}
+
+func TestHandleLineBotEvents_DoCommand(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	repo := entity.NewLineNotificationRepository(dao.NewDatastoreHandler())
+	l, err := entity.NewLineNotification(config.C().LineNotify.TokenKey, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.UserHash = entity.LineUserHash("U1")
+	if err := repo.Save(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &lineBotClient{}
+	searcher := imageSearcherFunc(func(string) (customsearch.ImageSearchResult, error) {
+		return customsearch.ImageSearchResult{}, nil
+	})
+	u := usecase.NewHandleLineBotEvents(log.NewAELogger(), client, searcher, repo)
+
+	command := func(userID, text string) string {
+		client.texts = nil
+		event := linebot.Event{
+			UserID:      userID,
+			Type:        linebot.EventTypeMessage,
+			MessageType: linebot.MessageTypeText,
+			TextMessage: linebot.TextMessage{Text: text},
+		}
+		if err := u.Do(ctx, usecase.HandleLineBotEventsParams{Events: []linebot.Event{event}}); err != nil {
+			t.Fatal(err)
+		}
+		if len(client.texts) != 1 {
+			t.Fatalf("Expected a reply, got %v", client.texts)
+		}
+		return client.texts[0]
+	}
+
+	// the user who has not connected through the bot
+	if text := command("U2", "設定"); !strings.Contains(text, "/line/notify/on?") {
+		t.Errorf("Expected signed connection url, got %v", text)
+	}
+
+	tests := []struct {
+		text     string
+		codes    string
+		members  string
+		ustream  bool
+		reminder bool
+	}{
+		{"推し れにちゃん", "", "高城れに", false, false},
+		{"推し　かなこ、しおりん", "", "百田夏菜子,玉井詩織", false, false},
+		{"フィード takagi-sd aenews", "takagi-sd,aenews", "百田夏菜子,玉井詩織", false, false},
+		{"フィード unknown", "takagi-sd,aenews", "百田夏菜子,玉井詩織", false, false},
+		{"ライブ おふ", "takagi-sd,aenews", "百田夏菜子,玉井詩織", true, false},
+		{"リマインダー off", "takagi-sd,aenews", "百田夏菜子,玉井詩織", true, true},
+		{"推し 全員", "takagi-sd,aenews", "", true, true},
+		{"フィード 全部", "", "", true, true},
+		{"ライブ おん", "", "", false, true},
+	}
+	for _, test := range tests {
+		command("U1", test.text)
+
+		v, err := repo.Find(ctx, l.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Codes != test.codes || v.Members != test.members || v.MuteUstream != test.ustream || v.MuteReminder != test.reminder {
+			t.Errorf("Expected preferences %v %v %v %v, got %v %v %v %v. text:%v",
+				test.codes, test.members, test.ustream, test.reminder,
+				v.Codes, v.Members, v.MuteUstream, v.MuteReminder, test.text)
+		}
+	}
+
+	// the settings command replies the link of preferences page
+	if text := command("U1", "設定"); !strings.Contains(text, "/line/notify/preferences?") {
+		t.Errorf("Expected preferences url, got %v", text)
+	}
+}
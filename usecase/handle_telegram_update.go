@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/customsearch"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/linebot"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/telegram"
+)
+
+type (
+	// HandleTelegramUpdate use case
+	HandleTelegramUpdate struct {
+		log           log.Logger
+		client        telegram.Client
+		imageSearcher customsearch.ImageSearcher
+		repo          entity.TelegramSubscriberRepository
+	}
+
+	// HandleTelegramUpdateParams input parameters
+	HandleTelegramUpdateParams struct {
+		Update telegram.Update
+	}
+)
+
+// NewHandleTelegramUpdate returns HandleTelegramUpdate use case
+func NewHandleTelegramUpdate(
+	log log.Logger,
+	client telegram.Client,
+	imageSearcher customsearch.ImageSearcher,
+	repo entity.TelegramSubscriberRepository) *HandleTelegramUpdate {
+	return &HandleTelegramUpdate{
+		log:           log,
+		client:        client,
+		imageSearcher: imageSearcher,
+		repo:          repo,
+	}
+}
+
+// Do handles given telegram bot update
+// /start and /stop subscribe and unsubscribe the chat, and the name of a member replies the image
+func (use *HandleTelegramUpdate) Do(ctx context.Context, params HandleTelegramUpdateParams) error {
+	const errTag = "HandleTelegramUpdate.Do failed"
+
+	m := params.Update.Message
+	if m == nil || m.Text == "" {
+		use.log.Infof(ctx, "not handle update id:%v", params.Update.UpdateID)
+		return nil
+	}
+	chatID := m.Chat.ID
+
+	switch telegram.Command(m.Text) {
+	case telegram.CommandStart:
+		if err := use.repo.Save(ctx, entity.NewTelegramSubscriber(chatID)); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "subscribe telegram chat:%v", chatID)
+		return use.reply(ctx, chatID, telegram.StartMessage())
+	case telegram.CommandStop:
+		if err := use.repo.Delete(ctx, entity.TelegramSubscriberID(chatID)); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "unsubscribe telegram chat:%v", chatID)
+		return use.reply(ctx, chatID, telegram.StopMessage())
+	case "":
+	default:
+		return use.reply(ctx, chatID, telegram.HelpMessage())
+	}
+
+	memberName := linebot.FindMemberName(m.Text)
+	if memberName == "" {
+		return use.reply(ctx, chatID, telegram.HelpMessage())
+	}
+
+	img, err := use.imageSearcher.Search(ctx, memberName)
+	if err != nil {
+		use.log.Warningf(ctx, "%v: image not found word:%v err:%v", errTag, memberName, err)
+		return use.reply(ctx, chatID, telegram.ImageNotFoundMessage())
+	}
+	if err := use.client.SendPhoto(ctx, chatID, img.URL, ""); err != nil {
+		use.log.Warningf(ctx, "%v: reply image chat:%v err:%v", errTag, chatID, err)
+	}
+	return nil
+}
+
+// reply sends the text to the chat
+// the error is just logged so that Telegram does not redeliver the update
+func (use *HandleTelegramUpdate) reply(ctx context.Context, chatID int64, text string) error {
+	if err := use.client.SendText(ctx, chatID, text); err != nil {
+		use.log.Warningf(ctx, "HandleTelegramUpdate.reply failed chat:%v err:%v", chatID, err)
+	}
+	return nil
+}
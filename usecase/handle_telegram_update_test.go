@@ -0,0 +1,122 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/utahta/momoclo-channel/customsearch"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/telegram"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+// telegramClient is a telegram.Client that records the sent messages
+type telegramClient struct {
+	texts  []string
+	photos []string
+	err    error
+}
+
+func (c *telegramClient) SendText(_ context.Context, _ int64, text string) error {
+	c.texts = append(c.texts, text)
+	return c.err
+}
+
+func (c *telegramClient) SendPhoto(_ context.Context, _ int64, photoURL, _ string) error {
+	c.photos = append(c.photos, photoURL)
+	return c.err
+}
+
+func (c *telegramClient) Send(_ context.Context, _ int64, msg telegram.Message, _ int) error {
+	c.texts = append(c.texts, msg.Text)
+	return c.err
+}
+
+// imageSearcherFunc is a customsearch.ImageSearcher that returns the result of the function
+type imageSearcherFunc func(string) (customsearch.ImageSearchResult, error)
+
+func (f imageSearcherFunc) Search(_ context.Context, word string) (customsearch.ImageSearchResult, error) {
+	return f(word)
+}
+
+func TestHandleTelegramUpdate_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	repo := entity.NewTelegramSubscriberRepository(dao.NewDatastoreHandler())
+	client := &telegramClient{}
+	searcher := imageSearcherFunc(func(word string) (customsearch.ImageSearchResult, error) {
+		if word == "百田夏菜子" {
+			return customsearch.ImageSearchResult{URL: "https://localhost/momota.jpg"}, nil
+		}
+		return customsearch.ImageSearchResult{}, errors.New("not found")
+	})
+	u := usecase.NewHandleTelegramUpdate(log.NewAELogger(), client, searcher, repo)
+
+	update := func(chatID int64, text string) {
+		params := usecase.HandleTelegramUpdateParams{Update: telegram.Update{
+			Message: &telegram.UpdateMessage{Chat: telegram.Chat{ID: chatID}, Text: text},
+		}}
+		if err := u.Do(ctx, params); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count := func() int {
+		subscribers, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(subscribers)
+	}
+
+	// subscribe
+	update(1, "/start")
+	update(-100, "/start@momoclo_bot")
+	if n := count(); n != 2 {
+		t.Errorf("Expected subscribers length 2, got %v", n)
+	}
+
+	// unsubscribe
+	update(1, "/stop")
+	if n := count(); n != 1 {
+		t.Errorf("Expected subscribers length 1, got %v", n)
+	}
+	if len(client.texts) != 3 || client.texts[0] != telegram.StartMessage() || client.texts[2] != telegram.StopMessage() {
+		t.Errorf("Expected start and stop messages, got %v", client.texts)
+	}
+
+	// member name replies the image
+	client.texts = nil
+	update(1, "かなこ")
+	if len(client.photos) != 1 || client.photos[0] != "https://localhost/momota.jpg" {
+		t.Errorf("Expected photo of the member, got %v", client.photos)
+	}
+
+	// unknown text and command reply the help
+	update(1, "hello")
+	update(1, "/unknown")
+	if len(client.texts) != 2 || client.texts[0] != telegram.HelpMessage() || client.texts[1] != telegram.HelpMessage() {
+		t.Errorf("Expected help messages, got %v", client.texts)
+	}
+
+	// the image is not found
+	client.texts = nil
+	update(1, "しおり")
+	if len(client.texts) != 1 || client.texts[0] != telegram.ImageNotFoundMessage() {
+		t.Errorf("Expected image not found message, got %v", client.texts)
+	}
+
+	// update without message is ignored
+	if err := u.Do(ctx, usecase.HandleTelegramUpdateParams{Update: telegram.Update{UpdateID: 1}}); err != nil {
+		t.Fatal(err)
+	}
+}
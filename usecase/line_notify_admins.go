@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/linenotify"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// LineNotifyAdmins use case
+	LineNotifyAdmins struct {
+		log       log.Logger
+		taskQueue event.TaskQueue
+		repo      entity.LineNotificationRepository
+	}
+
+	// LineNotifyAdminsParams input parameters
+	LineNotifyAdminsParams struct {
+		Messages []linenotify.Message `validate:"min=1,dive"`
+	}
+)
+
+// NewLineNotifyAdmins returns LineNotifyAdmins use case
+func NewLineNotifyAdmins(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	repo entity.LineNotificationRepository) *LineNotifyAdmins {
+	return &LineNotifyAdmins{
+		log:       log,
+		taskQueue: taskQueue,
+		repo:      repo,
+	}
+}
+
+// Do notify admins
+func (use *LineNotifyAdmins) Do(ctx context.Context, params LineNotifyAdminsParams) error {
+	const errTag = "LineNotifyAdmins.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	ns, err := use.repo.FindAdmins(ctx)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if len(ns) == 0 {
+		use.log.Warningf(ctx, "admins not found. messages:%v", params.Messages)
+		return nil
+	}
+
+	tasks := make([]event.Task, 0, len(ns))
+	for _, n := range ns {
+		accessToken, err := n.Token(config.C().LineNotify.TokenKey)
+		if err != nil {
+			use.log.Errorf(ctx, "%v: get access token err:%v", errTag, err)
+			continue
+		}
+		tasks = append(tasks, eventtask.NewLine(linenotify.Request{
+			ID:          n.ID,
+			AccessToken: accessToken,
+			Messages:    params.Messages,
+		}))
+	}
+
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "notify admins line tasks len:%v", len(tasks))
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package usecase_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/linenotify"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestLineNotifyAdmins_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	taskQueue := eventtest.NewTaskQueue()
+	repo := entity.NewLineNotificationRepository(dao.NewDatastoreHandler())
+	u := usecase.NewLineNotifyAdmins(log.NewAELogger(), taskQueue, repo)
+
+	params := usecase.LineNotifyAdminsParams{Messages: []linenotify.Message{{Text: "alert"}}}
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 0 {
+		t.Errorf("Expected taskqueue length 0 without admins, got %v", len(taskQueue.Tasks))
+	}
+
+	for i := 0; i < 5; i++ {
+		l, err := entity.NewLineNotification(config.C().LineNotify.TokenKey, fmt.Sprintf("token-%v", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Admin = i < 2
+		repo.Save(ctx, l)
+	}
+
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 2 {
+		t.Errorf("Expected taskqueue length 2, got %v", len(taskQueue.Tasks))
+	}
+}
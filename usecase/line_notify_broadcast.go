@@ -5,11 +5,13 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dryrun"
 	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/event"
 	"github.com/utahta/momoclo-channel/event/eventtask"
 	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
 	"github.com/utahta/momoclo-channel/validator"
 )
 
@@ -19,11 +21,18 @@ type (
 		log       log.Logger
 		taskQueue event.TaskQueue
 		repo      entity.LineNotificationRepository
+		preview   entity.PreviewRepository
 	}
 
 	// LineNotifyBroadcastParams input parameters
 	LineNotifyBroadcastParams struct {
 		Messages []linenotify.Message `validate:"min=1,dive"`
+
+		// attributes of the messages to filter recipients by their preferences
+		// all recipients receive the messages if Kind is empty
+		Kind    notifier.Kind
+		Code    string
+		Members []string
 	}
 )
 
@@ -31,11 +40,13 @@ type (
 func NewLineNotifyBroadcast(
 	log log.Logger,
 	taskQueue event.TaskQueue,
-	repo entity.LineNotificationRepository) *LineNotifyBroadcast {
+	repo entity.LineNotificationRepository,
+	preview entity.PreviewRepository) *LineNotifyBroadcast {
 	return &LineNotifyBroadcast{
 		log:       log,
 		taskQueue: taskQueue,
 		repo:      repo,
+		preview:   preview,
 	}
 }
 
@@ -48,10 +59,29 @@ func (use *LineNotifyBroadcast) Do(ctx context.Context, params LineNotifyBroadca
 	}
 
 	//TODO use iterator
-	ns, err := use.repo.FindAll(ctx)
+	all, err := use.repo.FindAll(ctx)
 	if err != nil {
 		return errors.Wrap(err, errTag)
 	}
+	ns := all[:0]
+	for _, n := range all {
+		if lineAccepts(n, params) {
+			ns = append(ns, n)
+		}
+	}
+
+	if dryrun.Enabled(ctx) {
+		// record the messages and the number of recipients instead of notifying
+		preview := struct {
+			Recipients int
+			Messages   []linenotify.Message
+		}{len(ns), params.Messages}
+		if err := use.preview.SaveMessages(ctx, entity.PreviewChannelLine, preview); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "dry-run: preview line messages recipients:%v", len(ns))
+		return nil
+	}
 
 	tasks := make([]event.Task, 0, len(ns))
 	for _, n := range ns {
@@ -74,3 +104,16 @@ func (use *LineNotifyBroadcast) Do(ctx context.Context, params LineNotifyBroadca
 
 	return nil
 }
+
+// lineAccepts returns true if the preferences of the subscriber accept the messages
+func lineAccepts(n *entity.LineNotification, params LineNotifyBroadcastParams) bool {
+	switch params.Kind {
+	case notifier.KindFeed:
+		return n.AcceptsEntry(params.Code, params.Members)
+	case notifier.KindUstream:
+		return !n.MuteUstream
+	case notifier.KindReminder:
+		return !n.MuteReminder
+	}
+	return true
+}
@@ -1,6 +1,8 @@
 package usecase_test
 
 import (
+	"sort"
+	"strings"
 	"testing"
 
 	"fmt"
@@ -9,10 +11,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/config"
 	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
 	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/event/eventtest"
 	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
 	"github.com/utahta/momoclo-channel/testutil"
 	"github.com/utahta/momoclo-channel/usecase"
 	"google.golang.org/appengine/aetest"
@@ -27,7 +31,7 @@ func TestLineNotifyBroadcast_Do(t *testing.T) {
 
 	taskQueue := eventtest.NewTaskQueue()
 	repo := entity.NewLineNotificationRepository(dao.NewDatastoreHandler())
-	u := usecase.NewLineNotifyBroadcast(log.NewAELogger(), taskQueue, repo)
+	u := usecase.NewLineNotifyBroadcast(log.NewAELogger(), taskQueue, repo, entity.NewPreviewRepository(dao.NewDatastoreHandler()))
 
 	validationTests := []struct {
 		params usecase.LineNotifyBroadcastParams
@@ -70,3 +74,109 @@ func TestLineNotifyBroadcast_Do(t *testing.T) {
 		t.Errorf("Expected taskqueue length 10, got %v", len(taskQueue.Tasks))
 	}
 }
+
+func TestLineNotifyBroadcast_DoDryRun(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	ctx = dryrun.WithContext(ctx)
+	taskQueue := eventtest.NewTaskQueue()
+	repo := entity.NewLineNotificationRepository(dao.NewDatastoreHandler())
+	preview := entity.NewPreviewRepository(dao.NewDatastoreHandler())
+	u := usecase.NewLineNotifyBroadcast(log.NewAELogger(), taskQueue, repo, preview)
+
+	for i := 0; i < 3; i++ {
+		l, err := entity.NewLineNotification(config.C().LineNotify.TokenKey, fmt.Sprintf("token-%v", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		repo.Save(ctx, l)
+	}
+
+	err = u.Do(ctx, usecase.LineNotifyBroadcastParams{Messages: []linenotify.Message{{Text: "hello"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(taskQueue.Tasks) != 0 {
+		t.Errorf("Expected taskqueue length 0, got %v", len(taskQueue.Tasks))
+	}
+	previews, err := preview.FindRecent(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previews) != 1 {
+		t.Fatalf("Expected previews length 1, got %v", len(previews))
+	}
+	if expected := `{"Recipients":3,`; !strings.HasPrefix(previews[0].Payload, expected) {
+		t.Errorf("Expected payload prefix %v, got %v", expected, previews[0].Payload)
+	}
+}
+
+func TestLineNotifyBroadcast_DoPreferences(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	taskQueue := eventtest.NewTaskQueue()
+	repo := entity.NewLineNotificationRepository(dao.NewDatastoreHandler())
+	u := usecase.NewLineNotifyBroadcast(log.NewAELogger(), taskQueue, repo, entity.NewPreviewRepository(dao.NewDatastoreHandler()))
+
+	preferences := map[string]func(*entity.LineNotification){
+		"all": func(l *entity.LineNotification) {},
+		"takagi": func(l *entity.LineNotification) {
+			l.SetMembers([]string{"高城れに"})
+			l.MuteUstream = true
+		},
+		"aenews": func(l *entity.LineNotification) {
+			l.SetCodes([]string{"aenews"})
+			l.MuteReminder = true
+		},
+	}
+	for token, f := range preferences {
+		l, err := entity.NewLineNotification(config.C().LineNotify.TokenKey, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f(l)
+		if err := repo.Save(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		params   usecase.LineNotifyBroadcastParams
+		expected []string
+	}{
+		{usecase.LineNotifyBroadcastParams{}, []string{"aenews", "all", "takagi"}},
+		{usecase.LineNotifyBroadcastParams{Kind: notifier.KindFeed, Code: "takagi-sd", Members: []string{"高城れに"}}, []string{"all", "takagi"}},
+		{usecase.LineNotifyBroadcastParams{Kind: notifier.KindFeed, Code: "aenews"}, []string{"aenews", "all"}},
+		{usecase.LineNotifyBroadcastParams{Kind: notifier.KindFeed, Code: "aenews", Members: []string{"百田夏菜子", "高城れに"}}, []string{"aenews", "all", "takagi"}},
+		{usecase.LineNotifyBroadcastParams{Kind: notifier.KindUstream}, []string{"aenews", "all"}},
+		{usecase.LineNotifyBroadcastParams{Kind: notifier.KindReminder}, []string{"all", "takagi"}},
+	}
+
+	for _, test := range tests {
+		taskQueue.Tasks = nil
+		test.params.Messages = []linenotify.Message{{Text: "hello"}}
+		if err := u.Do(ctx, test.params); err != nil {
+			t.Fatal(err)
+		}
+
+		var tokens []string
+		for _, task := range taskQueue.Tasks {
+			tokens = append(tokens, task.Object.(linenotify.Request).AccessToken)
+		}
+		sort.Strings(tokens)
+		if strings.Join(tokens, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("Expected recipients %v, got %v. params:%v", test.expected, tokens, test.params)
+		}
+	}
+}
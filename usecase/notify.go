@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// Notify use case
+	Notify struct {
+		log       log.Logger
+		taskQueue event.TaskQueue
+		registry  *notifier.Registry
+	}
+
+	// NotifyParams input parameters
+	NotifyParams struct {
+		// Notifications are delivered in order, each is delayed a second after the previous one
+		Notifications []notifier.Notification `validate:"min=1,dive"`
+		// Channels are the channels to deliver, all enabled channels if empty
+		Channels []string
+	}
+)
+
+// NewNotify returns Notify use case
+func NewNotify(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	registry *notifier.Registry) *Notify {
+	return &Notify{
+		log:       log,
+		taskQueue: taskQueue,
+		registry:  registry,
+	}
+}
+
+// Do fans out notifications to the enabled channels
+func (use *Notify) Do(ctx context.Context, params NotifyParams) error {
+	const errTag = "Notify.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	channels := use.registry.Enabled()
+	if len(params.Channels) > 0 {
+		channels = nil
+		for _, channel := range params.Channels {
+			if use.registry.IsEnabled(channel) {
+				channels = append(channels, channel)
+			}
+		}
+	}
+	tasks := make([]event.Task, 0, len(params.Notifications)*len(channels))
+	for i, n := range params.Notifications {
+		for _, channel := range channels {
+			task := eventtask.NewEnqueueNotification(channel, n)
+			task.Delay = time.Duration(i) * time.Second
+			tasks = append(tasks, task)
+		}
+	}
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "notify channels:%v notifications len:%v", channels, len(params.Notifications))
+
+	return nil
+}
+
+// Dispatch delivers the notification to given channel
+// the notification to unknown or disabled channel is dropped, because the retry of the task never succeeds
+func (use *Notify) Dispatch(ctx context.Context, channel string, n notifier.Notification) error {
+	const errTag = "Notify.Dispatch failed"
+
+	if err := validator.Validate(n); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	ch, ok := use.registry.Find(channel)
+	if !ok || !use.registry.IsEnabled(channel) {
+		use.log.Warningf(ctx, "%v: drop the notification to unknown or disabled channel:%v", errTag, channel)
+		return nil
+	}
+	if err := ch.Notify(ctx, n); err != nil {
+		return errors.Wrapf(err, "%v: channel:%v", errTag, channel)
+	}
+	return nil
+}
+
+// editChannels are the channels that announce edits of feed items by comparing with their own history
+// the other channels deduplicate feed items by the ChannelItem history, so edits are not delivered to them
+var editChannels = []string{
+	notifier.ChannelTwitter,
+	notifier.ChannelLine,
+}
+
+// historyChannels are the channels that deduplicate feed items by the ChannelItem history
+var historyChannels = []string{
+	notifier.ChannelDiscord,
+	notifier.ChannelSlack,
+	notifier.ChannelMastodon,
+	notifier.ChannelBluesky,
+	notifier.ChannelEmail,
+	notifier.ChannelWebhook,
+	notifier.ChannelWebPush,
+	notifier.ChannelTelegram,
+}
+
+// recordChannelItem records the feed item to the history of given channel
+// it returns false if the item has already been recorded, history is not recorded in dry-run mode
+func recordChannelItem(
+	ctx context.Context,
+	transactor dao.Transactor,
+	repo entity.ChannelItemRepository,
+	channel string,
+	item crawler.FeedItem) (bool, error) {
+	const errTag = "recordChannelItem failed"
+
+	if err := validator.Validate(item); err != nil {
+		return false, errors.Wrap(err, errTag)
+	}
+
+	v := entity.NewChannelItem(channel, item.UniqueURL(), item.EntryTitle, item.EntryURL, item.PublishedAt)
+	var recorded bool
+	err := transactor.RunInTransaction(ctx, func(ctx context.Context) error {
+		recorded = false
+
+		if _, err := repo.Find(ctx, v.ID); err != dao.ErrNoSuchEntity {
+			return err
+		}
+		recorded = true
+		if dryrun.Enabled(ctx) {
+			return nil
+		}
+		return repo.Save(ctx, v)
+	}, nil)
+	if err != nil {
+		return false, errors.Wrap(err, errTag)
+	}
+	return recorded, nil
+}
+
+// recordFeedNotification records the feed item of given notification to the history of given channel
+// it returns false if the item has already been enqueued
+func recordFeedNotification(
+	ctx context.Context,
+	transactor dao.Transactor,
+	repo entity.ChannelItemRepository,
+	channel string,
+	n notifier.Notification) (bool, error) {
+	if n.FeedItem == nil {
+		return false, errors.New("feed item is empty")
+	}
+	return recordChannelItem(ctx, transactor, repo, channel, *n.FeedItem)
+}
+
+// savePreview saves the message and the number of recipients as Preview of given channel in dry-run mode
+// it returns true if the preview is saved instead of sending the message
+func savePreview(
+	ctx context.Context,
+	logger log.Logger,
+	repo entity.PreviewRepository,
+	channel string,
+	recipients int,
+	message interface{}) (bool, error) {
+	if !dryrun.Enabled(ctx) {
+		return false, nil
+	}
+
+	preview := struct {
+		Recipients int
+		Message    interface{}
+	}{recipients, message}
+	if err := repo.SaveMessages(ctx, channel, preview); err != nil {
+		return false, errors.Wrap(err, "savePreview failed")
+	}
+	logger.Infof(ctx, "dry-run: preview %v message recipients:%v", channel, recipients)
+	return true, nil
+}
+
+// pushAfterRateLimit pushes the task again that is delayed until the rate limit is reset
+// the retry of task queue is not used because its backoff does not know the reset time
+func pushAfterRateLimit(ctx context.Context, taskQueue event.TaskQueue, task event.Task, retryAfter time.Duration) error {
+	task.Delay = retryAfter
+	return taskQueue.Push(ctx, task)
+}
+
+// chunkURLs splits urls into chunks that have n urls at most
+func chunkURLs(urls []string, n int) [][]string {
+	var chunks [][]string
+	for j := 0; j < len(urls); j += n {
+		last := j + n
+		if last > len(urls) {
+			last = len(urls)
+		}
+		chunks = append(chunks, urls[j:last])
+	}
+	return chunks
+}
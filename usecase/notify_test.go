@@ -0,0 +1,161 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/linenotify"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/twitter"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func newTestNotify(taskQueue *eventtest.TaskQueue) *usecase.Notify {
+	h := dao.NewDatastoreHandler()
+	transactor := dao.NewDatastoreTransactor()
+	registry := notifier.NewRegistry()
+	registry.Register(notifier.ChannelTwitter, usecase.NewEnqueueTweets(log.NewAELogger(), taskQueue, transactor, entity.NewTweetItemRepository(h)))
+	registry.Register(notifier.ChannelLine, usecase.NewEnqueueLines(log.NewAELogger(), taskQueue, transactor, entity.NewLineItemRepository(h)))
+	return usecase.NewNotify(log.NewAELogger(), taskQueue, registry)
+}
+
+func TestNotify_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	taskQueue := eventtest.NewTaskQueue()
+	u := newTestNotify(taskQueue)
+
+	err = u.Do(ctx, usecase.NotifyParams{})
+	if errs, ok := errors.Cause(err).(validator.ValidationErrors); !ok {
+		t.Errorf("Expected validation error, got %v", errs)
+	}
+
+	now := time.Now()
+	err = u.Do(ctx, usecase.NotifyParams{Notifications: []notifier.Notification{
+		notifier.NewMessageNotification(notifier.KindReminder, "reminder 1", "", now),
+		notifier.NewMessageNotification(notifier.KindReminder, "reminder 2", "", now),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		delay time.Duration
+	}{
+		{"/enqueue/twitter", 0},
+		{"/enqueue/line", 0},
+		{"/enqueue/twitter", time.Second},
+		{"/enqueue/line", time.Second},
+	}
+	if len(taskQueue.Tasks) != len(tests) {
+		t.Fatalf("Expected taskqueue length %v, got %v", len(tests), len(taskQueue.Tasks))
+	}
+	for i, test := range tests {
+		if taskQueue.Tasks[i].Path != test.path || taskQueue.Tasks[i].Delay != test.delay {
+			t.Errorf("Expected %v %v, got %v %v", test.path, test.delay, taskQueue.Tasks[i].Path, taskQueue.Tasks[i].Delay)
+		}
+	}
+
+	// only given channels are notified
+	taskQueue.Tasks = nil
+	err = u.Do(ctx, usecase.NotifyParams{
+		Notifications: []notifier.Notification{notifier.NewMessageNotification(notifier.KindReminder, "reminder", "", now)},
+		Channels:      []string{notifier.ChannelTwitter, notifier.ChannelDiscord},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 1 || taskQueue.Tasks[0].Path != "/enqueue/twitter" {
+		t.Errorf("Expected twitter task only, got %v", taskQueue.Tasks)
+	}
+
+	// only enabled channels are notified
+	testutil.MustConfigLoadWith(`
+[Notifier]
+  Channels = ["line"]
+`)
+	defer testutil.MustConfigLoad()
+
+	taskQueue.Tasks = nil
+	err = u.Do(ctx, usecase.NotifyParams{Notifications: []notifier.Notification{
+		notifier.NewMessageNotification(notifier.KindReminder, "reminder", "", now),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 1 || taskQueue.Tasks[0].Path != "/enqueue/line" {
+		t.Errorf("Expected line task only, got %v", taskQueue.Tasks)
+	}
+}
+
+func TestNotify_Dispatch(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	taskQueue := eventtest.NewTaskQueue()
+	u := newTestNotify(taskQueue)
+
+	n := notifier.NewMessageNotification(
+		notifier.KindUstream,
+		"momocloTV が配信を開始しました",
+		"http://www.ustream.tv/channel/momoclotv",
+		time.Date(2017, 11, 1, 20, 0, 0, 0, time.FixedZone("", 9*60*60)),
+	)
+	// unknown channel is dropped without error, so that the task is not retried
+	if err := u.Dispatch(ctx, "unknown", n); err != nil {
+		t.Errorf("Expected unknown channel is dropped, got %v", err)
+	}
+	if len(taskQueue.Tasks) != 0 {
+		t.Fatalf("Expected taskqueue length 0, got %v", len(taskQueue.Tasks))
+	}
+
+	if err := u.Dispatch(ctx, notifier.ChannelTwitter, n); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Dispatch(ctx, notifier.ChannelLine, n); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 2 {
+		t.Fatalf("Expected taskqueue length 2, got %v", len(taskQueue.Tasks))
+	}
+
+	tweet := taskQueue.Tasks[0].Object.([]twitter.TweetRequest)[0]
+	if expected := "momocloTV が配信を開始しました\nfrom 2017/11/01 20:00:00\nhttp://www.ustream.tv/channel/momoclotv"; tweet.Text != expected {
+		t.Errorf("Expected tweet %q, got %q", expected, tweet.Text)
+	}
+	line := taskQueue.Tasks[1].Object.(linenotify.Broadcast).Messages[0]
+	if expected := "\nmomocloTV が配信を開始しました\nhttp://www.ustream.tv/channel/momoclotv"; line.Text != expected {
+		t.Errorf("Expected line message %q, got %q", expected, line.Text)
+	}
+
+	// disabled channel is dropped as well
+	testutil.MustConfigLoadWith(`
+[Notifier]
+  Channels = ["line"]
+`)
+	defer testutil.MustConfigLoad()
+
+	taskQueue.Tasks = nil
+	if err := u.Dispatch(ctx, notifier.ChannelTwitter, n); err != nil {
+		t.Errorf("Expected disabled channel is dropped, got %v", err)
+	}
+	if len(taskQueue.Tasks) != 0 {
+		t.Errorf("Expected taskqueue length 0, got %v", len(taskQueue.Tasks))
+	}
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/bluesky"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// PostBlueskyThread use case
+	PostBlueskyThread struct {
+		log         log.Logger
+		taskQueue   event.TaskQueue
+		client      bluesky.Client
+		sessionRepo entity.BlueskySessionRepository
+		preview     entity.PreviewRepository
+	}
+
+	// PostBlueskyThreadParams input parameters
+	PostBlueskyThreadParams struct {
+		Requests []bluesky.PostRequest `validate:"min=1,dive"`
+	}
+)
+
+// NewPostBlueskyThread returns PostBlueskyThread use case
+func NewPostBlueskyThread(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	client bluesky.Client,
+	sessionRepo entity.BlueskySessionRepository,
+	preview entity.PreviewRepository) *PostBlueskyThread {
+	return &PostBlueskyThread{
+		log:         log,
+		taskQueue:   taskQueue,
+		client:      client,
+		sessionRepo: sessionRepo,
+		preview:     preview,
+	}
+}
+
+// Do posts the first request and enqueues the rest as its replies
+func (use *PostBlueskyThread) Do(ctx context.Context, params PostBlueskyThreadParams) error {
+	const errTag = "PostBlueskyThread.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	if dryrun.Enabled(ctx) {
+		// record the whole thread at once instead of posting
+		if err := use.preview.SaveMessages(ctx, notifier.ChannelBluesky, params.Requests); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "dry-run: preview bluesky posts len:%v", len(params.Requests))
+		return nil
+	}
+
+	session, err := use.session(ctx)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	req := params.Requests[0]
+	ref, err := use.client.Post(ctx, session, req)
+	if errors.Cause(err) == bluesky.ErrExpiredToken {
+		session, err = use.refreshSession(ctx, session)
+		if err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		ref, err = use.client.Post(ctx, session, req)
+	}
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "bluesky post: %v", ref.URI)
+
+	requests := params.Requests[1:] // go to next post
+	if len(requests) == 0 {
+		use.log.Info(ctx, "done!")
+		return nil
+	}
+	root := ref
+	if req.Reply != nil {
+		root = req.Reply.Root
+	}
+	requests[0].Reply = &bluesky.ReplyRef{Root: root, Parent: ref}
+
+	task := eventtask.NewBlueskyPosts(requests)
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	return nil
+}
+
+// session returns the stored session, or creates a new one if not stored
+func (use *PostBlueskyThread) session(ctx context.Context) (bluesky.Session, error) {
+	c := config.C().Bluesky
+	if c.Disabled {
+		return use.client.CreateSession(ctx) // the disabled client has no account to store the session
+	}
+
+	s, err := use.sessionRepo.Find(ctx, c.Identifier)
+	if err == dao.ErrNoSuchEntity {
+		return use.createSession(ctx)
+	}
+	if err != nil {
+		return bluesky.Session{}, err
+	}
+	return s.Session(c.TokenKey)
+}
+
+// refreshSession refreshes the session, or creates a new one if the refresh token is also expired
+func (use *PostBlueskyThread) refreshSession(ctx context.Context, s bluesky.Session) (bluesky.Session, error) {
+	refreshed, err := use.client.RefreshSession(ctx, s)
+	if errors.Cause(err) == bluesky.ErrExpiredToken {
+		return use.createSession(ctx)
+	}
+	if err != nil {
+		return bluesky.Session{}, err
+	}
+	return refreshed, use.saveSession(ctx, refreshed)
+}
+
+func (use *PostBlueskyThread) createSession(ctx context.Context) (bluesky.Session, error) {
+	s, err := use.client.CreateSession(ctx)
+	if err != nil {
+		return bluesky.Session{}, err
+	}
+	return s, use.saveSession(ctx, s)
+}
+
+func (use *PostBlueskyThread) saveSession(ctx context.Context, s bluesky.Session) error {
+	c := config.C().Bluesky
+	bs, err := entity.NewBlueskySession(c.TokenKey, c.Identifier, s)
+	if err != nil {
+		return err
+	}
+	return use.sessionRepo.Save(ctx, bs)
+}
@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/validator"
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/bluesky"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+// blueskyClient is a fake bluesky.Client that expires access tokens of given value
+type blueskyClient struct {
+	expiredToken string
+	created      int
+	refreshed    int
+	posted       []bluesky.PostRequest
+}
+
+func (c *blueskyClient) CreateSession(_ context.Context) (bluesky.Session, error) {
+	c.created++
+	return bluesky.Session{DID: "did:plc:test", AccessJwt: "access", RefreshJwt: "refresh"}, nil
+}
+
+func (c *blueskyClient) RefreshSession(_ context.Context, s bluesky.Session) (bluesky.Session, error) {
+	c.refreshed++
+	return bluesky.Session{DID: s.DID, AccessJwt: "refreshed", RefreshJwt: s.RefreshJwt}, nil
+}
+
+func (c *blueskyClient) Post(_ context.Context, s bluesky.Session, req bluesky.PostRequest) (bluesky.StrongRef, error) {
+	if s.AccessJwt == c.expiredToken {
+		return bluesky.StrongRef{}, errors.Wrap(bluesky.ErrExpiredToken, "post")
+	}
+	c.posted = append(c.posted, req)
+	return bluesky.StrongRef{URI: "at://did:plc:test/app.bsky.feed.post/1", CID: "cid1"}, nil
+}
+
+func TestPostBlueskyThread_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	client := &blueskyClient{expiredToken: "access"}
+	taskQueue := eventtest.NewTaskQueue()
+	sessionRepo := entity.NewBlueskySessionRepository(dao.NewDatastoreHandler())
+	u := usecase.NewPostBlueskyThread(log.NewAELogger(), taskQueue, client, sessionRepo, entity.NewPreviewRepository(dao.NewDatastoreHandler()))
+
+	validationTests := []struct {
+		params usecase.PostBlueskyThreadParams
+	}{
+		{},
+		{usecase.PostBlueskyThreadParams{Requests: []bluesky.PostRequest{
+			{ImageURLs: []string{"a"}},
+		}}},
+		{usecase.PostBlueskyThreadParams{Requests: []bluesky.PostRequest{
+			{Text: "a", Reply: &bluesky.ReplyRef{}},
+		}}},
+	}
+	for _, test := range validationTests {
+		err = u.Do(ctx, test.params)
+		if errs, ok := errors.Cause(err).(validator.ValidationErrors); !ok {
+			t.Errorf("Expected validation error, got %v", errs)
+		}
+	}
+
+	err = u.Do(ctx, usecase.PostBlueskyThreadParams{Requests: []bluesky.PostRequest{
+		{Text: "test", ImageURLs: []string{"http://localhost/a"}},
+		{ImageURLs: []string{"http://localhost/b"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.created != 1 || client.refreshed != 1 {
+		t.Errorf("Expected the session created and refreshed once, got %v %v", client.created, client.refreshed)
+	}
+	if len(client.posted) != 1 || client.posted[0].Text != "test" {
+		t.Errorf("Expected the first post, got %v", client.posted)
+	}
+	s, err := sessionRepo.Find(ctx, config.C().Bluesky.Identifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session, _ := s.Session(config.C().Bluesky.TokenKey); session.AccessJwt != "refreshed" {
+		t.Errorf("Expected the refreshed session stored, got %v", session)
+	}
+
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+	next := taskQueue.Tasks[0].Object.([]bluesky.PostRequest)
+	if len(next) != 1 || next[0].Reply == nil || next[0].Reply.Root.CID != "cid1" || next[0].Reply.Parent.CID != "cid1" {
+		t.Errorf("Expected the reply of the first post, got %v", next)
+	}
+
+	// the stored session is reused
+	err = u.Do(ctx, usecase.PostBlueskyThreadParams{Requests: next})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.created != 1 || client.refreshed != 1 || len(client.posted) != 2 {
+		t.Errorf("Expected the stored session reused, got %v %v %v", client.created, client.refreshed, len(client.posted))
+	}
+}
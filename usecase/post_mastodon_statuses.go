@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mastodon"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// PostMastodonStatuses use case
+	PostMastodonStatuses struct {
+		log       log.Logger
+		taskQueue event.TaskQueue
+		client    mastodon.Client
+		preview   entity.PreviewRepository
+	}
+
+	// PostMastodonStatusesParams input parameters
+	PostMastodonStatusesParams struct {
+		Requests []mastodon.StatusRequest `validate:"min=1,dive"`
+	}
+)
+
+// NewPostMastodonStatuses returns PostMastodonStatuses use case
+func NewPostMastodonStatuses(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	client mastodon.Client,
+	preview entity.PreviewRepository) *PostMastodonStatuses {
+	return &PostMastodonStatuses{
+		log:       log,
+		taskQueue: taskQueue,
+		client:    client,
+		preview:   preview,
+	}
+}
+
+// Do posts the first status and enqueues the rest as its replies
+func (use *PostMastodonStatuses) Do(ctx context.Context, params PostMastodonStatusesParams) error {
+	const errTag = "PostMastodonStatuses.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	if dryrun.Enabled(ctx) {
+		// record the whole thread at once instead of posting
+		if err := use.preview.SaveMessages(ctx, notifier.ChannelMastodon, params.Requests); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "dry-run: preview mastodon statuses len:%v", len(params.Requests))
+		return nil
+	}
+
+	res, err := use.client.PostStatus(ctx, params.Requests[0])
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "mastodon status: %v", params.Requests[0])
+
+	requests := params.Requests[1:] // go to next status
+	if len(requests) == 0 {
+		use.log.Info(ctx, "done!")
+		return nil
+	}
+	requests[0].InReplyToID = res.ID
+
+	task := eventtask.NewMastodonStatuses(requests)
+	if err := use.taskQueue.Push(ctx, task); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	return nil
+}
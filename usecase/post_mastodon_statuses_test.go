@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/validator"
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mastodon"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+// mastodonClientFunc is a mastodon.Client that returns the result of the function
+type mastodonClientFunc func(mastodon.StatusRequest) (mastodon.StatusResponse, error)
+
+func (f mastodonClientFunc) PostStatus(_ context.Context, req mastodon.StatusRequest) (mastodon.StatusResponse, error) {
+	return f(req)
+}
+
+func TestPostMastodonStatuses_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	var posted []mastodon.StatusRequest
+	client := mastodonClientFunc(func(req mastodon.StatusRequest) (mastodon.StatusResponse, error) {
+		posted = append(posted, req)
+		return mastodon.StatusResponse{ID: "100"}, nil
+	})
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewPostMastodonStatuses(log.NewAELogger(), taskQueue, client, entity.NewPreviewRepository(dao.NewDatastoreHandler()))
+
+	validationTests := []struct {
+		params usecase.PostMastodonStatusesParams
+	}{
+		{},
+		{usecase.PostMastodonStatusesParams{Requests: []mastodon.StatusRequest{
+			{ImageURLs: []string{"a"}},
+		}}},
+		{usecase.PostMastodonStatusesParams{Requests: []mastodon.StatusRequest{
+			{Text: "a", Visibility: "unknown"},
+		}}},
+	}
+	for _, test := range validationTests {
+		err = u.Do(ctx, test.params)
+		if errs, ok := errors.Cause(err).(validator.ValidationErrors); !ok {
+			t.Errorf("Expected validation error, got %v", errs)
+		}
+	}
+
+	err = u.Do(ctx, usecase.PostMastodonStatusesParams{Requests: []mastodon.StatusRequest{
+		{Text: "test", ImageURLs: []string{"http://localhost/a"}, SpoilerText: "cw"},
+		{ImageURLs: []string{"http://localhost/b"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posted) != 1 || posted[0].SpoilerText != "cw" {
+		t.Errorf("Expected the first status posted, got %v", posted)
+	}
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+	next := taskQueue.Tasks[0].Object.([]mastodon.StatusRequest)
+	if len(next) != 1 || next[0].InReplyToID != "100" {
+		t.Errorf("Expected the reply of the first status, got %v", next)
+	}
+}
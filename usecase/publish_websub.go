@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/timeutil"
+)
+
+type (
+	// PublishWebSub use case
+	PublishWebSub struct {
+		log       log.Logger
+		taskQueue event.TaskQueue
+		repo      entity.WebSubSubscriptionRepository
+	}
+)
+
+// NewPublishWebSub returns PublishWebSub use case
+func NewPublishWebSub(log log.Logger, taskQueue event.TaskQueue, repo entity.WebSubSubscriptionRepository) *PublishWebSub {
+	return &PublishWebSub{
+		log:       log,
+		taskQueue: taskQueue,
+		repo:      repo,
+	}
+}
+
+// Do enqueues content distribution to the subscribers of the feeds
+// the subscriptions of which lease is expired are deleted
+func (use *PublishWebSub) Do(ctx context.Context) error {
+	const errTag = "PublishWebSub.Do failed"
+
+	subscriptions, err := use.repo.FindAll(ctx)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	now := timeutil.Now()
+	var tasks []event.Task
+	for _, s := range subscriptions {
+		if s.Expired(now) {
+			if err := use.repo.Delete(ctx, s.ID); err != nil {
+				return errors.Wrap(err, errTag)
+			}
+			use.log.Infof(ctx, "websub subscription expired topic:%v callback:%v", s.Topic, s.Callback)
+			continue
+		}
+		tasks = append(tasks, eventtask.NewWebSubDistribution(s.ID))
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue websub distribution tasks len:%v", len(tasks))
+
+	return nil
+}
@@ -2,36 +2,32 @@ package usecase
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/pkg/errors"
 	"github.com/utahta/momoclo-channel/entity"
-	"github.com/utahta/momoclo-channel/event"
-	"github.com/utahta/momoclo-channel/event/eventtask"
-	"github.com/utahta/momoclo-channel/linenotify"
 	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
 	"github.com/utahta/momoclo-channel/timeutil"
-	"github.com/utahta/momoclo-channel/twitter"
 )
 
 type (
 	// Remind use case
 	Remind struct {
-		log       log.Logger
-		taskQueue event.TaskQueue
-		repo      entity.ReminderRepository
+		log    log.Logger
+		notify *Notify
+		repo   entity.ReminderRepository
 	}
 )
 
 // NewRemind returns Remind use case
 func NewRemind(
 	logger log.Logger,
-	taskQueue event.TaskQueue,
+	notify *Notify,
 	repo entity.ReminderRepository) *Remind {
 	return &Remind{
-		log:       logger,
-		taskQueue: taskQueue,
-		repo:      repo,
+		log:    logger,
+		notify: notify,
+		repo:   repo,
 	}
 }
 
@@ -61,10 +57,11 @@ func (r *Remind) Do(ctx context.Context) error {
 			}
 		}
 
-		r.taskQueue.PushMulti(ctx, []event.Task{
-			eventtask.NewTweet(twitter.TweetRequest{Text: reminder.Text}),
-			eventtask.NewLineBroadcast(linenotify.Message{Text: fmt.Sprintf("\n%s", reminder.Text)}),
-		})
+		n := notifier.NewMessageNotification(notifier.KindReminder, reminder.Text, "", now)
+		if err := r.notify.Do(ctx, NotifyParams{Notifications: []notifier.Notification{n}}); err != nil {
+			r.log.Errorf(ctx, "%v: notify reminder %v err:%v", errTag, reminder, err)
+			continue
+		}
 		r.log.Infof(ctx, "remind: %#v", reminder)
 	}
 	return nil
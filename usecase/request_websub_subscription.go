@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/syndication"
+	"github.com/utahta/momoclo-channel/validator"
+	"github.com/utahta/momoclo-channel/websub"
+)
+
+type (
+	// RequestWebSubSubscription use case
+	RequestWebSubSubscription struct {
+		log       log.Logger
+		taskQueue event.TaskQueue
+	}
+
+	// RequestWebSubSubscriptionParams input parameters
+	RequestWebSubSubscriptionParams struct {
+		Request websub.Request
+	}
+)
+
+// NewRequestWebSubSubscription returns RequestWebSubSubscription use case
+func NewRequestWebSubSubscription(log log.Logger, taskQueue event.TaskQueue) *RequestWebSubSubscription {
+	return &RequestWebSubSubscription{
+		log:       log,
+		taskQueue: taskQueue,
+	}
+}
+
+// Do accepts the subscription request of a subscriber and enqueues the verification of intent
+// the topic must be one of our aggregated feeds
+func (use *RequestWebSubSubscription) Do(ctx context.Context, params RequestWebSubSubscriptionParams) error {
+	const errTag = "RequestWebSubSubscription.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if _, _, err := parseWebSubTopic(params.Request.Topic); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	req := params.Request
+	if req.LeaseSeconds == 0 {
+		req.LeaseSeconds = websub.DefaultLeaseSeconds
+	}
+	if req.LeaseSeconds > websub.MaxLeaseSeconds {
+		req.LeaseSeconds = websub.MaxLeaseSeconds
+	}
+
+	if err := use.taskQueue.Push(ctx, eventtask.NewWebSubVerification(req)); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue websub verification mode:%v topic:%v callback:%v", req.Mode, req.Topic, req.Callback)
+
+	return nil
+}
+
+// parseWebSubTopic returns the extension and the feed code of given topic (e.g. http://localhost/feed.atom?code=aenews)
+func parseWebSubTopic(topic string) (string, string, error) {
+	if !strings.HasPrefix(topic, config.C().App.BaseURL+"/") {
+		return "", "", errors.Errorf("unknown topic:%v", topic)
+	}
+	u, err := url.Parse(topic)
+	if err != nil {
+		return "", "", err
+	}
+
+	ext := path.Ext(u.Path)
+	if _, _, ok := syndication.Encoding(ext); !ok || u.Path != "/feed"+ext {
+		return "", "", errors.Errorf("unknown topic:%v", topic)
+	}
+	code := u.Query().Get("code")
+	if code != "" {
+		if _, ok := crawler.FindFeed(crawler.FeedCode(code)); !ok {
+			return "", "", errors.Errorf("unknown topic code:%v", code)
+		}
+	}
+	return ext, code, nil
+}
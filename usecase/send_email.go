@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mailer"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// SendEmail use case
+	SendEmail struct {
+		log    log.Logger
+		mailer mailer.Mailer
+	}
+
+	// SendEmailParams input parameters
+	SendEmailParams struct {
+		Mail mailer.Mail
+	}
+)
+
+// NewSendEmail returns SendEmail use case
+func NewSendEmail(log log.Logger, mailer mailer.Mailer) *SendEmail {
+	return &SendEmail{
+		log:    log,
+		mailer: mailer,
+	}
+}
+
+// Do sends the mail
+func (use *SendEmail) Do(ctx context.Context, params SendEmailParams) error {
+	const errTag = "SendEmail.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	if err := use.mailer.Send(ctx, params.Mail); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "send email subject:%v", params.Mail.Subject)
+
+	return nil
+}
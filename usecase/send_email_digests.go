@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mailer"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// SendEmailDigests use case
+	SendEmailDigests struct {
+		log            log.Logger
+		taskQueue      event.TaskQueue
+		subscriberRepo entity.EmailSubscriberRepository
+		lineItemRepo   entity.LineItemRepository
+		preview        entity.PreviewRepository
+	}
+
+	// SendEmailDigestsParams input parameters
+	SendEmailDigestsParams struct {
+		Frequency string `validate:"eq=daily|eq=weekly"`
+	}
+)
+
+// NewSendEmailDigests returns SendEmailDigests use case
+func NewSendEmailDigests(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	subscriberRepo entity.EmailSubscriberRepository,
+	lineItemRepo entity.LineItemRepository,
+	preview entity.PreviewRepository) *SendEmailDigests {
+	return &SendEmailDigests{
+		log:            log,
+		taskQueue:      taskQueue,
+		subscriberRepo: subscriberRepo,
+		lineItemRepo:   lineItemRepo,
+		preview:        preview,
+	}
+}
+
+// Do enqueues the digest of entries in the period for each subscriber of given frequency
+// the entries come from LineItem history, and the subscriber who has no new entries is skipped
+func (use *SendEmailDigests) Do(ctx context.Context, params SendEmailDigestsParams) error {
+	const errTag = "SendEmailDigests.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	subscribers, err := use.subscriberRepo.FindConfirmed(ctx, params.Frequency)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	period := 24 * time.Hour
+	if params.Frequency == entity.EmailFrequencyWeekly {
+		period = 7 * 24 * time.Hour
+	}
+	now := timeutil.Now()
+	since := now.Add(-period)
+
+	items, err := use.lineItemRepo.FindSince(ctx, since)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	subject := digestSubject(params.Frequency, since, now)
+	var (
+		mails   []mailer.Mail
+		digests []*entity.EmailSubscriber
+	)
+	for _, s := range subscribers {
+		from := s.DigestedAt
+		if from.Before(since) {
+			from = since
+		}
+
+		var newItems []*entity.LineItem
+		for _, item := range items {
+			if item.CreatedAt.After(from) {
+				newItems = append(newItems, item)
+			}
+		}
+		if len(newItems) == 0 {
+			continue
+		}
+
+		mails = append(mails, mailer.Mail{
+			To:             s.Email,
+			Subject:        subject,
+			Body:           digestBody(newItems) + emailFooter(s),
+			UnsubscribeURL: emailLinkURL(s, entity.EmailActionUnsubscribe, nil),
+		})
+		s.DigestedAt = now
+		digests = append(digests, s)
+	}
+	if len(mails) == 0 {
+		use.log.Infof(ctx, "no new entries for %v digest", params.Frequency)
+		return nil
+	}
+
+	if dryrun.Enabled(ctx) {
+		preview := struct {
+			Recipients int
+			Mail       mailer.Mail
+		}{len(mails), mails[0]}
+		if err := use.preview.SaveMessages(ctx, notifier.ChannelEmail, preview); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "dry-run: preview %v digest recipients:%v", params.Frequency, len(mails))
+		return nil
+	}
+
+	tasks := make([]event.Task, len(mails))
+	for i, mail := range mails {
+		tasks[i] = eventtask.NewEmail(mail)
+	}
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if err := use.subscriberRepo.SaveMulti(ctx, digests); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "enqueue %v digest tasks len:%v", params.Frequency, len(tasks))
+
+	return nil
+}
+
+// digestSubject returns the subject of digest mails
+func digestSubject(frequency string, since, now time.Time) string {
+	const layout = "2006/01/02"
+	if frequency == entity.EmailFrequencyWeekly {
+		return fmt.Sprintf("【ももクロちゃんねる】ウィークリーまとめ %s - %s", since.In(timeutil.JST()).Format(layout), now.In(timeutil.JST()).Format(layout))
+	}
+	return fmt.Sprintf("【ももクロちゃんねる】デイリーまとめ %s", now.In(timeutil.JST()).Format(layout))
+}
+
+// digestBody returns the list of entries
+func digestBody(items []*entity.LineItem) string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "新着 %d件\n\n", len(items))
+	for _, item := range items {
+		fmt.Fprintf(buf, "・%s\n  %s\n", item.Title, item.URL)
+	}
+	return buf.String()
+}
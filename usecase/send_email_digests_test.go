@@ -0,0 +1,95 @@
+package usecase_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mailer"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestSendEmailDigests_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	h := dao.NewDatastoreHandler()
+	now := time.Now()
+	lineItemRepo := entity.NewLineItemRepository(h)
+	for i, d := range []time.Duration{-48 * time.Hour, -3 * time.Hour, -1 * time.Hour} {
+		item := entity.NewLineItem(fmt.Sprintf("id-%d", i), fmt.Sprintf("entry %d", i), fmt.Sprintf("http://localhost/%d", i), now, nil, nil)
+		item.CreatedAt = now.Add(d)
+		if err := lineItemRepo.Save(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	subscriberRepo := entity.NewEmailSubscriberRepository(h)
+	subscribers := []struct {
+		email      string
+		frequency  string
+		confirmed  bool
+		digestedAt time.Time
+	}{
+		{"daily@example.com", entity.EmailFrequencyDaily, true, now.Add(-24 * time.Hour)},
+		{"recent@example.com", entity.EmailFrequencyDaily, true, now.Add(-2 * time.Hour)},
+		{"uptodate@example.com", entity.EmailFrequencyDaily, true, now},
+		{"unconfirmed@example.com", entity.EmailFrequencyDaily, false, time.Time{}},
+		{"weekly@example.com", entity.EmailFrequencyWeekly, true, now.Add(-7 * 24 * time.Hour)},
+	}
+	for _, v := range subscribers {
+		s := entity.NewEmailSubscriber(v.email, v.frequency)
+		s.Confirmed = v.confirmed
+		s.DigestedAt = v.digestedAt
+		if err := subscriberRepo.Save(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewSendEmailDigests(log.NewAELogger(), taskQueue, subscriberRepo, lineItemRepo, entity.NewPreviewRepository(h))
+	if err := u.Do(ctx, usecase.SendEmailDigestsParams{Frequency: "immediate"}); err == nil {
+		t.Error("Expected validation error, got nil")
+	}
+	if err := u.Do(ctx, usecase.SendEmailDigestsParams{Frequency: entity.EmailFrequencyDaily}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(taskQueue.Tasks) != 2 {
+		t.Fatalf("Expected taskqueue length 2, got %v", len(taskQueue.Tasks))
+	}
+	mails := map[string]mailer.Mail{}
+	for _, task := range taskQueue.Tasks {
+		mail := task.Object.(mailer.Mail)
+		mails[mail.To] = mail
+	}
+	if body := mails["daily@example.com"].Body; !strings.Contains(body, "entry 1") || !strings.Contains(body, "entry 2") || strings.Contains(body, "entry 0") {
+		t.Errorf("Expected entries in a day, got %q", body)
+	}
+	if body := mails["recent@example.com"].Body; strings.Contains(body, "entry 1") || !strings.Contains(body, "entry 2") {
+		t.Errorf("Expected entries since the last digest, got %q", body)
+	}
+	if mail := mails["daily@example.com"]; mail.UnsubscribeURL == "" || !strings.Contains(mail.Body, mail.UnsubscribeURL) {
+		t.Errorf("Expected unsubscribe link, got %v", mail)
+	}
+
+	// the entries are not sent twice
+	if err := u.Do(ctx, usecase.SendEmailDigestsParams{Frequency: entity.EmailFrequencyDaily}); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 2 {
+		t.Errorf("Expected taskqueue length 2, got %v", len(taskQueue.Tasks))
+	}
+}
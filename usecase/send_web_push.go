@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+	"github.com/utahta/momoclo-channel/webpush"
+)
+
+type (
+	// SendWebPush use case
+	SendWebPush struct {
+		log    log.Logger
+		client webpush.Client
+		repo   entity.WebPushSubscriptionRepository
+	}
+
+	// SendWebPushParams input parameters
+	SendWebPushParams struct {
+		Request webpush.Request
+	}
+)
+
+// NewSendWebPush returns SendWebPush use case
+func NewSendWebPush(log log.Logger, client webpush.Client, repo entity.WebPushSubscriptionRepository) *SendWebPush {
+	return &SendWebPush{
+		log:    log,
+		client: client,
+		repo:   repo,
+	}
+}
+
+// Do sends a push to the subscriber
+// the subscription is deleted if push service responds that it is expired or unsubscribed
+func (use *SendWebPush) Do(ctx context.Context, params SendWebPushParams) error {
+	const errTag = "SendWebPush.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	err := use.client.Send(ctx, params.Request)
+	if err == webpush.ErrGone {
+		if err := use.repo.Delete(ctx, params.Request.ID); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "delete gone web push subscription id:%v", params.Request.ID)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "send web push id:%v", params.Request.ID)
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/linebot"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// ShowLineNotifyPreferences use case
+	ShowLineNotifyPreferences struct {
+		log  log.Logger
+		repo entity.LineNotificationRepository
+	}
+
+	// ShowLineNotifyPreferencesParams input parameters
+	ShowLineNotifyPreferencesParams struct {
+		ID    string `validate:"required"`
+		Token string `validate:"required"`
+	}
+
+	// ShowLineNotifyPreferencesResult represents the preferences and the choices of them
+	ShowLineNotifyPreferencesResult struct {
+		Preferences LineNotifyPreferences
+		Codes       []string // feed codes that can be chosen
+		Members     []string // member names that can be chosen
+	}
+)
+
+// NewShowLineNotifyPreferences returns ShowLineNotifyPreferences use case
+func NewShowLineNotifyPreferences(log log.Logger, repo entity.LineNotificationRepository) *ShowLineNotifyPreferences {
+	return &ShowLineNotifyPreferences{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do returns the preferences of the subscriber given signed link
+func (use *ShowLineNotifyPreferences) Do(ctx context.Context, params ShowLineNotifyPreferencesParams) (ShowLineNotifyPreferencesResult, error) {
+	const errTag = "ShowLineNotifyPreferences.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return ShowLineNotifyPreferencesResult{}, errors.Wrap(err, errTag)
+	}
+
+	l, err := use.repo.Find(ctx, params.ID)
+	if err != nil {
+		return ShowLineNotifyPreferencesResult{}, errors.Wrap(err, errTag)
+	}
+	if !l.VerifyPreferencesToken(config.C().LineNotify.SigningKey, params.Token) {
+		return ShowLineNotifyPreferencesResult{}, errors.Errorf("%v: invalid token id:%v", errTag, params.ID)
+	}
+
+	return ShowLineNotifyPreferencesResult{
+		Preferences: newLineNotifyPreferences(l),
+		Codes:       lineFeedCodes(),
+		Members:     linebot.MemberNames(),
+	}, nil
+}
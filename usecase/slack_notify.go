@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/slack"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// SlackNotify use case
+	SlackNotify struct {
+		log       log.Logger
+		taskQueue event.TaskQueue
+		client    slack.Client
+		repo      entity.SlackWebhookRepository
+	}
+
+	// SlackNotifyParams input parameters
+	SlackNotifyParams struct {
+		Request slack.Request
+	}
+)
+
+// NewSlackNotify returns SlackNotify use case
+func NewSlackNotify(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	client slack.Client,
+	repo entity.SlackWebhookRepository) *SlackNotify {
+	return &SlackNotify{
+		log:       log,
+		taskQueue: taskQueue,
+		client:    client,
+		repo:      repo,
+	}
+}
+
+// Do posts message to slack webhook
+func (use *SlackNotify) Do(ctx context.Context, params SlackNotifyParams) error {
+	const errTag = "SlackNotify.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	request := params.Request
+	err := use.client.Post(ctx, request.WebhookURL, request.Message)
+	if err != nil {
+		if err == slack.ErrInvalidWebhook {
+			err = use.repo.Delete(ctx, request.ID)
+			use.log.Infof(ctx, "delete id:%v err:%v", request.ID, err)
+			return errors.Wrap(err, errTag)
+		}
+		if e, ok := err.(*slack.RateLimitError); ok {
+			if err := pushAfterRateLimit(ctx, use.taskQueue, eventtask.NewSlack(request), e.RetryAfter); err != nil {
+				return errors.Wrap(err, errTag)
+			}
+			use.log.Warningf(ctx, "slack rate limited id:%v retry after:%v", request.ID, e.RetryAfter)
+			return nil
+		}
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "slack notify id:%v", request.ID)
+
+	return nil
+}
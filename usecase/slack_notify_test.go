@@ -0,0 +1,95 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/slack"
+	"github.com/utahta/momoclo-channel/slack/slacktest"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestSlackNotify_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	s := slacktest.NewServer()
+	defer s.Close()
+
+	testutil.MustConfigLoad()
+	repo := entity.NewSlackWebhookRepository(dao.NewDatastoreHandler())
+	w, err := entity.NewSlackWebhook(config.C().Slack.TokenKey, s.WebhookURL("/services/a"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+
+	// enqueue the notification and deliver it to the fake server
+	taskQueue := eventtest.NewTaskQueue()
+	enqueue := usecase.NewEnqueueSlack(
+		log.NewAELogger(),
+		taskQueue,
+		dao.NewDatastoreTransactor(),
+		entity.NewChannelItemRepository(dao.NewDatastoreHandler()),
+		repo,
+		entity.NewPreviewRepository(dao.NewDatastoreHandler()),
+	)
+	n := notifier.NewMessageNotification(
+		notifier.KindUstream,
+		"momocloTV が配信を開始しました",
+		"http://www.ustream.tv/channel/momoclotv",
+		time.Date(2017, 11, 1, 20, 0, 0, 0, time.FixedZone("", 9*60*60)),
+	)
+	if err := enqueue.Notify(ctx, n); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+
+	client := slack.NewWithHTTPClient(func(context.Context) *http.Client { return http.DefaultClient })
+	u := usecase.NewSlackNotify(log.NewAELogger(), taskQueue, client, repo)
+	params := usecase.SlackNotifyParams{Request: taskQueue.Tasks[0].Object.(slack.Request)}
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := s.Messages("/services/a")
+	if len(messages) != 1 {
+		t.Fatalf("Expected messages length 1, got %v", len(messages))
+	}
+	if expected := "*momocloTV が配信を開始しました*\n<http://www.ustream.tv/channel/momoclotv>"; messages[0].Blocks[0].Text.Text != expected {
+		t.Errorf("Expected section %q, got %q", expected, messages[0].Blocks[0].Text.Text)
+	}
+	if expected := "from 2017/11/01 20:00"; messages[0].Blocks[1].Elements[0].Text != expected {
+		t.Errorf("Expected context %q, got %q", expected, messages[0].Blocks[1].Elements[0].Text)
+	}
+
+	// removed webhook is deleted
+	s.SetStatus("/services/a", http.StatusNotFound)
+	if err := u.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	webhooks, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 0 {
+		t.Errorf("Expected webhooks length 0, got %v", len(webhooks))
+	}
+}
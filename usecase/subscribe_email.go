@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mailer"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+// emailLinkInterval is the interval that the mail of the link is sent to the same address
+const emailLinkInterval = 10 * time.Minute
+
+type (
+	// SubscribeEmail use case
+	SubscribeEmail struct {
+		log    log.Logger
+		mailer mailer.Mailer
+		repo   entity.EmailSubscriberRepository
+	}
+
+	// SubscribeEmailParams input parameters
+	SubscribeEmailParams struct {
+		Email     string `validate:"required,email"`
+		Frequency string `validate:"eq=immediate|eq=daily|eq=weekly"`
+	}
+)
+
+// NewSubscribeEmail returns SubscribeEmail use case
+func NewSubscribeEmail(log log.Logger, mailer mailer.Mailer, repo entity.EmailSubscriberRepository) *SubscribeEmail {
+	return &SubscribeEmail{
+		log:    log,
+		mailer: mailer,
+		repo:   repo,
+	}
+}
+
+// Do stores the unconfirmed subscriber and sends the confirmation mail
+// the confirmed subscriber receives the manage link to change the frequency instead
+// the mail is not sent to the same address again within emailLinkInterval, because the endpoint is public
+func (use *SubscribeEmail) Do(ctx context.Context, params SubscribeEmailParams) error {
+	const errTag = "SubscribeEmail.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	s := entity.NewEmailSubscriber(params.Email, params.Frequency)
+	if v, err := use.repo.Find(ctx, s.ID); err == nil {
+		if v.Confirmed && v.Frequency == params.Frequency {
+			use.log.Infof(ctx, "email subscriber has already been confirmed id:%v", s.ID)
+			return nil
+		}
+		if !v.Confirmed {
+			v.Frequency = params.Frequency
+		}
+		s = v
+	} else if err != dao.ErrNoSuchEntity {
+		return errors.Wrap(err, errTag)
+	}
+
+	now := timeutil.Now()
+	sendable := now.Sub(s.LinkSentAt) >= emailLinkInterval
+	if sendable {
+		s.LinkSentAt = now
+	}
+	if err := use.repo.Save(ctx, s); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if !sendable {
+		use.log.Warningf(ctx, "email link has been sent recently id:%v sent at:%v", s.ID, s.LinkSentAt)
+		return nil
+	}
+
+	mail := mailer.Mail{
+		To:      s.Email,
+		Subject: "【ももクロちゃんねる】メール通知の確認",
+		Body: fmt.Sprintf(
+			"メール通知の登録を受け付けました。\n以下のリンクを開いて登録を完了してください。\n\n%s\n\nお心当たりのない場合はこのメールを破棄してください。\n",
+			emailLinkURL(s, entity.EmailActionConfirm, nil),
+		),
+	}
+	if s.Confirmed {
+		v := url.Values{}
+		v.Set("frequency", params.Frequency)
+		mail.Subject = "【ももクロちゃんねる】メール通知の変更"
+		mail.Body = fmt.Sprintf(
+			"メール通知の変更を受け付けました。\n以下のリンクを開いて変更を完了してください。\n\n%s\n\nお心当たりのない場合はこのメールを破棄してください。\n",
+			emailLinkURL(s, entity.EmailActionManage, v),
+		)
+	}
+	if err := use.mailer.Send(ctx, mail); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "send email link id:%v confirmed:%v frequency:%v", s.ID, s.Confirmed, params.Frequency)
+
+	return nil
+}
+
+// emailLinkURL returns the signed link of given action for the subscriber
+// given values are added to the query of the link
+func emailLinkURL(s *entity.EmailSubscriber, action string, v url.Values) string {
+	if v == nil {
+		v = url.Values{}
+	}
+	v.Set("id", s.ID)
+	v.Set("token", s.Token(config.C().Mail.SigningKey, action))
+	return fmt.Sprintf("%s/email/%s?%s", config.C().App.BaseURL, action, v.Encode())
+}
+
+// emailFooter returns the footer of mails that has the unsubscribe link
+func emailFooter(s *entity.EmailSubscriber) string {
+	return fmt.Sprintf("\n--\nももクロちゃんねる\n配信停止: %s\n", emailLinkURL(s, entity.EmailActionUnsubscribe, nil))
+}
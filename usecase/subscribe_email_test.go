@@ -0,0 +1,153 @@
+package usecase_test
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/mailer/mailertest"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestSubscribeEmail_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	m := mailertest.NewMailer()
+	repo := entity.NewEmailSubscriberRepository(dao.NewDatastoreHandler())
+	subscribe := usecase.NewSubscribeEmail(log.NewAELogger(), m, repo)
+
+	validationTests := []struct {
+		params usecase.SubscribeEmailParams
+	}{
+		{usecase.SubscribeEmailParams{Email: "", Frequency: entity.EmailFrequencyDaily}},
+		{usecase.SubscribeEmailParams{Email: "invalid", Frequency: entity.EmailFrequencyDaily}},
+		{usecase.SubscribeEmailParams{Email: "fan@example.com", Frequency: "monthly"}},
+	}
+	for _, test := range validationTests {
+		err = subscribe.Do(ctx, test.params)
+		if errs, ok := errors.Cause(err).(validator.ValidationErrors); !ok {
+			t.Errorf("Expected validation error, got %v", errs)
+		}
+	}
+
+	params := usecase.SubscribeEmailParams{Email: "fan@example.com", Frequency: entity.EmailFrequencyDaily}
+	if err := subscribe.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+
+	// the confirmation mail is not sent again within the interval
+	if err := subscribe.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+
+	mails := m.Mails()
+	if len(mails) != 1 || mails[0].To != "fan@example.com" {
+		t.Fatalf("Expected a confirmation mail, got %v", mails)
+	}
+	link := regexp.MustCompile(`/email/confirm\?\S+`).FindString(mails[0].Body)
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, token := u.Query().Get("id"), u.Query().Get("token")
+
+	s, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Confirmed {
+		t.Error("Expected unconfirmed subscriber, got confirmed")
+	}
+
+	confirm := usecase.NewConfirmEmail(log.NewAELogger(), repo)
+	if err := confirm.Do(ctx, usecase.ConfirmEmailParams{ID: id, Token: "invalid"}); err == nil {
+		t.Error("Expected invalid token error, got nil")
+	}
+	if err := confirm.Do(ctx, usecase.ConfirmEmailParams{ID: id, Token: token}); err != nil {
+		t.Fatal(err)
+	}
+	s, err = repo.Find(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Confirmed || s.DigestedAt.IsZero() {
+		t.Errorf("Expected confirmed subscriber, got %v", s)
+	}
+
+	// the confirmed subscriber is not overwritten by another subscription
+	// and the manage link is not sent again within the interval
+	params.Frequency = entity.EmailFrequencyWeekly
+	if err := subscribe.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := repo.Find(ctx, id); !s.Confirmed || s.Frequency != entity.EmailFrequencyDaily {
+		t.Errorf("Expected the confirmed daily subscriber, got %v", s)
+	}
+	if len(m.Mails()) != 1 {
+		t.Errorf("Expected no more mails, got %v", m.Mails())
+	}
+
+	// the manage link is sent after the interval
+	tmp := timeutil.Now
+	timeutil.Now = func() time.Time {
+		return tmp().Add(11 * time.Minute)
+	}
+	defer func() {
+		timeutil.Now = tmp
+	}()
+	if err := subscribe.Do(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	mails = m.Mails()
+	if len(mails) != 2 {
+		t.Fatalf("Expected a manage mail, got %v", mails)
+	}
+	link = regexp.MustCompile(`/email/manage\?\S+`).FindString(mails[1].Body)
+	u, err = url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manageParams := usecase.UpdateEmailFrequencyParams{ID: u.Query().Get("id"), Token: u.Query().Get("token"), Frequency: u.Query().Get("frequency")}
+
+	update := usecase.NewUpdateEmailFrequency(log.NewAELogger(), repo)
+	if err := update.Do(ctx, usecase.UpdateEmailFrequencyParams{ID: id, Token: token, Frequency: entity.EmailFrequencyWeekly}); err == nil {
+		t.Error("Expected invalid token error, got nil")
+	}
+	if err := update.Do(ctx, manageParams); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := repo.Find(ctx, id); !s.Confirmed || s.Frequency != entity.EmailFrequencyWeekly {
+		t.Errorf("Expected the confirmed weekly subscriber, got %v", s)
+	}
+
+	// unsubscribe token is not valid for confirmation and vice versa
+	unsubscribe := usecase.NewUnsubscribeEmail(log.NewAELogger(), repo)
+	if err := unsubscribe.Do(ctx, usecase.UnsubscribeEmailParams{ID: id, Token: token}); err == nil {
+		t.Error("Expected invalid token error, got nil")
+	}
+	unsubscribeToken := s.Token(config.C().Mail.SigningKey, entity.EmailActionUnsubscribe)
+	for i := 0; i < 2; i++ {
+		if err := unsubscribe.Do(ctx, usecase.UnsubscribeEmailParams{ID: id, Token: unsubscribeToken}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := repo.Find(ctx, id); err != dao.ErrNoSuchEntity {
+		t.Errorf("Expected ErrNoSuchEntity, got %v", err)
+	}
+}
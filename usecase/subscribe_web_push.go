@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+	"github.com/utahta/momoclo-channel/webpush"
+)
+
+type (
+	// SubscribeWebPush use case
+	SubscribeWebPush struct {
+		log  log.Logger
+		repo entity.WebPushSubscriptionRepository
+	}
+
+	// SubscribeWebPushParams input parameters
+	SubscribeWebPushParams struct {
+		Subscription webpush.Subscription
+	}
+)
+
+// NewSubscribeWebPush returns SubscribeWebPush use case
+func NewSubscribeWebPush(log log.Logger, repo entity.WebPushSubscriptionRepository) *SubscribeWebPush {
+	return &SubscribeWebPush{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do saves PushSubscription of the browser
+// the subscription of the same endpoint is overwritten by the new keys
+func (use *SubscribeWebPush) Do(ctx context.Context, params SubscribeWebPushParams) error {
+	const errTag = "SubscribeWebPush.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if err := params.Subscription.Keys.Check(); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	s, err := entity.NewWebPushSubscription(config.C().WebPush.TokenKey, params.Subscription)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if err := use.repo.Save(ctx, s); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "subscribe web push id:%v", s.ID)
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/telegram"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// TelegramNotify use case
+	TelegramNotify struct {
+		log       log.Logger
+		taskQueue event.TaskQueue
+		client    telegram.Client
+		repo      entity.TelegramSubscriberRepository
+	}
+
+	// TelegramNotifyParams input parameters
+	TelegramNotifyParams struct {
+		Request telegram.Request
+	}
+)
+
+// NewTelegramNotify returns TelegramNotify use case
+func NewTelegramNotify(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	client telegram.Client,
+	repo entity.TelegramSubscriberRepository) *TelegramNotify {
+	return &TelegramNotify{
+		log:       log,
+		taskQueue: taskQueue,
+		client:    client,
+		repo:      repo,
+	}
+}
+
+// Do sends message to telegram chat
+func (use *TelegramNotify) Do(ctx context.Context, params TelegramNotifyParams) error {
+	const errTag = "TelegramNotify.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	request := params.Request
+	err := use.client.Send(ctx, request.ChatID, request.Message, request.Offset)
+	if err != nil {
+		if err == telegram.ErrForbidden {
+			err = use.repo.Delete(ctx, request.ID)
+			use.log.Infof(ctx, "delete id:%v err:%v", request.ID, err)
+			return errors.Wrap(err, errTag)
+		}
+		if e, ok := err.(*telegram.RateLimitError); ok {
+			request.Offset = e.Offset // the albums that have been sent are not sent again
+			if err := pushAfterRateLimit(ctx, use.taskQueue, eventtask.NewTelegram(request), e.RetryAfter); err != nil {
+				return errors.Wrap(err, errTag)
+			}
+			use.log.Warningf(ctx, "telegram rate limited id:%v offset:%v retry after:%v", request.ID, request.Offset, e.RetryAfter)
+			return nil
+		}
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "telegram notify id:%v", request.ID)
+
+	return nil
+}
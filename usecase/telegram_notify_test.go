@@ -0,0 +1,91 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/telegram"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+// telegramSendFunc is a telegram.Client whose Send returns the result of the function
+type telegramSendFunc func(int64) error
+
+func (f telegramSendFunc) SendText(context.Context, int64, string) error { return nil }
+
+func (f telegramSendFunc) SendPhoto(context.Context, int64, string, string) error { return nil }
+
+func (f telegramSendFunc) Send(_ context.Context, chatID int64, _ telegram.Message, _ int) error {
+	return f(chatID)
+}
+
+func TestTelegramNotify_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	repo := entity.NewTelegramSubscriberRepository(dao.NewDatastoreHandler())
+	for _, chatID := range []int64{1, 2, 3} {
+		if err := repo.Save(ctx, entity.NewTelegramSubscriber(chatID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taskQueue := eventtest.NewTaskQueue()
+	client := telegramSendFunc(func(chatID int64) error {
+		switch chatID {
+		case 1:
+			return &telegram.RateLimitError{RetryAfter: 5 * time.Second, Offset: 10}
+		case 2:
+			return telegram.ErrForbidden
+		}
+		return nil
+	})
+	u := usecase.NewTelegramNotify(log.NewAELogger(), taskQueue, client, repo)
+
+	subscribers, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range subscribers {
+		params := usecase.TelegramNotifyParams{Request: telegram.Request{
+			ID:      s.ID,
+			ChatID:  s.ChatID,
+			Message: telegram.Message{Text: "text"},
+		}}
+		if err := u.Do(ctx, params); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// rate limited request is enqueued again after retry-after
+	if len(taskQueue.Tasks) != 1 {
+		t.Fatalf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
+	}
+	if taskQueue.Tasks[0].Delay != 5*time.Second {
+		t.Errorf("Expected delay 5s, got %v", taskQueue.Tasks[0].Delay)
+	}
+	// the albums that have been sent are not sent again
+	if req := taskQueue.Tasks[0].Object.(telegram.Request); req.Offset != 10 {
+		t.Errorf("Expected offset 10, got %v", req.Offset)
+	}
+
+	// the subscriber that blocked the bot is deleted
+	subscribers, err = repo.FindAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscribers) != 2 {
+		t.Errorf("Expected subscribers length 2, got %v", len(subscribers))
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/event"
 	"github.com/utahta/momoclo-channel/event/eventtask"
 	"github.com/utahta/momoclo-channel/log"
@@ -17,6 +19,7 @@ type (
 		log       log.Logger
 		taskQueue event.TaskQueue
 		tweeter   twitter.Tweeter
+		preview   entity.PreviewRepository
 	}
 
 	// TweetParams input parameters
@@ -26,11 +29,16 @@ type (
 )
 
 // NewTweet returns Tweet use case
-func NewTweet(log log.Logger, taskQueue event.TaskQueue, tweeter twitter.Tweeter) *Tweet {
+func NewTweet(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	tweeter twitter.Tweeter,
+	preview entity.PreviewRepository) *Tweet {
 	return &Tweet{
 		log:       log,
 		taskQueue: taskQueue,
 		tweeter:   tweeter,
+		preview:   preview,
 	}
 }
 
@@ -42,6 +50,15 @@ func (use *Tweet) Do(ctx context.Context, params TweetParams) error {
 		return errors.Wrap(err, errTag)
 	}
 
+	if dryrun.Enabled(ctx) {
+		// record the whole thread at once instead of tweeting
+		if err := use.preview.SaveMessages(ctx, entity.PreviewChannelTwitter, params.Requests); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "dry-run: preview tweets len:%v", len(params.Requests))
+		return nil
+	}
+
 	res, err := use.tweeter.Tweet(ctx, params.Requests[0])
 	if err != nil {
 		return errors.Wrap(err, errTag)
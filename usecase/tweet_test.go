@@ -1,10 +1,14 @@
 package usecase_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/go-playground/validator"
 	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/dryrun"
+	"github.com/utahta/momoclo-channel/entity"
 	"github.com/utahta/momoclo-channel/event/eventtest"
 	"github.com/utahta/momoclo-channel/log"
 	"github.com/utahta/momoclo-channel/testutil"
@@ -21,7 +25,7 @@ func TestTweet_Do(t *testing.T) {
 	defer done()
 
 	taskQueue := eventtest.NewTaskQueue()
-	u := usecase.NewTweet(log.NewAELogger(), taskQueue, twitter.NewNopTweeter())
+	u := usecase.NewTweet(log.NewAELogger(), taskQueue, twitter.NewNopTweeter(), entity.NewPreviewRepository(dao.NewDatastoreHandler()))
 
 	validationTests := []struct {
 		params usecase.TweetParams
@@ -55,3 +59,41 @@ func TestTweet_Do(t *testing.T) {
 		t.Errorf("Expected taskqueue length 1, got %v", len(taskQueue.Tasks))
 	}
 }
+
+func TestTweet_DoDryRun(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	ctx = dryrun.WithContext(ctx)
+	taskQueue := eventtest.NewTaskQueue()
+	preview := entity.NewPreviewRepository(dao.NewDatastoreHandler())
+	u := usecase.NewTweet(log.NewAELogger(), taskQueue, twitter.NewNopTweeter(), preview)
+
+	err = u.Do(ctx, usecase.TweetParams{Requests: []twitter.TweetRequest{
+		{Text: "test", ImageURLs: []string{"http://localhost/a"}},
+		{VideoURL: "http://localhost/b"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(taskQueue.Tasks) != 0 {
+		t.Errorf("Expected taskqueue length 0, got %v", len(taskQueue.Tasks))
+	}
+	previews, err := preview.FindRecent(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previews) != 1 {
+		t.Fatalf("Expected previews length 1, got %v", len(previews))
+	}
+	if previews[0].Channel != entity.PreviewChannelTwitter {
+		t.Errorf("Expected channel %v, got %v", entity.PreviewChannelTwitter, previews[0].Channel)
+	}
+	if !strings.Contains(previews[0].Payload, "http://localhost/b") {
+		t.Errorf("Expected payload contains whole thread, got %v", previews[0].Payload)
+	}
+}
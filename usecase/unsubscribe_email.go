@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// UnsubscribeEmail use case
+	UnsubscribeEmail struct {
+		log  log.Logger
+		repo entity.EmailSubscriberRepository
+	}
+
+	// UnsubscribeEmailParams input parameters
+	UnsubscribeEmailParams struct {
+		ID    string `validate:"required"`
+		Token string `validate:"required"`
+	}
+)
+
+// NewUnsubscribeEmail returns UnsubscribeEmail use case
+func NewUnsubscribeEmail(log log.Logger, repo entity.EmailSubscriberRepository) *UnsubscribeEmail {
+	return &UnsubscribeEmail{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do deletes the subscriber given signed link
+// it does nothing if the subscriber has already been deleted
+func (use *UnsubscribeEmail) Do(ctx context.Context, params UnsubscribeEmailParams) error {
+	const errTag = "UnsubscribeEmail.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	s, err := use.repo.Find(ctx, params.ID)
+	if err == dao.ErrNoSuchEntity {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if !s.VerifyToken(config.C().Mail.SigningKey, entity.EmailActionUnsubscribe, params.Token) {
+		return errors.Errorf("%v: invalid token id:%v", errTag, params.ID)
+	}
+
+	if err := use.repo.Delete(ctx, s.ID); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "unsubscribe email subscriber id:%v", s.ID)
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// UnsubscribeWebPush use case
+	UnsubscribeWebPush struct {
+		log  log.Logger
+		repo entity.WebPushSubscriptionRepository
+	}
+
+	// UnsubscribeWebPushParams input parameters
+	UnsubscribeWebPushParams struct {
+		Endpoint string `validate:"required,url"`
+	}
+)
+
+// NewUnsubscribeWebPush returns UnsubscribeWebPush use case
+func NewUnsubscribeWebPush(log log.Logger, repo entity.WebPushSubscriptionRepository) *UnsubscribeWebPush {
+	return &UnsubscribeWebPush{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do deletes the subscription given endpoint
+// the endpoint is known only to the browser, so it is enough to identify the subscriber
+func (use *UnsubscribeWebPush) Do(ctx context.Context, params UnsubscribeWebPushParams) error {
+	const errTag = "UnsubscribeWebPush.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	id := entity.WebPushSubscriptionID(params.Endpoint)
+	if err := use.repo.Delete(ctx, id); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "unsubscribe web push id:%v", id)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/timeutil"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// UpdateEmailFrequency use case
+	UpdateEmailFrequency struct {
+		log  log.Logger
+		repo entity.EmailSubscriberRepository
+	}
+
+	// UpdateEmailFrequencyParams input parameters
+	UpdateEmailFrequencyParams struct {
+		ID        string `validate:"required"`
+		Token     string `validate:"required"`
+		Frequency string `validate:"eq=immediate|eq=daily|eq=weekly"`
+	}
+)
+
+// NewUpdateEmailFrequency returns UpdateEmailFrequency use case
+func NewUpdateEmailFrequency(log log.Logger, repo entity.EmailSubscriberRepository) *UpdateEmailFrequency {
+	return &UpdateEmailFrequency{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do changes the frequency of the confirmed subscriber given signed manage link
+func (use *UpdateEmailFrequency) Do(ctx context.Context, params UpdateEmailFrequencyParams) error {
+	const errTag = "UpdateEmailFrequency.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	s, err := use.repo.Find(ctx, params.ID)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if !s.VerifyToken(config.C().Mail.SigningKey, entity.EmailActionManage, params.Token) {
+		return errors.Errorf("%v: invalid token id:%v", errTag, params.ID)
+	}
+	if !s.Confirmed {
+		return errors.Errorf("%v: unconfirmed subscriber id:%v", errTag, params.ID)
+	}
+	if s.Frequency == params.Frequency {
+		return nil
+	}
+
+	s.Frequency = params.Frequency
+	s.DigestedAt = timeutil.Now() // the first digest of new frequency does not include entries already delivered
+	if err := use.repo.Save(ctx, s); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "update email subscriber id:%v frequency:%v", s.ID, s.Frequency)
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/crawler"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/linebot"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+)
+
+type (
+	// LineNotifyPreferences represents the preferences of LINE Notify subscriber
+	LineNotifyPreferences struct {
+		Codes    []string // feed codes to deliver, all if empty
+		Members  []string // member names that the entries relate to, all if empty
+		Ustream  bool     // live streaming notifications are delivered if true
+		Reminder bool     // reminders are delivered if true
+	}
+
+	// UpdateLineNotifyPreferences use case
+	UpdateLineNotifyPreferences struct {
+		log  log.Logger
+		repo entity.LineNotificationRepository
+	}
+
+	// UpdateLineNotifyPreferencesParams input parameters
+	UpdateLineNotifyPreferencesParams struct {
+		ID          string `validate:"required"`
+		Token       string `validate:"required"`
+		Preferences LineNotifyPreferences
+	}
+)
+
+// NewUpdateLineNotifyPreferences returns UpdateLineNotifyPreferences use case
+func NewUpdateLineNotifyPreferences(log log.Logger, repo entity.LineNotificationRepository) *UpdateLineNotifyPreferences {
+	return &UpdateLineNotifyPreferences{
+		log:  log,
+		repo: repo,
+	}
+}
+
+// Do saves the preferences of the subscriber given signed link
+func (use *UpdateLineNotifyPreferences) Do(ctx context.Context, params UpdateLineNotifyPreferencesParams) error {
+	const errTag = "UpdateLineNotifyPreferences.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if err := params.Preferences.validate(); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	l, err := use.repo.Find(ctx, params.ID)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	if !l.VerifyPreferencesToken(config.C().LineNotify.SigningKey, params.Token) {
+		return errors.Errorf("%v: invalid token id:%v", errTag, params.ID)
+	}
+
+	params.Preferences.apply(l)
+	if err := use.repo.Save(ctx, l); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "update line notify preferences id:%v preferences:%v", l.ID, params.Preferences)
+
+	return nil
+}
+
+// newLineNotifyPreferences returns the preferences of given subscriber
+func newLineNotifyPreferences(l *entity.LineNotification) LineNotifyPreferences {
+	return LineNotifyPreferences{
+		Codes:    l.SplitCodes(),
+		Members:  l.SplitMembers(),
+		Ustream:  !l.MuteUstream,
+		Reminder: !l.MuteReminder,
+	}
+}
+
+// validate returns error if the preferences contain unknown feed codes or member names
+func (p LineNotifyPreferences) validate() error {
+	for _, c := range p.Codes {
+		if f, ok := crawler.FindFeed(crawler.FeedCode(c)); !ok || !f.Enabled {
+			return errors.Errorf("unknown feed code:%v", c)
+		}
+	}
+	for _, m := range p.Members {
+		if !linebot.IsMemberName(m) {
+			return errors.Errorf("unknown member:%v", m)
+		}
+	}
+	return nil
+}
+
+// apply sets the preferences to given subscriber
+func (p LineNotifyPreferences) apply(l *entity.LineNotification) {
+	l.SetCodes(p.Codes)
+	l.SetMembers(p.Members)
+	l.MuteUstream = !p.Ustream
+	l.MuteReminder = !p.Reminder
+}
+
+// lineFeedCodes returns the codes of enabled feeds that subscribers can choose
+func lineFeedCodes() []string {
+	var codes []string
+	for _, f := range crawler.Feeds() {
+		if f.Enabled {
+			codes = append(codes, f.Code.String())
+		}
+	}
+	return codes
+}
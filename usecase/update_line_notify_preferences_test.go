@@ -0,0 +1,58 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"google.golang.org/appengine/aetest"
+)
+
+func TestUpdateLineNotifyPreferences_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+	repo := entity.NewLineNotificationRepository(dao.NewDatastoreHandler())
+	l, err := entity.NewLineNotification(config.C().LineNotify.TokenKey, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	token := l.PreferencesToken(config.C().LineNotify.SigningKey)
+
+	u := usecase.NewUpdateLineNotifyPreferences(log.NewAELogger(), repo)
+	preferences := usecase.LineNotifyPreferences{Codes: []string{"takagi-sd"}, Members: []string{"高城れに"}, Reminder: true}
+
+	invalidTests := []usecase.UpdateLineNotifyPreferencesParams{
+		{ID: l.ID, Token: "invalid", Preferences: preferences},
+		{ID: l.ID, Token: token, Preferences: usecase.LineNotifyPreferences{Codes: []string{"unknown"}}},
+		{ID: l.ID, Token: token, Preferences: usecase.LineNotifyPreferences{Members: []string{"unknown"}}},
+		{ID: "unknown", Token: token, Preferences: preferences},
+	}
+	for _, params := range invalidTests {
+		if err := u.Do(ctx, params); err == nil {
+			t.Errorf("Expected error, got nil. params:%v", params)
+		}
+	}
+
+	if err := u.Do(ctx, usecase.UpdateLineNotifyPreferencesParams{ID: l.ID, Token: token, Preferences: preferences}); err != nil {
+		t.Fatal(err)
+	}
+	v, err := repo.Find(ctx, l.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Codes != "takagi-sd" || v.Members != "高城れに" || !v.MuteUstream || v.MuteReminder {
+		t.Errorf("Expected saved preferences, got %v", v)
+	}
+}
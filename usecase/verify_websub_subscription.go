@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/validator"
+	"github.com/utahta/momoclo-channel/websub"
+)
+
+type (
+	// VerifyWebSubSubscription use case
+	VerifyWebSubSubscription struct {
+		log    log.Logger
+		client websub.Client
+		repo   entity.WebSubSubscriptionRepository
+	}
+
+	// VerifyWebSubSubscriptionParams input parameters
+	VerifyWebSubSubscriptionParams struct {
+		Request websub.Request
+	}
+)
+
+// NewVerifyWebSubSubscription returns VerifyWebSubSubscription use case
+func NewVerifyWebSubSubscription(
+	log log.Logger,
+	client websub.Client,
+	repo entity.WebSubSubscriptionRepository) *VerifyWebSubSubscription {
+	return &VerifyWebSubSubscription{
+		log:    log,
+		client: client,
+		repo:   repo,
+	}
+}
+
+// Do verifies the intent of the subscriber, and then subscribes or unsubscribes the topic
+// the request is just ignored if the subscriber does not confirm the intent
+func (use *VerifyWebSubSubscription) Do(ctx context.Context, params VerifyWebSubSubscriptionParams) error {
+	const errTag = "VerifyWebSubSubscription.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	req := params.Request
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	err := use.client.Verify(ctx, req, hex.EncodeToString(b))
+	if err == websub.ErrNotVerified {
+		use.log.Warningf(ctx, "websub intent is not verified mode:%v topic:%v callback:%v", req.Mode, req.Topic, req.Callback)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	id := entity.WebSubSubscriptionID(req.Topic, req.Callback)
+	if req.Mode == websub.ModeUnsubscribe {
+		if err := use.repo.Delete(ctx, id); err != nil {
+			return errors.Wrap(err, errTag)
+		}
+		use.log.Infof(ctx, "websub unsubscribed topic:%v callback:%v", req.Topic, req.Callback)
+		return nil
+	}
+
+	s, err := entity.NewWebSubSubscription(
+		config.C().WebSub.TokenKey,
+		req.Topic,
+		req.Callback,
+		req.Secret,
+		time.Duration(req.LeaseSeconds)*time.Second,
+	)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	prev, err := use.repo.Find(ctx, id)
+	if err != nil && err != dao.ErrNoSuchEntity {
+		return errors.Wrap(err, errTag)
+	}
+	if err == nil {
+		s.CreatedAt = prev.CreatedAt // renewal of the lease
+	}
+	if err := use.repo.Save(ctx, s); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "websub subscribed topic:%v callback:%v expires:%v", s.Topic, s.Callback, s.ExpiresAt)
+
+	return nil
+}
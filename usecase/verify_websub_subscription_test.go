@@ -0,0 +1,136 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"github.com/utahta/momoclo-channel/websub"
+	"google.golang.org/appengine/aetest"
+)
+
+// websubClient is a websub.Client that records requests
+type websubClient struct {
+	verified bool
+	contents []websub.Content
+	err      error
+}
+
+func (c *websubClient) Verify(_ context.Context, _ websub.Request, challenge string) error {
+	if !c.verified || challenge == "" {
+		return websub.ErrNotVerified
+	}
+	return nil
+}
+
+func (c *websubClient) Distribute(_ context.Context, content websub.Content) error {
+	c.contents = append(c.contents, content)
+	return c.err
+}
+
+func TestRequestWebSubSubscription_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	baseURL := config.C().App.BaseURL
+	config.C().App.BaseURL = "http://localhost"
+	defer func() { config.C().App.BaseURL = baseURL }()
+
+	taskQueue := eventtest.NewTaskQueue()
+	u := usecase.NewRequestWebSubSubscription(log.NewAELogger(), taskQueue)
+
+	tests := []struct {
+		topic string
+		ok    bool
+	}{
+		{"http://localhost/feed.atom", true},
+		{"http://localhost/feed.json?code=momota-sd", true},
+		{"http://localhost/feed.xml", false},
+		{"http://localhost/feed.rss?code=unknown", false},
+		{"http://example.com/feed.atom", false},
+	}
+	for _, test := range tests {
+		req := websub.Request{
+			Mode:         websub.ModeSubscribe,
+			Topic:        test.topic,
+			Callback:     "http://localhost/callback",
+			LeaseSeconds: websub.MaxLeaseSeconds + 1,
+		}
+		err := u.Do(ctx, usecase.RequestWebSubSubscriptionParams{Request: req})
+		if (err == nil) != test.ok {
+			t.Errorf("Expected ok %v given %v, got %v", test.ok, test.topic, err)
+		}
+	}
+
+	if len(taskQueue.Tasks) != 2 {
+		t.Fatalf("Expected task length 2, got %v", len(taskQueue.Tasks))
+	}
+	req := taskQueue.Tasks[0].Object.(websub.Request)
+	if taskQueue.Tasks[0].Path != "/websub/verify" || req.LeaseSeconds != websub.MaxLeaseSeconds {
+		t.Errorf("Expected verification with max lease, got %v %v", taskQueue.Tasks[0].Path, req.LeaseSeconds)
+	}
+}
+
+func TestVerifyWebSubSubscription_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	repo := entity.NewWebSubSubscriptionRepository(dao.NewDatastoreHandler())
+	client := &websubClient{}
+	u := usecase.NewVerifyWebSubSubscription(log.NewAELogger(), client, repo)
+	req := websub.Request{
+		Mode:         websub.ModeSubscribe,
+		Topic:        "http://localhost/feed.atom",
+		Callback:     "http://localhost/callback",
+		Secret:       "secret",
+		LeaseSeconds: 60,
+	}
+	id := entity.WebSubSubscriptionID(req.Topic, req.Callback)
+
+	// not verified
+	if err := u.Do(ctx, usecase.VerifyWebSubSubscriptionParams{Request: req}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Find(ctx, id); err != dao.ErrNoSuchEntity {
+		t.Errorf("Expected no subscription, got %v", err)
+	}
+
+	client.verified = true
+	if err := u.Do(ctx, usecase.VerifyWebSubSubscriptionParams{Request: req}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := repo.Find(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret, err := s.Secret(config.C().WebSub.TokenKey); err != nil || secret != "secret" {
+		t.Errorf("Expected secret, got %v %v", secret, err)
+	}
+	if d := s.ExpiresAt.Sub(s.UpdatedAt).Seconds(); d < 59 || d > 61 {
+		t.Errorf("Expected lease 60 seconds, got %v", d)
+	}
+
+	req.Mode = websub.ModeUnsubscribe
+	if err := u.Do(ctx, usecase.VerifyWebSubSubscriptionParams{Request: req}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Find(ctx, id); err != dao.ErrNoSuchEntity {
+		t.Errorf("Expected unsubscribed, got %v", err)
+	}
+}
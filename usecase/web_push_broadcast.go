@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event"
+	"github.com/utahta/momoclo-channel/event/eventtask"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/notifier"
+	"github.com/utahta/momoclo-channel/validator"
+	"github.com/utahta/momoclo-channel/webpush"
+)
+
+type (
+	// WebPushBroadcast use case
+	WebPushBroadcast struct {
+		log       log.Logger
+		taskQueue event.TaskQueue
+		repo      entity.WebPushSubscriptionRepository
+		preview   entity.PreviewRepository
+	}
+
+	// WebPushBroadcastParams input parameters
+	WebPushBroadcastParams struct {
+		Message webpush.Message
+	}
+)
+
+// NewWebPushBroadcast returns WebPushBroadcast use case
+func NewWebPushBroadcast(
+	log log.Logger,
+	taskQueue event.TaskQueue,
+	repo entity.WebPushSubscriptionRepository,
+	preview entity.PreviewRepository) *WebPushBroadcast {
+	return &WebPushBroadcast{
+		log:       log,
+		taskQueue: taskQueue,
+		repo:      repo,
+		preview:   preview,
+	}
+}
+
+// Do enqueues a push for each subscription
+func (use *WebPushBroadcast) Do(ctx context.Context, params WebPushBroadcastParams) error {
+	const errTag = "WebPushBroadcast.Do failed"
+
+	if err := validator.Validate(params); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	ss, err := use.repo.FindAll(ctx)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	if ok, err := savePreview(ctx, use.log, use.preview, notifier.ChannelWebPush, len(ss), params.Message); ok || err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	tasks := make([]event.Task, 0, len(ss))
+	for _, s := range ss {
+		sub, err := s.Subscription(config.C().WebPush.TokenKey)
+		if err != nil {
+			use.log.Errorf(ctx, "%v: get subscription id:%v err:%v", errTag, s.ID, err)
+			continue
+		}
+		tasks = append(tasks, eventtask.NewWebPush(webpush.Request{
+			ID:           s.ID,
+			Subscription: sub,
+			Message:      params.Message,
+		}))
+	}
+
+	if err := use.taskQueue.PushMulti(ctx, tasks); err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	use.log.Infof(ctx, "broadcast web push tasks len:%v", len(tasks))
+
+	return nil
+}
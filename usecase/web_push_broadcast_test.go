@@ -0,0 +1,137 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/go-playground/validator"
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"github.com/utahta/momoclo-channel/dao"
+	"github.com/utahta/momoclo-channel/entity"
+	"github.com/utahta/momoclo-channel/event/eventtest"
+	"github.com/utahta/momoclo-channel/log"
+	"github.com/utahta/momoclo-channel/testutil"
+	"github.com/utahta/momoclo-channel/usecase"
+	"github.com/utahta/momoclo-channel/webpush"
+	"google.golang.org/appengine/aetest"
+)
+
+// webPushClientFunc is a webpush.Client that returns the result of the function
+type webPushClientFunc func(webpush.Request) error
+
+func (f webPushClientFunc) Send(_ context.Context, req webpush.Request) error {
+	return f(req)
+}
+
+// testWebPushSubscription returns a subscription that has valid keys
+func testWebPushSubscription(t *testing.T, endpoint string) webpush.Subscription {
+	_, x, y, err := elliptic.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return webpush.Subscription{
+		Endpoint: endpoint,
+		Keys: webpush.Keys{
+			P256dh: base64.RawURLEncoding.EncodeToString(elliptic.Marshal(elliptic.P256(), x, y)),
+			Auth:   base64.RawURLEncoding.EncodeToString(auth),
+		},
+	}
+}
+
+func TestWebPushBroadcast_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	taskQueue := eventtest.NewTaskQueue()
+	repo := entity.NewWebPushSubscriptionRepository(dao.NewDatastoreHandler())
+	u := usecase.NewWebPushBroadcast(log.NewAELogger(), taskQueue, repo, entity.NewPreviewRepository(dao.NewDatastoreHandler()))
+
+	err = u.Do(ctx, usecase.WebPushBroadcastParams{Message: webpush.Message{Title: "", Body: "body"}})
+	if errs, ok := errors.Cause(err).(validator.ValidationErrors); !ok {
+		t.Errorf("Expected validation error, got %v", errs)
+	}
+
+	subscribe := usecase.NewSubscribeWebPush(log.NewAELogger(), repo)
+	for i := 0; i < 3; i++ {
+		sub := testWebPushSubscription(t, fmt.Sprintf("https://push.example.com/send/%d", i))
+		if err := subscribe.Do(ctx, usecase.SubscribeWebPushParams{Subscription: sub}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	invalid := testWebPushSubscription(t, "https://push.example.com/send/invalid")
+	invalid.Keys.Auth = "short"
+	if err := subscribe.Do(ctx, usecase.SubscribeWebPushParams{Subscription: invalid}); err == nil {
+		t.Error("Expected error of invalid keys, got nil")
+	}
+
+	message := webpush.Message{Title: "title", Body: "body", URL: "http://localhost/entry"}
+	if err := u.Do(ctx, usecase.WebPushBroadcastParams{Message: message}); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskQueue.Tasks) != 3 {
+		t.Fatalf("Expected task length 3, got %v", len(taskQueue.Tasks))
+	}
+	req, ok := taskQueue.Tasks[0].Object.(webpush.Request)
+	if !ok || req.Message != message || req.Subscription.Keys.Check() != nil {
+		t.Errorf("Unexpected push request %v", taskQueue.Tasks[0].Object)
+	}
+	if req.ID != entity.WebPushSubscriptionID(req.Subscription.Endpoint) {
+		t.Errorf("Expected id of the endpoint, got %v", req.ID)
+	}
+	if _, err := entity.NewWebPushSubscription(config.C().WebPush.TokenKey, req.Subscription); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSendWebPush_Do(t *testing.T) {
+	ctx, done, err := testutil.NewContext(&aetest.Options{StronglyConsistentDatastore: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	testutil.MustConfigLoad()
+
+	repo := entity.NewWebPushSubscriptionRepository(dao.NewDatastoreHandler())
+	sub := testWebPushSubscription(t, "https://push.example.com/send/1")
+	if err := usecase.NewSubscribeWebPush(log.NewAELogger(), repo).Do(ctx, usecase.SubscribeWebPushParams{Subscription: sub}); err != nil {
+		t.Fatal(err)
+	}
+
+	var sendErr error
+	client := webPushClientFunc(func(webpush.Request) error { return sendErr })
+	u := usecase.NewSendWebPush(log.NewAELogger(), client, repo)
+	req := webpush.Request{
+		ID:           entity.WebPushSubscriptionID(sub.Endpoint),
+		Subscription: sub,
+		Message:      webpush.Message{Title: "title"},
+	}
+
+	sendErr = errors.New("push service unavailable")
+	if err := u.Do(ctx, usecase.SendWebPushParams{Request: req}); err == nil {
+		t.Error("Expected error to retry, got nil")
+	}
+	if ss, _ := repo.FindAll(ctx); len(ss) != 1 {
+		t.Errorf("Expected subscription remains, got %v", len(ss))
+	}
+
+	sendErr = webpush.ErrGone
+	if err := u.Do(ctx, usecase.SendWebPushParams{Request: req}); err != nil {
+		t.Fatal(err)
+	}
+	if ss, _ := repo.FindAll(ctx); len(ss) != 0 {
+		t.Errorf("Expected gone subscription is pruned, got %v", len(ss))
+	}
+}
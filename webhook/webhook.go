@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/utahta/momoclo-channel/timeutil"
+	"google.golang.org/appengine/urlfetch"
+)
+
+// headers of webhook requests
+const (
+	HeaderEvent     = "X-Momoclo-Event"
+	HeaderDelivery  = "X-Momoclo-Delivery"
+	HeaderTimestamp = "X-Momoclo-Timestamp"
+	HeaderSignature = "X-Momoclo-Signature"
+)
+
+type (
+	// Payload represents the JSON body of webhook requests
+	Payload struct {
+		ID        string    `json:"id"`    // delivery id
+		Event     string    `json:"event"` // feed, ustream or reminder
+		CreatedAt time.Time `json:"created_at"`
+		Item      *Item     `json:"item,omitempty"` // feed event only
+		Text      string    `json:"text,omitempty"`
+		URL       string    `json:"url,omitempty"`
+	}
+
+	// Item represents an entry of the feed event
+	Item struct {
+		Code        string    `json:"code"`
+		Title       string    `json:"title"`
+		URL         string    `json:"url"`
+		EntryTitle  string    `json:"entry_title"`
+		EntryURL    string    `json:"entry_url"`
+		ImageURLs   []string  `json:"image_urls"`
+		VideoURLs   []string  `json:"video_urls"`
+		PublishedAt time.Time `json:"published_at"`
+	}
+
+	// Request represents a signed webhook request
+	Request struct {
+		URL        string
+		Secret     string
+		Event      string
+		DeliveryID string
+		Body       []byte
+	}
+
+	// StatusError represents the response of unsuccessful status
+	StatusError struct {
+		StatusCode int
+	}
+
+	// Client interface
+	Client interface {
+		Post(context.Context, Request) error
+	}
+
+	client struct {
+		httpClient func(context.Context) *http.Client
+	}
+)
+
+// New returns Client that posts through urlfetch
+func New() Client {
+	return NewWithHTTPClient(urlfetch.Client)
+}
+
+// NewWithHTTPClient returns Client that posts through given http client
+func NewWithHTTPClient(fn func(context.Context) *http.Client) Client {
+	return &client{httpClient: fn}
+}
+
+// Error implements error
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook: unexpected status:%v", e.StatusCode)
+}
+
+// Post posts the signed body, any status except 2xx is regarded as failure
+func (c *client) Post(ctx context.Context, req Request) error {
+	r, err := http.NewRequest(http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(timeutil.Now().Unix(), 10)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("User-Agent", "momoclo-channel-webhook")
+	r.Header.Set(HeaderEvent, req.Event)
+	r.Header.Set(HeaderDelivery, req.DeliveryID)
+	r.Header.Set(HeaderTimestamp, timestamp)
+	r.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+
+	resp, err := c.httpClient(ctx).Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode/100 != 2 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// Sign returns the signature of the body (e.g. "sha256=...")
+// the signed message is the timestamp and the body joined with "." to prevent replay
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if the signature is valid for the body
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Post(t *testing.T) {
+	const secret = "secret"
+	body := []byte(`{"id":"1","event":"feed"}`)
+
+	var status = http.StatusNoContent
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if !Verify(secret, r.Header.Get(HeaderTimestamp), b, r.Header.Get(HeaderSignature)) {
+			t.Errorf("Expected valid signature, got %v", r.Header.Get(HeaderSignature))
+		}
+		if r.Header.Get(HeaderEvent) != "feed" || r.Header.Get(HeaderDelivery) != "1" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	c := NewWithHTTPClient(func(context.Context) *http.Client { return http.DefaultClient })
+	req := Request{URL: s.URL, Secret: secret, Event: "feed", DeliveryID: "1", Body: body}
+	if err := c.Post(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	status = http.StatusInternalServerError
+	err := c.Post(context.Background(), req)
+	if e, ok := err.(*StatusError); !ok || e.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected StatusError, got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte("body")
+	signature := Sign("secret", "1500000000", body)
+
+	tests := []struct {
+		secret    string
+		timestamp string
+		body      []byte
+		expected  bool
+	}{
+		{"secret", "1500000000", body, true},
+		{"other", "1500000000", body, false},
+		{"secret", "1500000001", body, false},
+		{"secret", "1500000000", []byte("tampered"), false},
+	}
+	for _, test := range tests {
+		if v := Verify(test.secret, test.timestamp, test.body, signature); v != test.expected {
+			t.Errorf("Expected %v, got %v. %v %v %s", test.expected, v, test.secret, test.timestamp, test.body)
+		}
+	}
+}
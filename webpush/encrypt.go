@@ -0,0 +1,115 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// recordSize is the record size of aes128gcm content coding, a message is encrypted in a single record
+const recordSize = 4096
+
+// Check returns error if the keys of subscription are malformed
+func (k Keys) Check() error {
+	_, _, err := k.decode()
+	return err
+}
+
+func (k Keys) decode() ([]byte, []byte, error) {
+	pub, err := decodeBase64(k.P256dh)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid p256dh")
+	}
+	if x, _ := elliptic.Unmarshal(elliptic.P256(), pub); x == nil {
+		return nil, nil, errors.New("invalid p256dh: not a point of P-256")
+	}
+	auth, err := decodeBase64(k.Auth)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid auth")
+	}
+	if len(auth) != 16 {
+		return nil, nil, errors.Errorf("invalid auth length:%v", len(auth))
+	}
+	return pub, auth, nil
+}
+
+// encrypt encrypts the plaintext for the user agent (see: RFC 8291 and RFC 8188)
+func encrypt(keys Keys, plaintext []byte) ([]byte, error) {
+	uaPublic, authSecret, err := keys.decode()
+	if err != nil {
+		return nil, err
+	}
+	// 16 octets of the tag and 1 octet of the padding delimiter
+	if len(plaintext) > recordSize-16-1 {
+		return nil, errors.Errorf("plaintext is too large:%v", len(plaintext))
+	}
+
+	curve := elliptic.P256()
+	asPrivate, x, y, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := elliptic.Marshal(curve, x, y)
+
+	uaX, uaY := elliptic.Unmarshal(curve, uaPublic)
+	sx, _ := curve.ScalarMult(uaX, uaY, asPrivate)
+	ecdhSecret := make([]byte, 32)
+	sxBytes := sx.Bytes()
+	copy(ecdhSecret[32-len(sxBytes):], sxBytes)
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublic...), asPublic...)
+	ikm := hkdf(authSecret, ecdhSecret, keyInfo, 32)
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	record := append(append([]byte{}, plaintext...), 0x02) // the last record
+	ciphertext := gcm.Seal(nil, nonce, record, nil)
+
+	header := make([]byte, 16+4+1)
+	copy(header, salt)
+	binary.BigEndian.PutUint32(header[16:], recordSize)
+	header[20] = byte(len(asPublic))
+	header = append(header, asPublic...)
+
+	return append(header, ciphertext...), nil
+}
+
+// hkdf derives a key of given length that is at most 32 octets (see: RFC 5869)
+func hkdf(salt, ikm, info []byte, length int) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(ikm)
+	prk := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, prk)
+	mac.Write(info)
+	mac.Write([]byte{0x01})
+	return mac.Sum(nil)[:length]
+}
+
+// decodeBase64 decodes base64url string with or without padding
+func decodeBase64(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
@@ -0,0 +1,86 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// GenerateKeys returns base64url encoded VAPID key pair
+func GenerateKeys() (string, string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	d := make([]byte, 32)
+	b := key.D.Bytes()
+	copy(d[32-len(b):], b)
+	return encodePublicKey(&key.PublicKey), base64.RawURLEncoding.EncodeToString(d), nil
+}
+
+// PublicKey returns base64url encoded VAPID public key given private key
+// it is the applicationServerKey of subscriptions in the browser
+func PublicKey(privateKey string) (string, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return encodePublicKey(&key.PublicKey), nil
+}
+
+func parsePrivateKey(privateKey string) (*ecdsa.PrivateKey, error) {
+	d, err := decodeBase64(privateKey)
+	if err != nil || len(d) != 32 {
+		return nil, errors.New("invalid vapid private key")
+	}
+	curve := elliptic.P256()
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d)
+	return key, nil
+}
+
+func encodePublicKey(key *ecdsa.PublicKey) string {
+	return base64.RawURLEncoding.EncodeToString(elliptic.Marshal(key.Curve, key.X, key.Y))
+}
+
+// vapidAuthorization returns Authorization header of VAPID given the endpoint (see: RFC 8292)
+func vapidAuthorization(key *ecdsa.PrivateKey, subject, endpoint string, expiresAt time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(struct {
+		Aud string `json:"aud"`
+		Exp int64  `json:"exp"`
+		Sub string `json:"sub"`
+	}{u.Scheme + "://" + u.Host, expiresAt.Unix(), subject})
+	if err != nil {
+		return "", err
+	}
+	input := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		return "", err
+	}
+	sig := make([]byte, 64)
+	rb, sb := r.Bytes(), s.Bytes()
+	copy(sig[32-len(rb):32], rb)
+	copy(sig[64-len(sb):], sb)
+
+	token := input + "." + base64.RawURLEncoding.EncodeToString(sig)
+	return fmt.Sprintf("vapid t=%s, k=%s", token, encodePublicKey(&key.PublicKey)), nil
+}
@@ -0,0 +1,128 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utahta/momoclo-channel/config"
+	"google.golang.org/appengine/urlfetch"
+)
+
+// DefaultTTL is the time that push service retains the message while the browser is offline
+const DefaultTTL = 24 * time.Hour
+
+// ErrGone is returned when push service responds that the subscription is expired or unsubscribed (404 or 410)
+var ErrGone = errors.New("webpush: subscription gone")
+
+type (
+	// Subscription represents PushSubscription of the browser
+	Subscription struct {
+		Endpoint string `json:"endpoint" validate:"required,url"`
+		Keys     Keys   `json:"keys"`
+	}
+
+	// Keys represents the keys of PushSubscription that are base64url encoded
+	Keys struct {
+		P256dh string `json:"p256dh" validate:"required"`
+		Auth   string `json:"auth" validate:"required"`
+	}
+
+	// Message represents a notification that is shown by service worker
+	Message struct {
+		Title    string `json:"title" validate:"required"`
+		Body     string `json:"body"`
+		URL      string `json:"url,omitempty" validate:"omitempty,url"`
+		ImageURL string `json:"image,omitempty" validate:"omitempty,url"`
+	}
+
+	// Request represents a push to a subscriber
+	Request struct {
+		ID           string `validate:"required"` // id of the subscriber
+		Subscription Subscription
+		Message      Message
+	}
+
+	// Client interface
+	Client interface {
+		Send(context.Context, Request) error
+	}
+
+	client struct {
+		subject    string
+		privateKey string
+		httpClient func(context.Context) *http.Client
+	}
+)
+
+// New returns Client
+func New() Client {
+	c := config.C().WebPush
+	if c.Disabled {
+		return NewNop()
+	}
+	return NewWithHTTPClient(c.Subject, c.PrivateKey, urlfetch.Client)
+}
+
+// NewWithHTTPClient returns Client that sends pushes through given http client
+// subject is the contact of the sender (e.g. mailto:admin@example.com), and privateKey is base64url encoded VAPID private key
+func NewWithHTTPClient(subject, privateKey string, fn func(context.Context) *http.Client) Client {
+	return &client{
+		subject:    subject,
+		privateKey: privateKey,
+		httpClient: fn,
+	}
+}
+
+// Send encrypts the message and sends it to the push service of the subscription
+// it returns ErrGone if the subscription is no longer valid
+func (c *client) Send(ctx context.Context, req Request) error {
+	const errTag = "webpush.Send failed"
+
+	key, err := parsePrivateKey(c.privateKey)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	plaintext, err := json.Marshal(req.Message)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	body, err := encrypt(req.Subscription.Keys, plaintext)
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	authorization, err := vapidAuthorization(key, c.subject, req.Subscription.Endpoint, time.Now().Add(12*time.Hour))
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+
+	r, err := http.NewRequest(http.MethodPost, req.Subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	r.Header.Set("Authorization", authorization)
+	r.Header.Set("Content-Encoding", "aes128gcm")
+	r.Header.Set("Content-Type", "application/octet-stream")
+	r.Header.Set("TTL", strconv.Itoa(int(DefaultTTL.Seconds())))
+
+	resp, err := c.httpClient(ctx).Do(r.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, errTag)
+	}
+	defer resp.Body.Close()
+
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode/100 != 2:
+		return errors.Errorf("%v: status:%v body:%s", errTag, resp.StatusCode, b)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKeys returns the keys of user agent and its private key
+func testKeys(t *testing.T) (Keys, []byte) {
+	priv, x, y, err := elliptic.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return Keys{
+		P256dh: base64.RawURLEncoding.EncodeToString(elliptic.Marshal(elliptic.P256(), x, y)),
+		Auth:   base64.RawURLEncoding.EncodeToString(auth),
+	}, priv
+}
+
+// decrypt decrypts the content as user agent
+func decrypt(t *testing.T, keys Keys, uaPrivate, content []byte) []byte {
+	uaPublic, authSecret, err := keys.decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := content[:16]
+	if rs := binary.BigEndian.Uint32(content[16:20]); rs != recordSize {
+		t.Errorf("Expected record size %v, got %v", recordSize, rs)
+	}
+	idlen := int(content[20])
+	asPublic := content[21 : 21+idlen]
+	ciphertext := content[21+idlen:]
+
+	curve := elliptic.P256()
+	x, y := elliptic.Unmarshal(curve, asPublic)
+	sx, _ := curve.ScalarMult(x, y, uaPrivate)
+	ecdhSecret := make([]byte, 32)
+	copy(ecdhSecret[32-len(sx.Bytes()):], sx.Bytes())
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublic...), asPublic...)
+	ikm := hkdf(authSecret, ecdhSecret, keyInfo, 32)
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	record, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record[len(record)-1] != 0x02 {
+		t.Errorf("Expected the last record delimiter, got %v", record[len(record)-1])
+	}
+	return record[:len(record)-1]
+}
+
+func TestEncrypt(t *testing.T) {
+	keys, uaPrivate := testKeys(t)
+
+	content, err := encrypt(keys, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := decrypt(t, keys, uaPrivate, content); string(v) != "hello" {
+		t.Errorf("Expected hello, got %s", v)
+	}
+
+	if _, err := encrypt(keys, make([]byte, recordSize)); err == nil {
+		t.Error("Expected error of too large plaintext, got nil")
+	}
+	if err := (Keys{P256dh: "invalid", Auth: keys.Auth}).Check(); err == nil {
+		t.Error("Expected error of invalid p256dh, got nil")
+	}
+}
+
+func TestVAPIDAuthorization(t *testing.T) {
+	publicKey, privateKey, err := GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := PublicKey(privateKey); err != nil || v != publicKey {
+		t.Fatalf("Expected public key %v, got %v %v", publicKey, v, err)
+	}
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := vapidAuthorization(key, "mailto:admin@localhost", "https://push.example.com/send/abc", time.Unix(1500000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(v, "vapid t=") || !strings.HasSuffix(v, ", k="+publicKey) {
+		t.Fatalf("Unexpected authorization %v", v)
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(v, "vapid t="), ", k="+publicKey)
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected jwt, got %v", token)
+	}
+	b, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims struct {
+		Aud string `json:"aud"`
+		Exp int64  `json:"exp"`
+		Sub string `json:"sub"`
+	}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Aud != "https://push.example.com" || claims.Exp != 1500000000 || claims.Sub != "mailto:admin@localhost" {
+		t.Errorf("Unexpected claims %v", claims)
+	}
+
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&key.PublicKey, hash[:], r, s) {
+		t.Error("Expected valid signature")
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	keys, uaPrivate := testKeys(t)
+	_, privateKey, err := GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := http.StatusCreated
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("TTL") == "" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "vapid t=") {
+			t.Errorf("Expected vapid authorization, got %v", r.Header.Get("Authorization"))
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		var m Message
+		if err := json.Unmarshal(decrypt(t, keys, uaPrivate, b), &m); err != nil || m.Title != "title" {
+			t.Errorf("Unexpected message %v %v", m, err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	c := NewWithHTTPClient("mailto:admin@localhost", privateKey, func(context.Context) *http.Client { return http.DefaultClient })
+	req := Request{
+		ID:           "id",
+		Subscription: Subscription{Endpoint: s.URL + "/send/abc", Keys: keys},
+		Message:      Message{Title: "title", Body: "body", URL: "http://localhost/entry"},
+	}
+	if err := c.Send(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	status = http.StatusGone
+	if err := c.Send(context.Background(), req); err != ErrGone {
+		t.Errorf("Expected ErrGone, got %v", err)
+	}
+}
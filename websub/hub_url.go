@@ -0,0 +1,8 @@
+package websub
+
+import "github.com/utahta/momoclo-channel/config"
+
+// HubURL returns WebSub hub URL
+func HubURL() string {
+	return config.C().App.BaseURL + "/websub/hub"
+}
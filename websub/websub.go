@@ -0,0 +1,151 @@
+package websub
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"google.golang.org/appengine/urlfetch"
+)
+
+const (
+	ModeSubscribe   = "subscribe"
+	ModeUnsubscribe = "unsubscribe"
+
+	// DefaultLeaseSeconds is used if the subscriber does not request the lease (10 days)
+	DefaultLeaseSeconds = 10 * 24 * 60 * 60
+
+	// MaxLeaseSeconds is the max lease that the hub accepts (30 days)
+	MaxLeaseSeconds = 30 * 24 * 60 * 60
+)
+
+var (
+	// ErrNotVerified is returned when the subscriber does not confirm the intent
+	ErrNotVerified = errors.New("websub: not verified")
+
+	// ErrGone is returned when the subscriber responds 410 Gone to the content distribution
+	ErrGone = errors.New("websub: gone")
+)
+
+type (
+	// Request represents subscription request of a subscriber
+	Request struct {
+		Mode         string `validate:"eq=subscribe|eq=unsubscribe"`
+		Topic        string `validate:"required,url"`
+		Callback     string `validate:"required,url"`
+		Secret       string `validate:"max=199"` // the secret to sign content, not signed if empty
+		LeaseSeconds int    `validate:"min=0"`
+	}
+
+	// Content represents a content distribution to a subscriber
+	Content struct {
+		Callback    string
+		Topic       string
+		HubURL      string
+		Secret      string
+		ContentType string
+		Body        []byte
+	}
+
+	// Client interface
+	Client interface {
+		Verify(context.Context, Request, string) error
+		Distribute(context.Context, Content) error
+	}
+
+	client struct {
+		httpClient func(context.Context) *http.Client
+	}
+)
+
+// New returns Client that requests through urlfetch
+func New() Client {
+	return NewWithHTTPClient(urlfetch.Client)
+}
+
+// NewWithHTTPClient returns Client that requests through given http client
+func NewWithHTTPClient(fn func(context.Context) *http.Client) Client {
+	return &client{httpClient: fn}
+}
+
+// Verify verifies the intent of the subscriber by echoing the challenge
+// it returns ErrNotVerified if the subscriber does not echo the challenge
+func (c *client) Verify(ctx context.Context, req Request, challenge string) error {
+	u, err := url.Parse(req.Callback)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("hub.mode", req.Mode)
+	q.Set("hub.topic", req.Topic)
+	q.Set("hub.challenge", challenge)
+	if req.Mode == ModeSubscribe {
+		q.Set("hub.lease_seconds", strconv.Itoa(req.LeaseSeconds))
+	}
+	u.RawQuery = q.Encode()
+
+	r, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient(ctx).Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 || string(b) != challenge {
+		return ErrNotVerified
+	}
+	return nil
+}
+
+// Distribute posts the content of the topic to the subscriber
+// the body is signed by X-Hub-Signature if the subscriber has the secret
+func (c *client) Distribute(ctx context.Context, content Content) error {
+	r, err := http.NewRequest(http.MethodPost, content.Callback, bytes.NewReader(content.Body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", content.ContentType)
+	r.Header.Add("Link", fmt.Sprintf(`<%s>; rel="hub"`, content.HubURL))
+	r.Header.Add("Link", fmt.Sprintf(`<%s>; rel="self"`, content.Topic))
+	if content.Secret != "" {
+		r.Header.Set("X-Hub-Signature", Sign(content.Secret, content.Body))
+	}
+
+	resp, err := c.httpClient(ctx).Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode == http.StatusGone {
+		return ErrGone
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("websub: distribute status:%v", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns X-Hub-Signature of the body (e.g. "sha256=...")
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,73 @@
+package websub
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Verify(t *testing.T) {
+	var echo bool
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("hub.mode") != ModeSubscribe || q.Get("hub.topic") != "http://localhost/feed.atom" || q.Get("hub.lease_seconds") != "100" || q.Get("token") != "a" {
+			t.Errorf("Unexpected query %v", q)
+		}
+		if echo {
+			w.Write([]byte(q.Get("hub.challenge")))
+		} else {
+			w.Write([]byte("ng"))
+		}
+	}))
+	defer s.Close()
+
+	c := NewWithHTTPClient(func(context.Context) *http.Client { return http.DefaultClient })
+	req := Request{Mode: ModeSubscribe, Topic: "http://localhost/feed.atom", Callback: s.URL + "/callback?token=a", LeaseSeconds: 100}
+
+	echo = true
+	if err := c.Verify(context.Background(), req, "challenge"); err != nil {
+		t.Fatal(err)
+	}
+	echo = false
+	if err := c.Verify(context.Background(), req, "challenge"); err != ErrNotVerified {
+		t.Errorf("Expected ErrNotVerified, got %v", err)
+	}
+}
+
+func TestClient_Distribute(t *testing.T) {
+	status := http.StatusOK
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if v := r.Header.Get("X-Hub-Signature"); v != Sign("secret", b) {
+			t.Errorf("Expected valid signature, got %v", v)
+		}
+		if links := r.Header["Link"]; len(links) != 2 || links[0] != `<http://localhost/websub>; rel="hub"` {
+			t.Errorf("Unexpected links %v", links)
+		}
+		if r.Header.Get("Content-Type") != "application/atom+xml" {
+			t.Errorf("Unexpected content type %v", r.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	c := NewWithHTTPClient(func(context.Context) *http.Client { return http.DefaultClient })
+	content := Content{
+		Callback:    s.URL,
+		Topic:       "http://localhost/feed.atom",
+		HubURL:      "http://localhost/websub",
+		Secret:      "secret",
+		ContentType: "application/atom+xml",
+		Body:        []byte("<feed></feed>"),
+	}
+	if err := c.Distribute(context.Background(), content); err != nil {
+		t.Fatal(err)
+	}
+
+	status = http.StatusGone
+	if err := c.Distribute(context.Background(), content); err != ErrGone {
+		t.Errorf("Expected ErrGone, got %v", err)
+	}
+}